@@ -0,0 +1,44 @@
+// Package gateway serves Fabex gRPC services as JSON over HTTP using HTTP bindings of fabex.proto and v2/fabex.proto
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	pbv2 "github.com/hyperledger-labs/fabex/proto/v2"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewHandler creates HTTP handler calling the gRPC service over the connection. Fields are named as in fabex.proto,
+// unpopulated fields are returned with zero values.
+func NewHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := pb.RegisterFabexHandler(ctx, mux, conn); err != nil {
+		return nil, errors.Wrap(err, "failed to register gateway handlers")
+	}
+	if err := pbv2.RegisterFabexHandler(ctx, mux, conn); err != nil {
+		return nil, errors.Wrap(err, "failed to register v2 gateway handlers")
+	}
+	return mux, nil
+}
+
+// Connect creates HTTP handler calling the gRPC service at the endpoint, the connection is closed when ctx is done
+func Connect(ctx context.Context, endpoint string) (http.Handler, error) {
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", endpoint)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	return NewHandler(ctx, conn)
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fabexgrpc "github.com/hyperledger-labs/fabex/api/grpc"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHandler(t *testing.T) {
+	storage := dbtest.New()
+	storage.Add("mychannel", db.Tx{Txid: "tx1", Blocknum: 1, Chaincode: "fabcar"}, db.Tx{Txid: "tx2", Blocknum: 2, Chaincode: "fabcar"})
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterFabexServer(server, fabexgrpc.NewFabexServer("", "", storage, nil, nil, fabexgrpc.Options{}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler, err := NewHandler(ctx, conn)
+	require.NoError(t, err)
+
+	// streamed entries are newline-delimited results
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/channels/mychannel/blocks?startblock=1&endblock=2", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var txids []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line struct {
+			Result map[string]interface{} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		txids = append(txids, line.Result["txid"].(string))
+		assert.Equal(t, "fabcar", line.Result["chaincode"])
+	}
+	assert.Equal(t, []string{"tx1", "tx2"}, txids)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/channels/mychannel/txs/tx2", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"txid":"tx2"`)
+
+	// errors of the gRPC service are mapped to statuses
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/channels/mychannel/txs/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/channels/mychannel/nosuchroute", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
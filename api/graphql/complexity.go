@@ -0,0 +1,175 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxComplexity limits the estimated cost of a query
+	maxComplexity = 5000
+	// storageCost is the cost of fields resolved by a storage query
+	storageCost = 10
+	// archiveCost is the cost of fields resolved by decoding an archived block
+	archiveCost = 20
+)
+
+var (
+	// fieldCosts are costs of fields resolved by storages or the archive, other fields cost 1
+	fieldCosts = map[string]int{
+		"Channel.height":        storageCost,
+		"Channel.block":         storageCost,
+		"Channel.blockByHash":   storageCost,
+		"Channel.blocks":        storageCost,
+		"Channel.transactions":  storageCost,
+		"Channel.keyHistory":    storageCost,
+		"Identity.transactions": storageCost,
+		"Write.history":         storageCost,
+		"Transaction.block":     storageCost,
+		"Transaction.events":    archiveCost,
+	}
+	// nodeCosts are costs of nodes of connections which are fetched one by one
+	nodeCosts = map[string]int{
+		"Channel.blocks": storageCost,
+	}
+)
+
+// complexity estimates the cost of the validated operation. Fields cost 1 or their fieldCosts, costs of fields
+// selected from nodes of connections are multiplied by the page size and costs of fields selected from other lists
+// are multiplied by defaultFirst. Introspection fields are free.
+func complexity(schema graphql.Schema, doc *ast.Document, operationName string, vars map[string]interface{}) (int, error) {
+	w := costWalker{schema: schema, fragments: make(map[string]*ast.FragmentDefinition), vars: make(map[string]interface{})}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			w.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return 0, errors.Errorf("unknown operation: %s", operationName)
+	}
+
+	for _, def := range op.VariableDefinitions {
+		if def.DefaultValue != nil {
+			w.vars[def.Variable.Name.Value] = def.DefaultValue.GetValue()
+		}
+	}
+	for name, v := range vars {
+		w.vars[name] = v
+	}
+
+	// the schema has no mutations and subscriptions, so validated operations are queries
+	return w.selectionSet(schema.QueryType(), op.SelectionSet, defaultFirst), nil
+}
+
+type costWalker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	vars      map[string]interface{}
+}
+
+// selectionSet returns the cost of fields selected from the parent, size is the expected size of its lists
+func (w costWalker) selectionSet(parent interface{}, set *ast.SelectionSet, size int) int {
+	if set == nil {
+		return 0
+	}
+
+	cost := 0
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			obj, ok := parent.(*graphql.Object)
+			if !ok {
+				continue
+			}
+			def, ok := obj.Fields()[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			name := obj.Name() + "." + sel.Name.Value
+			fieldCost, ok := fieldCosts[name]
+			if !ok {
+				fieldCost = 1
+			}
+			// nodes of a connection are a list of the page size
+			if first, ok := w.pageSize(def, sel); ok {
+				cost += fieldCost + first*nodeCosts[name] + w.selectionSet(graphql.GetNamed(def.Type), sel.SelectionSet, first)
+				continue
+			}
+			multiplier := 1
+			if isList(def.Type) {
+				multiplier = size
+			}
+			cost += fieldCost + multiplier*w.selectionSet(graphql.GetNamed(def.Type), sel.SelectionSet, defaultFirst)
+		case *ast.InlineFragment:
+			t := parent
+			if sel.TypeCondition != nil {
+				t = w.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			cost += w.selectionSet(t, sel.SelectionSet, size)
+		case *ast.FragmentSpread:
+			// fragment cycles are rejected by validation
+			if frag, ok := w.fragments[sel.Name.Value]; ok {
+				cost += w.selectionSet(w.schema.Type(frag.TypeCondition.Name.Value), frag.SelectionSet, size)
+			}
+		}
+	}
+	return cost
+}
+
+// pageSize returns the first argument of a connection field, ok is false for other fields
+func (w costWalker) pageSize(def *graphql.FieldDefinition, field *ast.Field) (first int, ok bool) {
+	for _, arg := range def.Args {
+		if arg.Name() != "first" {
+			continue
+		}
+		for _, a := range field.Arguments {
+			if a.Name.Value == "first" {
+				// invalid sizes are rejected by resolvers, but they must not decrease the cost
+				if n, ok := w.intValue(a.Value); ok && n > 0 {
+					return n, true
+				}
+			}
+		}
+		return defaultFirst, true
+	}
+	return 0, false
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+func (w costWalker) intValue(value ast.Value) (int, bool) {
+	var v interface{} = value.GetValue()
+	if variable, ok := value.(*ast.Variable); ok {
+		v = w.vars[variable.Name.Value]
+	}
+	switch v := v.(type) {
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
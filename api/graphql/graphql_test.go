@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStorage counts blocks fetched by number
+type countingStorage struct {
+	*dbtest.Storage
+	fetched int
+}
+
+func (c *countingStorage) GetByBlocknum(ctx context.Context, ch string, blocknum uint64) ([]db.Tx, error) {
+	c.fetched++
+	return c.Storage.GetByBlocknum(ctx, ch, blocknum)
+}
+
+func payload(t *testing.T, kvs ...models.WriteKV) []byte {
+	for i := range kvs {
+		kvs[i].Value = base64.StdEncoding.EncodeToString([]byte(kvs[i].Value))
+	}
+	raw, err := json.Marshal(kvs)
+	require.NoError(t, err)
+	return raw
+}
+
+func query(t *testing.T, h http.Handler, body string) (int, map[string]interface{}) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	return rec.Code, res
+}
+
+func TestHandler(t *testing.T) {
+	storage := &countingStorage{Storage: dbtest.New()}
+	storage.Add("mychannel",
+		db.Tx{ChannelId: "mychannel", Txid: "tx1", Blocknum: 1, Hash: "h1", Chaincode: "fabcar", CreatorMSP: "Org1MSP",
+			Payload:   payload(t, models.WriteKV{Key: "CAR1", Value: `{"make":"Toyota"}`}),
+			Documents: []db.Document{{Key: "CAR1", Value: map[string]interface{}{"make": "Toyota"}}}},
+		db.Tx{ChannelId: "mychannel", Txid: "tx2", Blocknum: 2, Hash: "h2", PreviousHash: "h1", Chaincode: "fabcar", CreatorMSP: "Org2MSP",
+			Payload: payload(t, models.WriteKV{Key: "CAR1", Value: `{"make":"Honda"}`})},
+		db.Tx{ChannelId: "mychannel", Txid: "tx3", Blocknum: 2, TxNum: 1, Hash: "h2", PreviousHash: "h1", Chaincode: "marbles",
+			CreatorMSP: "Org2MSP", Payload: payload(t, models.WriteKV{Key: "marble1", Value: "blue"})})
+	require.NoError(t, storage.InsertKeyHistory(context.Background(), "mychannel", []db.KeyModification{
+		{ChannelId: "mychannel", Namespace: "fabcar", Key: "CAR1", Blocknum: 1, Txid: "tx1", Value: []byte(`{"make":"Toyota"}`)},
+		{ChannelId: "mychannel", Namespace: "fabcar", Key: "CAR1", Blocknum: 2, Txid: "tx2", Value: []byte(`{"make":"Honda"}`)},
+	}))
+	h, err := NewHandler(storage, nil, []string{"mychannel"})
+	require.NoError(t, err)
+
+	t.Run("Nested", func(t *testing.T) {
+		status, res := query(t, h, `{"query": "{ channel(id: \"mychannel\") { height block(number: 2) { previousHash transactions { id creator { mspId } writes { key value document history { nodes { txId } } } } } } }"}`)
+		require.Equal(t, http.StatusOK, status)
+		require.Nil(t, res["errors"])
+		ch := res["data"].(map[string]interface{})["channel"].(map[string]interface{})
+		assert.EqualValues(t, 3, ch["height"])
+		block := ch["block"].(map[string]interface{})
+		assert.Equal(t, "h1", block["previousHash"])
+		tx := block["transactions"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "tx2", tx["id"])
+		assert.Equal(t, "Org2MSP", tx["creator"].(map[string]interface{})["mspId"])
+		write := tx["writes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, `{"make":"Honda"}`, write["value"])
+		assert.Nil(t, write["document"])
+		assert.Len(t, write["history"].(map[string]interface{})["nodes"], 2)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		var ids []string
+		after := ""
+		for {
+			body, err := json.Marshal(map[string]interface{}{
+				"query":     `query($after: String) { channel(id: "mychannel") { transactions(chaincode: "fabcar", after: $after) { nodes { id } pageInfo { endCursor hasNextPage } } } }`,
+				"variables": map[string]interface{}{"after": after},
+			})
+			require.NoError(t, err)
+			_, res := query(t, h, string(body))
+			require.Nil(t, res["errors"])
+			conn := res["data"].(map[string]interface{})["channel"].(map[string]interface{})["transactions"].(map[string]interface{})
+			for _, node := range conn["nodes"].([]interface{}) {
+				ids = append(ids, node.(map[string]interface{})["id"].(string))
+			}
+			info := conn["pageInfo"].(map[string]interface{})
+			if !info["hasNextPage"].(bool) {
+				break
+			}
+			after = info["endCursor"].(string)
+		}
+		assert.Equal(t, []string{"tx1", "tx2"}, ids)
+	})
+
+	t.Run("BlockCache", func(t *testing.T) {
+		storage.fetched = 0
+		_, res := query(t, h, `{"query": "{ channel(id: \"mychannel\") { transactions(fromBlock: 2) { nodes { id block { number } } } } }"}`)
+		require.Nil(t, res["errors"])
+		nodes := res["data"].(map[string]interface{})["channel"].(map[string]interface{})["transactions"].(map[string]interface{})["nodes"]
+		assert.Len(t, nodes, 2)
+		// both txs are in block 2, it's fetched once per request
+		assert.Equal(t, 1, storage.fetched)
+	})
+
+	t.Run("Complexity", func(t *testing.T) {
+		for _, q := range []string{
+			`{ channel(id: \"mychannel\") { blocks(first: 100) { nodes { transactions { writes { history(first: 100) { nodes { key } } } } } } } }`,
+			// events of every tx decode an archived block
+			`{ channel(id: \"mychannel\") { transactions(first: 100) { nodes { block { transactions { events { name } } } } } } }`,
+		} {
+			_, res := query(t, h, `{"query": "`+q+`"}`)
+			require.NotNil(t, res["errors"])
+			assert.Contains(t, res["errors"].([]interface{})[0].(map[string]interface{})["message"], "complexity")
+			assert.Nil(t, res["data"])
+		}
+	})
+
+	t.Run("UnknownChannel", func(t *testing.T) {
+		_, res := query(t, h, `{"query": "{ channel(id: \"other\") { height } }"}`)
+		require.NotNil(t, res["errors"])
+		assert.Nil(t, res["data"].(map[string]interface{})["channel"])
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		status, _ := query(t, h, `{"query": `)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
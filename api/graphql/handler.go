@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+)
+
+// maxRequestSize limits the body of POST requests
+const maxRequestSize = 1 << 20
+
+// request is sent as JSON body of POST or as query parameters of GET, variables are JSON encoded for GET
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler executes GraphQL queries
+type Handler struct {
+	schema graphql.Schema
+}
+
+// NewHandler creates handler of queries over stored data, blocks is nil if blocks aren't archived,
+// channels are channels explored by fabex, other channels aren't served if it's not empty
+func NewHandler(db db.Storage, blocks db.RawBlockStore, channels []string) (*Handler, error) {
+	schema, err := newSchema(&resolver{db: db, blocks: blocks, channels: channels})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GraphQL schema")
+	}
+	return &Handler{schema: schema}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeResult(w, http.StatusBadRequest, failure(errors.Wrap(err, "invalid variables")))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			writeResult(w, http.StatusBadRequest, failure(errors.Wrap(err, "invalid request body")))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeResult(w, http.StatusMethodNotAllowed, failure(errors.Errorf("method %s is not allowed", r.Method)))
+		return
+	}
+
+	writeResult(w, http.StatusOK, h.execute(r.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+// execute validates the query, rejects it if it's too complex and executes it
+func (h *Handler) execute(ctx context.Context, query, operationName string, vars map[string]interface{}) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if res := graphql.ValidateDocument(&h.schema, doc, nil); !res.IsValid {
+		return &graphql.Result{Errors: res.Errors}
+	}
+
+	cost, err := complexity(h.schema, doc, operationName, vars)
+	if err != nil {
+		return failure(err)
+	}
+	if cost > maxComplexity {
+		return failure(errors.Errorf("query complexity %d exceeds %d", cost, maxComplexity))
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: operationName,
+		Args:          vars,
+		Context:       withRequestCache(ctx),
+	})
+}
+
+func failure(err error) *graphql.Result {
+	return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+}
+
+func writeResult(w http.ResponseWriter, status int, res *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,763 @@
+// Package graphql serves GraphQL queries over channels, blocks, txs, writes, key history and chaincode events
+// stored by fabex
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/hyperledger-labs/fabex/archive"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultFirst is the page size of connections without first argument, it's also the expected size of lists
+	// for complexity estimation
+	defaultFirst = 10
+	// maxFirst limits the page size of connections
+	maxFirst = 100
+)
+
+// resolver fetches data of schema fields
+type resolver struct {
+	db     db.Storage
+	blocks db.RawBlockStore
+	// channels are channels explored by fabex, other channels aren't served if it's not empty
+	channels []string
+}
+
+// blockKey identifies a block of a channel
+type blockKey struct {
+	channel  string
+	blocknum uint64
+}
+
+// requestCache keeps blocks fetched while a query is executed, fields of txs of the same block resolve the block once
+type requestCache struct {
+	mu      sync.Mutex
+	stored  map[blockKey][]db.Tx
+	decoded map[blockKey]*blockhandler.CustomBlock
+}
+
+type requestCacheKey struct{}
+
+func withRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCacheKey{}, &requestCache{
+		stored:  make(map[blockKey][]db.Tx),
+		decoded: make(map[blockKey]*blockhandler.CustomBlock),
+	})
+}
+
+// cached returns blocks of the request, fetch is called if the request has no cache or the block isn't cached yet
+func cached[T any](ctx context.Context, key blockKey, blocks func(c *requestCache) map[blockKey]T, fetch func() (T, error)) (T, error) {
+	c, ok := ctx.Value(requestCacheKey{}).(*requestCache)
+	if !ok {
+		return fetch()
+	}
+	c.mu.Lock()
+	v, ok := blocks(c)[key]
+	c.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	c.mu.Lock()
+	blocks(c)[key] = v
+	c.mu.Unlock()
+	return v, nil
+}
+
+type channel struct {
+	ID string `graphql:"id"`
+}
+
+type block struct {
+	Channel      string `graphql:"channel"`
+	Number       uint64 `graphql:"number"`
+	Hash         string `graphql:"hash"`
+	PreviousHash string `graphql:"previousHash"`
+	Entries      []db.Tx
+}
+
+// transaction is the part of a Fabric tx writing to a single chaincode, txs writing to several chaincodes are
+// stored as several transactions with the same ID
+type transaction struct {
+	ID             string `graphql:"id"`
+	Channel        string `graphql:"channel"`
+	BlockNumber    uint64 `graphql:"blockNumber"`
+	TxNum          uint64 `graphql:"txNum"`
+	Time           string `graphql:"time"`
+	ValidationCode int32  `graphql:"validationCode"`
+	Type           string `graphql:"type"`
+	Chaincode      string `graphql:"chaincode"`
+	Entry          db.Tx
+}
+
+type write struct {
+	Channel   string
+	Namespace string `graphql:"namespace"`
+	Key       string `graphql:"key"`
+	Value     []byte
+	// Document is nil if the value isn't decoded
+	Document interface{} `graphql:"document"`
+}
+
+type event struct {
+	Chaincode string `graphql:"chaincode"`
+	Name      string `graphql:"name"`
+	Payload   []byte
+}
+
+type identity struct {
+	Channel string
+	MSPID   string `graphql:"mspId"`
+}
+
+type keyModification struct {
+	Channel     string
+	Namespace   string `graphql:"namespace"`
+	Key         string `graphql:"key"`
+	Value       []byte
+	IsDelete    bool   `graphql:"isDelete"`
+	BlockNumber uint64 `graphql:"blockNumber"`
+	TxNum       uint64 `graphql:"txNum"`
+	TxID        string `graphql:"txId"`
+	Time        string `graphql:"time"`
+}
+
+// page is a connection, EndCursor is nil if there are no more nodes
+type page struct {
+	Nodes    interface{} `graphql:"nodes"`
+	PageInfo pageInfo    `graphql:"pageInfo"`
+}
+
+type pageInfo struct {
+	EndCursor   *string `graphql:"endCursor"`
+	HasNextPage bool    `graphql:"hasNextPage"`
+}
+
+func newPage(nodes interface{}, cursor string) page {
+	if cursor == "" {
+		return page{Nodes: nodes}
+	}
+	return page{Nodes: nodes, PageInfo: pageInfo{EndCursor: &cursor, HasNextPage: true}}
+}
+
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+func newTransaction(tx db.Tx) transaction {
+	return transaction{ID: tx.Txid, Channel: tx.ChannelId, BlockNumber: tx.Blocknum, TxNum: tx.TxNum, Time: formatTime(tx.Time),
+		ValidationCode: tx.ValidationCode, Type: tx.TxType, Chaincode: tx.Chaincode, Entry: tx}
+}
+
+func newTransactions(txs []db.Tx) []transaction {
+	res := make([]transaction, 0, len(txs))
+	for _, tx := range txs {
+		res = append(res, newTransaction(tx))
+	}
+	return res
+}
+
+func newKeyModifications(ch string, mods []db.KeyModification) []keyModification {
+	res := make([]keyModification, 0, len(mods))
+	for _, m := range mods {
+		res = append(res, keyModification{Channel: ch, Namespace: m.Namespace, Key: m.Key, Value: m.Value, IsDelete: m.IsDelete,
+			BlockNumber: m.Blocknum, TxNum: m.TxNum, TxID: m.Txid, Time: formatTime(m.Time)})
+	}
+	return res
+}
+
+// newBlock groups txs of a single block, nil is returned for a block without txs
+func newBlock(txs []db.Tx) *block {
+	if len(txs) == 0 {
+		return nil
+	}
+	return &block{Channel: txs[0].ChannelId, Number: txs[0].Blocknum, Hash: txs[0].Hash, PreviousHash: txs[0].PreviousHash, Entries: txs}
+}
+
+func isNotFound(err error) bool {
+	return err != nil && err.Error() == db.NOT_FOUND_ERR
+}
+
+// pageArgs converts first, after and order arguments of a connection
+func pageArgs(args map[string]interface{}) (db.Page, error) {
+	page := db.Page{Limit: defaultFirst}
+	if first, ok := args["first"].(int); ok {
+		if first < 1 || first > maxFirst {
+			return page, errors.Errorf("first must be between 1 and %d", maxFirst)
+		}
+		page.Limit = int64(first)
+	}
+	page.Cursor, _ = args["after"].(string)
+	if order, ok := args["order"].(db.SortOrder); ok {
+		page.Order = order
+	}
+	return page, nil
+}
+
+// bytesFields resolves text and base64 representations of a byte field
+func bytesFields(name, description string, value func(source interface{}) []byte) graphql.Fields {
+	return graphql.Fields{
+		name: &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: description + " as text",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return string(value(p.Source)), nil
+			},
+		},
+		name + "Base64": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: description + " encoded with base64",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return base64.StdEncoding.EncodeToString(value(p.Source)), nil
+			},
+		},
+	}
+}
+
+func withFields(fields graphql.Fields, more graphql.Fields) graphql.Fields {
+	for name, f := range more {
+		fields[name] = f
+	}
+	return fields
+}
+
+// connection creates a type of pages of nodes
+func connection(node *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: node.Name() + "Connection",
+		Fields: graphql.Fields{
+			"nodes":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(node)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+// connectionArgs are arguments of a page, more are arguments selecting nodes
+func connectionArgs(more graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Page size, 10 by default"},
+		"after": &graphql.ArgumentConfig{Type: graphql.String, Description: "End cursor of the previous page"},
+		"order": &graphql.ArgumentConfig{Type: orderType, DefaultValue: db.Ascending},
+	}
+	for name, arg := range more {
+		args[name] = arg
+	}
+	return args
+}
+
+var (
+	pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"endCursor":   &graphql.Field{Type: graphql.String, Description: "Cursor of the next page, null if there are no more nodes"},
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	orderType = graphql.NewEnum(graphql.EnumConfig{
+		Name: "Order",
+		Values: graphql.EnumValueConfigMap{
+			"ASC":  &graphql.EnumValueConfig{Value: db.Ascending, Description: "From the oldest block to the newest"},
+			"DESC": &graphql.EnumValueConfig{Value: db.Descending, Description: "From the newest block to the oldest"},
+		},
+	})
+
+	jsonType = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "JSON",
+		Description: "JSON value",
+		Serialize: func(value interface{}) interface{} {
+			return value
+		},
+	})
+)
+
+// newSchema defines types of the schema and their resolvers
+func newSchema(r *resolver) (graphql.Schema, error) {
+	nonNullString, nonNullInt := graphql.NewNonNull(graphql.String), graphql.NewNonNull(graphql.Int)
+
+	keyModificationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "KeyModification",
+		Description: "Version of a world state key written by a valid transaction",
+		Fields: withFields(graphql.Fields{
+			"namespace":   &graphql.Field{Type: nonNullString},
+			"key":         &graphql.Field{Type: nonNullString},
+			"isDelete":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"blockNumber": &graphql.Field{Type: nonNullInt},
+			"txNum":       &graphql.Field{Type: nonNullInt},
+			"txId":        &graphql.Field{Type: nonNullString},
+			"time":        &graphql.Field{Type: nonNullString, Description: "RFC 3339 time of the transaction"},
+		}, bytesFields("value", "Written value", func(source interface{}) []byte {
+			return source.(keyModification).Value
+		})),
+	})
+	keyModificationConnection := connection(keyModificationType)
+
+	writeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Write",
+		Fields: withFields(graphql.Fields{
+			"namespace": &graphql.Field{Type: nonNullString},
+			"key":       &graphql.Field{Type: nonNullString},
+			"document":  &graphql.Field{Type: jsonType, Description: "Value decoded from JSON, null for values of other formats"},
+			"history": &graphql.Field{
+				Type:        graphql.NewNonNull(keyModificationConnection),
+				Description: "Versions of the key written by valid transactions",
+				Args:        connectionArgs(nil),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					w := p.Source.(write)
+					return r.keyHistory(p.Context, w.Channel, w.Namespace, w.Key, p.Args)
+				},
+			},
+		}, bytesFields("value", "Written value", func(source interface{}) []byte {
+			return source.(write).Value
+		})),
+	})
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Event",
+		Description: "Chaincode event set by the transaction",
+		Fields: withFields(graphql.Fields{
+			"chaincode": &graphql.Field{Type: nonNullString},
+			"name":      &graphql.Field{Type: nonNullString},
+		}, bytesFields("payload", "Event payload", func(source interface{}) []byte {
+			return source.(event).Payload
+		})),
+	})
+
+	identityType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Identity",
+		Description: "Creator of transactions",
+		Fields: graphql.Fields{
+			"mspId": &graphql.Field{Type: nonNullString},
+		},
+	})
+
+	transactionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Description: "Part of a Fabric transaction writing to a single chaincode, transactions writing to several chaincodes " +
+			"are stored as several transactions with the same ID",
+		Fields: graphql.Fields{
+			"id":             &graphql.Field{Type: nonNullString},
+			"channel":        &graphql.Field{Type: nonNullString},
+			"blockNumber":    &graphql.Field{Type: nonNullInt},
+			"txNum":          &graphql.Field{Type: nonNullInt, Description: "Position of the transaction in the block"},
+			"time":           &graphql.Field{Type: nonNullString, Description: "RFC 3339 time of the transaction"},
+			"validationCode": &graphql.Field{Type: nonNullInt, Description: "Validation code, 0 for valid transactions"},
+			"type":           &graphql.Field{Type: nonNullString, Description: "Channel header type, e.g. ENDORSER_TRANSACTION"},
+			"chaincode":      &graphql.Field{Type: nonNullString, Description: "Chaincode of the writes, empty for config transactions"},
+			"creator": &graphql.Field{
+				Type: graphql.NewNonNull(identityType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(transaction)
+					return identity{Channel: tx.Channel, MSPID: tx.Entry.CreatorMSP}, nil
+				},
+			},
+			"writes": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(writeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return writes(p.Source.(transaction))
+				},
+			},
+			"events": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+				Description: "Chaincode events, they are read from archived blocks",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.events(p.Context, p.Source.(transaction))
+				},
+			},
+		},
+	})
+	transactionConnection := connection(transactionType)
+
+	identityType.AddFieldConfig("transactions", &graphql.Field{
+		Type:        graphql.NewNonNull(transactionConnection),
+		Description: "Transactions created by the MSP",
+		Args:        connectionArgs(nil),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id := p.Source.(identity)
+			page, err := pageArgs(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			return r.queryTransactions(p.Context, id.Channel, db.Filter{CreatorMSP: id.MSPID, Page: page})
+		},
+	})
+
+	blockType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Block",
+		Fields: graphql.Fields{
+			"channel":      &graphql.Field{Type: nonNullString},
+			"number":       &graphql.Field{Type: nonNullInt},
+			"hash":         &graphql.Field{Type: nonNullString},
+			"previousHash": &graphql.Field{Type: nonNullString},
+			"transactions": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return newTransactions(p.Source.(*block).Entries), nil
+				},
+			},
+		},
+	})
+	blockConnection := connection(blockType)
+
+	transactionType.AddFieldConfig("block", &graphql.Field{
+		Type: graphql.NewNonNull(blockType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			tx := p.Source.(transaction)
+			return r.block(p.Context, tx.Channel, tx.BlockNumber)
+		},
+	})
+
+	channelType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Channel",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: nonNullString},
+			"height": &graphql.Field{
+				Type:        nonNullInt,
+				Description: "Number of the last stored block + 1, 0 if there are no blocks",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.height(p.Context, p.Source.(channel).ID)
+				},
+			},
+			"block": &graphql.Field{
+				Type: blockType,
+				Args: graphql.FieldConfigArgument{"number": &graphql.ArgumentConfig{Type: nonNullInt}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					b, err := r.block(p.Context, p.Source.(channel).ID, uint64(p.Args["number"].(int)))
+					if isNotFound(err) {
+						return nil, nil
+					}
+					return b, err
+				},
+			},
+			"blockByHash": &graphql.Field{
+				Type: blockType,
+				Args: graphql.FieldConfigArgument{"hash": &graphql.ArgumentConfig{Type: nonNullString}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					txs, err := r.db.QueryBlockByHash(p.Context, p.Source.(channel).ID, p.Args["hash"].(string))
+					if isNotFound(err) {
+						return nil, nil
+					}
+					return newBlock(txs), errors.Wrap(err, "failed to get block by hash")
+				},
+			},
+			"blocks": &graphql.Field{
+				Type:        graphql.NewNonNull(blockConnection),
+				Description: "Blocks of the range, it ends with the last stored block by default",
+				Args: connectionArgs(graphql.FieldConfigArgument{
+					"from": &graphql.ArgumentConfig{Type: graphql.Int},
+					"to":   &graphql.ArgumentConfig{Type: graphql.Int},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.blockRange(p.Context, p.Source.(channel).ID, p.Args)
+				},
+			},
+			"transactions": &graphql.Field{
+				Type:        graphql.NewNonNull(transactionConnection),
+				Description: "Transactions matching all specified arguments",
+				Args: connectionArgs(graphql.FieldConfigArgument{
+					"id":             &graphql.ArgumentConfig{Type: graphql.String},
+					"chaincode":      &graphql.ArgumentConfig{Type: graphql.String},
+					"creatorMspId":   &graphql.ArgumentConfig{Type: graphql.String},
+					"type":           &graphql.ArgumentConfig{Type: graphql.String},
+					"key":            &graphql.ArgumentConfig{Type: graphql.String, Description: "Written key"},
+					"keyPrefix":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Prefix of a written key"},
+					"validationCode": &graphql.ArgumentConfig{Type: graphql.Int},
+					"fromBlock":      &graphql.ArgumentConfig{Type: graphql.Int},
+					"toBlock":        &graphql.ArgumentConfig{Type: graphql.Int},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.transactions(p.Context, p.Source.(channel).ID, p.Args)
+				},
+			},
+			"keyHistory": &graphql.Field{
+				Type:        graphql.NewNonNull(keyModificationConnection),
+				Description: "Versions of the key written by valid transactions",
+				Args: connectionArgs(graphql.FieldConfigArgument{
+					"namespace": &graphql.ArgumentConfig{Type: nonNullString, Description: "Chaincode name"},
+					"key":       &graphql.ArgumentConfig{Type: nonNullString},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.keyHistory(p.Context, p.Source.(channel).ID, p.Args["namespace"].(string), p.Args["key"].(string), p.Args)
+				},
+			},
+			"identity": &graphql.Field{
+				Type: graphql.NewNonNull(identityType),
+				Args: graphql.FieldConfigArgument{"mspId": &graphql.ArgumentConfig{Type: nonNullString}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return identity{Channel: p.Source.(channel).ID, MSPID: p.Args["mspId"].(string)}, nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"channels": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(channelType))),
+				Description: "Channels explored by fabex",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					channels := make([]channel, 0, len(r.channels))
+					for _, ch := range r.channels {
+						channels = append(channels, channel{ID: ch})
+					}
+					return channels, nil
+				},
+			},
+			"channel": &graphql.Field{
+				Type: channelType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: nonNullString}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(string)
+					if len(r.channels) == 0 {
+						return channel{ID: id}, nil
+					}
+					for _, ch := range r.channels {
+						if ch == id {
+							return channel{ID: id}, nil
+						}
+					}
+					return nil, errors.Errorf("unknown channel: %s", id)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func (r *resolver) height(ctx context.Context, ch string) (uint64, error) {
+	last, err := r.db.GetLastEntry(ctx, ch)
+	if isNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get the last block")
+	}
+	return last.Blocknum + 1, nil
+}
+
+func (r *resolver) block(ctx context.Context, ch string, blocknum uint64) (*block, error) {
+	stored := func(c *requestCache) map[blockKey][]db.Tx { return c.stored }
+	txs, err := cached(ctx, blockKey{ch, blocknum}, stored, func() ([]db.Tx, error) {
+		return r.db.GetByBlocknum(ctx, ch, blocknum)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, errors.New(db.NOT_FOUND_ERR)
+	}
+	return newBlock(txs), nil
+}
+
+// blockRange returns a page of complete blocks, the cursor is the number of the next block of the range
+func (r *resolver) blockRange(ctx context.Context, ch string, args map[string]interface{}) (page, error) {
+	p, err := pageArgs(args)
+	if err != nil {
+		return page{}, err
+	}
+
+	var from, to uint64
+	if v, ok := args["from"].(int); ok {
+		from = uint64(v)
+	}
+	if v, ok := args["to"].(int); ok {
+		to = uint64(v)
+	} else {
+		height, err := r.height(ctx, ch)
+		if err != nil {
+			return page{}, err
+		}
+		if height == 0 {
+			return newPage([]*block{}, ""), nil
+		}
+		to = height - 1
+	}
+	if p.Cursor != "" {
+		next, err := strconv.ParseUint(p.Cursor, 10, 64)
+		if err != nil {
+			return page{}, errors.Errorf("invalid cursor: %s", p.Cursor)
+		}
+		if p.Order == db.Descending {
+			to = next
+		} else {
+			from = next
+		}
+	}
+	if from > to {
+		return newPage([]*block{}, ""), nil
+	}
+
+	// window of the page and the first block of the next page
+	first, last, cursor := from, to, ""
+	limit := uint64(p.Limit)
+	if p.Order == db.Descending {
+		if to-from >= limit {
+			first = to - limit + 1
+			cursor = strconv.FormatUint(first-1, 10)
+		}
+	} else if to-from >= limit {
+		last = from + limit - 1
+		cursor = strconv.FormatUint(last+1, 10)
+	}
+
+	blocks := []*block{}
+	for blocknum := first; blocknum <= last; blocknum++ {
+		b, err := r.block(ctx, ch, blocknum)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return page{}, errors.Wrapf(err, "failed to get block %d", blocknum)
+		}
+		blocks = append(blocks, b)
+	}
+	if p.Order == db.Descending {
+		for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+			blocks[i], blocks[j] = blocks[j], blocks[i]
+		}
+	}
+	return newPage(blocks, cursor), nil
+}
+
+func (r *resolver) transactions(ctx context.Context, ch string, args map[string]interface{}) (page, error) {
+	p, err := pageArgs(args)
+	if err != nil {
+		return page{}, err
+	}
+	filter := db.Filter{Page: p}
+	filter.Chaincode, _ = args["chaincode"].(string)
+	filter.CreatorMSP, _ = args["creatorMspId"].(string)
+	filter.TxType, _ = args["type"].(string)
+	filter.Key, _ = args["key"].(string)
+	filter.KeyPrefix, _ = args["keyPrefix"].(string)
+	if code, ok := args["validationCode"].(int); ok {
+		code32 := int32(code)
+		filter.ValidationCode = &code32
+	}
+	if v, ok := args["fromBlock"].(int); ok {
+		filter.FromBlock = uint64(v)
+	}
+	if v, ok := args["toBlock"].(int); ok {
+		filter.ToBlock = uint64(v)
+	}
+
+	// all parts of a tx fit a single page
+	if id, ok := args["id"].(string); ok {
+		txs, err := r.db.GetByTxId(ctx, ch, id)
+		if err != nil && !isNotFound(err) {
+			return page{}, errors.Wrap(err, "failed to get txs by ID")
+		}
+		matching := []transaction{}
+		for _, tx := range txs {
+			if filter.Match(tx) {
+				matching = append(matching, newTransaction(tx))
+			}
+		}
+		return newPage(matching, ""), nil
+	}
+
+	return r.queryTransactions(ctx, ch, filter)
+}
+
+func (r *resolver) queryTransactions(ctx context.Context, ch string, filter db.Filter) (page, error) {
+	txs, cursor, err := r.db.Query(ctx, ch, filter)
+	if err != nil {
+		return page{}, errors.Wrap(err, "failed to query txs")
+	}
+	return newPage(newTransactions(txs), cursor), nil
+}
+
+func (r *resolver) keyHistory(ctx context.Context, ch, namespace, key string, args map[string]interface{}) (page, error) {
+	p, err := pageArgs(args)
+	if err != nil {
+		return page{}, err
+	}
+	mods, cursor, err := r.db.GetKeyHistory(ctx, ch, namespace, key, p)
+	if err != nil {
+		return page{}, errors.Wrap(err, "failed to get key history")
+	}
+	return newPage(newKeyModifications(ch, mods), cursor), nil
+}
+
+// events decodes the archived block of the tx, events of other txs of the block are skipped
+func (r *resolver) events(ctx context.Context, tx transaction) ([]event, error) {
+	custom, err := r.decodedBlock(ctx, tx.Channel, tx.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []event{}
+	txNum := custom.TxNum(tx.ID, tx.TxNum)
+	for _, ev := range custom.Events {
+		if ev.Txid == tx.ID && ev.TxNum == txNum {
+			events = append(events, event{Chaincode: ev.Chaincode, Name: ev.Name, Payload: ev.Payload})
+		}
+	}
+	return events, nil
+}
+
+// decodedBlock returns the archived block, blocks are decoded once per request
+func (r *resolver) decodedBlock(ctx context.Context, ch string, blocknum uint64) (*blockhandler.CustomBlock, error) {
+	if r.blocks == nil {
+		return nil, errors.New("block archive is disabled")
+	}
+	decoded := func(c *requestCache) map[blockKey]*blockhandler.CustomBlock { return c.decoded }
+	return cached(ctx, blockKey{ch, blocknum}, decoded, func() (*blockhandler.CustomBlock, error) {
+		raw, err := r.blocks.GetRawBlock(ctx, ch, blocknum)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get raw block %d", blocknum)
+		}
+		fabblock, _, err := archive.Verify(raw)
+		if err != nil {
+			return nil, err
+		}
+		custom, err := blockhandler.HandleBlock(fabblock)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode block %d", blocknum)
+		}
+		return custom, nil
+	})
+}
+
+// writes decodes the write set of the tx
+func writes(tx transaction) ([]write, error) {
+	var kvs []models.WriteKV
+	if err := json.Unmarshal(tx.Entry.Payload, &kvs); err != nil {
+		return nil, errors.Wrap(err, "failed to decode write set")
+	}
+	documents := make(map[string]map[string]interface{}, len(tx.Entry.Documents))
+	for _, doc := range tx.Entry.Documents {
+		documents[doc.Key] = doc.Value
+	}
+
+	res := make([]write, 0, len(kvs))
+	for _, kv := range kvs {
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode value of %s", kv.Key)
+		}
+		w := write{Channel: tx.Channel, Namespace: tx.Chaincode, Key: kv.Key, Value: value}
+		if doc, ok := documents[kv.Key]; ok {
+			w.Document = doc
+		}
+		res = append(res, w)
+	}
+	return res, nil
+}
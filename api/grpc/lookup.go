@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/hyperledger-labs/fabex/db"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *FabexServer) GetTransaction(ctx context.Context, req *pb.RequestTransaction) (*pb.Transaction, error) {
+	if req.Channelid == "" || req.Txid == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel ID and tx ID must be specified")
+	}
+
+	txs, err := s.db.GetByTxId(ctx, req.Channelid, req.Txid)
+	if err == nil && len(txs) == 0 {
+		err = errors.New(db.NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return nil, storageStatus(err, "failed to get tx %s", req.Txid)
+	}
+
+	resp := &pb.Transaction{Txid: req.Txid}
+	for _, tx := range txs {
+		resp.Entries = append(resp.Entries, pb.EntryFromTx(tx))
+	}
+	return resp, nil
+}
+
+func (s *FabexServer) GetBlock(ctx context.Context, req *pb.RequestBlock) (*pb.Block, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	txs, err := s.db.GetByBlocknum(ctx, req.Channelid, req.Blocknum)
+	if err == nil && len(txs) == 0 {
+		err = errors.New(db.NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return nil, storageStatus(err, "failed to get block %d", req.Blocknum)
+	}
+
+	resp := &pb.Block{Channelid: req.Channelid, Blocknum: req.Blocknum, Hash: txs[0].Hash, Previoushash: txs[0].PreviousHash}
+	for _, tx := range txs {
+		resp.Entries = append(resp.Entries, pb.EntryFromTx(tx))
+	}
+	return resp, nil
+}
+
+func (s *FabexServer) GetChainInfo(ctx context.Context, req *pb.RequestChainInfo) (*pb.ChainInfo, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+	if !s.knownChannel(req.Channelid) {
+		return nil, status.Errorf(codes.NotFound, "unknown channel: %s", req.Channelid)
+	}
+
+	return s.chainInfo(ctx, req.Channelid)
+}
+
+func (s *FabexServer) ListChannels(ctx context.Context, _ *pb.RequestChannels) (*pb.Channels, error) {
+	resp := &pb.Channels{}
+	for _, ch := range s.opts.Channels {
+		info, err := s.chainInfo(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+		resp.Channels = append(resp.Channels, info)
+	}
+	return resp, nil
+}
+
+// knownChannel checks if the channel is explored, all channels are known if explored channels aren't specified
+func (s *FabexServer) knownChannel(ch string) bool {
+	if len(s.opts.Channels) == 0 {
+		return true
+	}
+	for _, known := range s.opts.Channels {
+		if known == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// chainInfo describes the last stored block of the channel
+func (s *FabexServer) chainInfo(ctx context.Context, ch string) (*pb.ChainInfo, error) {
+	info := &pb.ChainInfo{Channelid: ch}
+	last, err := s.db.GetLastEntry(ctx, ch)
+	if err != nil {
+		if err.Error() == db.NOT_FOUND_ERR {
+			return info, nil
+		}
+		return nil, storageStatus(err, "failed to get the last block of %s", ch)
+	}
+	info.Height = last.Blocknum + 1
+	info.Currentblockhash, info.Previousblockhash = last.Hash, last.PreviousHash
+	return info, nil
+}
+
+// storageStatus converts the storage error into a status, NOT_FOUND_ERR is NotFound, invalid cursors are
+// InvalidArgument, errors of the request context keep their codes and other errors are Internal
+func storageStatus(err error, format string, args ...interface{}) error {
+	switch {
+	case err.Error() == db.NOT_FOUND_ERR:
+		return status.Error(codes.NotFound, errors.Errorf(format, args...).Error()+": not found")
+	case db.IsInvalidCursor(err):
+		return status.Error(codes.InvalidArgument, errors.Wrapf(err, format, args...).Error())
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, errors.Wrapf(err, format, args...).Error())
+	}
+}
+
+// streamStatus keeps statuses, e.g. of sending to the stream, and converts other errors with storageStatus
+func streamStatus(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return storageStatus(err, format, args...)
+}
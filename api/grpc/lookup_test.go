@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// lookupStorage never returns txs of blockingBlock until the request is done
+type lookupStorage struct {
+	*dbtest.Storage
+	blockingBlock uint64
+}
+
+func (f *lookupStorage) GetByBlocknum(ctx context.Context, ch string, blocknum uint64) ([]db.Tx, error) {
+	if blocknum == f.blockingBlock {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.Storage.GetByBlocknum(ctx, ch, blocknum)
+}
+
+func serveLookup(t *testing.T, storage db.Storage, opts Options) pb.FabexClient {
+	serv := NewFabexServer("", "", storage, nil, nil, opts)
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.StreamInterceptor(serv.streamDeadline))
+	pb.RegisterFabexServer(server, serv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewFabexClient(conn)
+}
+
+func TestLookup(t *testing.T) {
+	storage := &lookupStorage{Storage: dbtest.New(), blockingBlock: 100}
+	storage.Add("mychannel",
+		db.Tx{Txid: "tx1", Blocknum: 1, Hash: "h1", PreviousHash: "h0", Chaincode: "fabcar"},
+		db.Tx{Txid: "tx1", Blocknum: 1, Hash: "h1", PreviousHash: "h0", Chaincode: "marbles"},
+		db.Tx{Txid: "tx2", Blocknum: 2, Hash: "h2", PreviousHash: "h1", Chaincode: "fabcar"},
+	)
+	client := serveLookup(t, storage, Options{Channels: []string{"mychannel"}})
+	ctx := context.Background()
+
+	tx, err := client.GetTransaction(ctx, &pb.RequestTransaction{Channelid: "mychannel", Txid: "tx1"})
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", tx.Txid)
+	assert.Len(t, tx.Entries, 2)
+
+	_, err = client.GetTransaction(ctx, &pb.RequestTransaction{Channelid: "mychannel", Txid: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetTransaction(ctx, &pb.RequestTransaction{Channelid: "mychannel"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	block, err := client.GetBlock(ctx, &pb.RequestBlock{Channelid: "mychannel", Blocknum: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "h2", block.Hash)
+	assert.Equal(t, "h1", block.Previoushash)
+	require.Len(t, block.Entries, 1)
+	assert.Equal(t, "tx2", block.Entries[0].Txid)
+
+	_, err = client.GetBlock(ctx, &pb.RequestBlock{Channelid: "mychannel", Blocknum: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	info, err := client.GetChainInfo(ctx, &pb.RequestChainInfo{Channelid: "mychannel"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), info.Height)
+	assert.Equal(t, "h2", info.Currentblockhash)
+
+	_, err = client.GetChainInfo(ctx, &pb.RequestChainInfo{Channelid: "other"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	channels, err := client.ListChannels(ctx, &pb.RequestChannels{})
+	require.NoError(t, err)
+	require.Len(t, channels.Channels, 1)
+	assert.Equal(t, "mychannel", channels.Channels[0].Channelid)
+	assert.Equal(t, uint64(3), channels.Channels[0].Height)
+}
+
+func TestStreamLimits(t *testing.T) {
+	storage := &lookupStorage{Storage: dbtest.New(), blockingBlock: 3}
+	storage.Add("mychannel", db.Tx{Txid: "tx0", Blocknum: 0}, db.Tx{Txid: "tx1", Blocknum: 1}, db.Tx{Txid: "tx2", Blocknum: 2})
+	client := serveLookup(t, storage, Options{MaxRange: 10, StreamTimeout: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	// the channel is required
+	entries, err := client.Get(ctx, &pb.Entry{})
+	require.NoError(t, err)
+	_, err = entries.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	stream, err := client.GetRange(ctx, &pb.RequestRange{Endblock: 1})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// all entries including block 0 are streamed without a filter
+	entries, err = client.Get(ctx, &pb.Entry{Channelid: "mychannel"})
+	require.NoError(t, err)
+	for _, txid := range []string{"tx0", "tx1", "tx2"} {
+		entry, err := entries.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, txid, entry.Txid)
+	}
+	_, err = entries.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// ranges over MaxRange blocks are rejected
+	stream, err = client.GetRange(ctx, &pb.RequestRange{Channelid: "mychannel", Startblock: 1, Endblock: 11})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// streams are stopped when the deadline is reached
+	stream, err = client.GetRange(ctx, &pb.RequestRange{Channelid: "mychannel", Startblock: 1, Endblock: 3})
+	require.NoError(t, err)
+	for _, txid := range []string{"tx1", "tx2"} {
+		entry, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, txid, entry.Txid)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// all entries of channels over MaxRange blocks are read in chunks
+	for blocknum := uint64(4); blocknum <= 10; blocknum++ {
+		storage.Add("mychannel", db.Tx{Txid: "tx", Blocknum: blocknum})
+	}
+	entries, err = client.Get(ctx, &pb.Entry{Channelid: "mychannel"})
+	require.NoError(t, err)
+	var blocks []uint64
+	for {
+		entry, err := entries.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		blocks = append(blocks, entry.Blocknum)
+	}
+	assert.Equal(t, []uint64{0, 1, 2, 4, 5, 6, 7, 8, 9, 10}, blocks)
+}
+
+func TestStatusCodes(t *testing.T) {
+	storage := dbtest.New()
+	storage.Add("mychannel", db.Tx{Txid: "tx1", Blocknum: 1})
+	client := serveLookup(t, storage, Options{})
+	ctx := context.Background()
+
+	_, err := client.List(ctx, &pb.RequestPage{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.List(ctx, &pb.RequestPage{Channelid: "mychannel", Pagetoken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Query(ctx, &pb.RequestQuery{Channelid: "mychannel", Fields: map[string]string{"$where": "1"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetKeyHistory(ctx, &pb.RequestKeyHistory{Channelid: "mychannel", Namespace: "fabcar"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetKeyHistory(ctx, &pb.RequestKeyHistory{Channelid: "mychannel", Namespace: "fabcar", Key: "CAR1", Pagetoken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetState(ctx, &pb.RequestState{Channelid: "mychannel", Namespace: "fabcar", Key: "CAR1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.ScanState(ctx, &pb.RequestStateScan{Channelid: "mychannel"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetRawBlock(ctx, &pb.RequestRawBlock{Channelid: "mychannel", Blocknum: 1})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	sub, err := client.Subscribe(ctx, &pb.RequestSubscribe{})
+	require.NoError(t, err)
+	_, err = sub.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
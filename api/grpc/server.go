@@ -4,22 +4,39 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
+	"time"
 
+	"github.com/hyperledger-labs/fabex/archive"
+	"github.com/hyperledger-labs/fabex/bus"
 	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
 	pb "github.com/hyperledger-labs/fabex/proto"
+	pbv2 "github.com/hyperledger-labs/fabex/proto/v2"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func StartGrpcServ(_ context.Context, serv *FabexServer) error {
-	grpcServer := grpc.NewServer()
+// StartGrpcServ serves requests until ctx is done, then stops the server gracefully
+func StartGrpcServ(ctx context.Context, serv *FabexServer) error {
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(serv.streamDeadline))
 	pb.RegisterFabexServer(grpcServer, serv)
+	pbv2.RegisterFabexServer(grpcServer, NewV2Server(serv))
 
 	l, err := net.Listen("tcp", fmt.Sprintf("%s:%s", serv.address, serv.port))
 	if err != nil {
 		return errors.WithStack(errors.Wrap(err, "failed to listen port"))
 	}
 
+	go func() {
+		<-ctx.Done()
+		// graceful stop waits for streams, subscriptions never end by themselves
+		close(serv.stop)
+		grpcServer.GracefulStop()
+	}()
+
 	// start server
 	if err := grpcServer.Serve(l); err != nil {
 		return errors.WithStack(err)
@@ -28,20 +45,96 @@ func StartGrpcServ(_ context.Context, serv *FabexServer) error {
 	return nil
 }
 
+const (
+	// DefaultMaxRange is the max number of blocks of a range request by default
+	DefaultMaxRange = 1000
+	// DefaultStreamTimeout limits duration of finite streams by default
+	DefaultStreamTimeout = 5 * time.Minute
+)
+
+// Options of the server, zero values are replaced with defaults
+type Options struct {
+	// Channels are channels explored by fabex, other channels aren't described if it's not empty
+	Channels []string
+	// MaxRange is the max number of blocks of GetRange and v2 GetBlocks requests
+	MaxRange uint64
+	// StreamTimeout limits duration of server streams, subscriptions aren't limited
+	StreamTimeout time.Duration
+}
+
 type FabexServer struct {
 	pb.UnimplementedFabexServer
 	address string
 	port    string
 	db      db.Storage
+	blocks  db.RawBlockStore
+	// events is nil if blocks aren't stored by this process, subscriptions poll the database then
+	events *bus.Bus
+	opts   Options
+	stop   chan struct{}
+}
+
+// NewFabexServer creates server, blocks is nil if blocks aren't archived, events is nil if blocks aren't indexed
+// by this process
+func NewFabexServer(addr string, port string, database db.Storage, blocks db.RawBlockStore, events *bus.Bus, opts Options) *FabexServer {
+	if opts.MaxRange == 0 {
+		opts.MaxRange = DefaultMaxRange
+	}
+	if opts.StreamTimeout <= 0 {
+		opts.StreamTimeout = DefaultStreamTimeout
+	}
+	return &FabexServer{address: addr, port: port, db: database, blocks: blocks, events: events, opts: opts, stop: make(chan struct{})}
+}
+
+// unlimitedStreams are streams which never end by themselves, so they aren't limited by StreamTimeout
+var unlimitedStreams = map[string]bool{
+	"/fabex.Fabex/Subscribe":    true,
+	"/fabex.v2.Fabex/Subscribe": true,
+}
+
+// streamDeadline limits duration of finite server streams, DeadlineExceeded is returned when the limit is reached
+func (s *FabexServer) streamDeadline(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if unlimitedStreams[info.FullMethod] {
+		return handler(srv, ss)
+	}
+
+	ctx, cancel := context.WithTimeout(ss.Context(), s.opts.StreamTimeout)
+	defer cancel()
+	err := handler(srv, &deadlineStream{ServerStream: ss, ctx: ctx})
+	if err != nil && ctx.Err() == context.DeadlineExceeded && ss.Context().Err() == nil {
+		return status.Errorf(codes.DeadlineExceeded, "stream exceeded %s", s.opts.StreamTimeout)
+	}
+	return err
+}
+
+// deadlineStream replaces the context of the stream
+type deadlineStream struct {
+	grpc.ServerStream
+	ctx context.Context
 }
 
-func NewFabexServer(addr string, port string, database db.Storage) *FabexServer {
-	return &FabexServer{address: addr, port: port, db: database}
+func (s *deadlineStream) Context() context.Context {
+	return s.ctx
+}
+
+// checkRange returns InvalidArgument if the range [from, to] exceeds MaxRange blocks
+func (s *FabexServer) checkRange(from, to uint64) error {
+	if to >= from && to-from >= s.opts.MaxRange {
+		return status.Errorf(codes.InvalidArgument, "range of blocks %d-%d exceeds %d blocks", from, to, s.opts.MaxRange)
+	}
+	return nil
 }
 
 func (s *FabexServer) GetRange(req *pb.RequestRange, stream pb.Fabex_GetRangeServer) error {
 	if req.Channelid == "" {
-		return errors.New("no channel ID specified")
+		return status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	if req.Startblock < 0 || req.Endblock < 0 {
+		return status.Error(codes.InvalidArgument, "negative block number")
+	}
+	if err := s.checkRange(uint64(req.Startblock), uint64(req.Endblock)); err != nil {
+		return err
 	}
 
 	// set blocks counter to latest saved in db block number value
@@ -49,23 +142,13 @@ func (s *FabexServer) GetRange(req *pb.RequestRange, stream pb.Fabex_GetRangeSer
 
 	// insert missing blocks/txs into db
 	for blockCounter <= req.Endblock {
-		QueryResults, err := s.db.GetByBlocknum(req.Channelid, uint64(blockCounter))
+		QueryResults, err := s.db.GetByBlocknum(stream.Context(), req.Channelid, uint64(blockCounter))
 		if err != nil {
-			return errors.Wrapf(err, "failed to get txs by block number %d", blockCounter)
+			return storageStatus(err, "failed to get txs by block number %d", blockCounter)
 		}
-		if QueryResults != nil {
-			for _, queryResult := range QueryResults {
-				if err = stream.Send(&pb.Entry{
-					Channelid:      queryResult.ChannelId,
-					Txid:           queryResult.Txid,
-					Hash:           queryResult.Hash,
-					Previoushash:   queryResult.PreviousHash,
-					Blocknum:       queryResult.Blocknum,
-					Payload:        queryResult.Payload,
-					Time:           queryResult.Time,
-					Validationcode: queryResult.ValidationCode}); err != nil {
-					return err
-				}
+		for _, queryResult := range QueryResults {
+			if err = stream.Send(pb.EntryFromTx(queryResult)); err != nil {
+				return err
 			}
 		}
 		blockCounter++
@@ -74,58 +157,290 @@ func (s *FabexServer) GetRange(req *pb.RequestRange, stream pb.Fabex_GetRangeSer
 	return nil
 }
 
+// Get streams entries selected by the first specified field of txid, blocknum and payload. Unset blocknum can't be
+// told apart from block 0, so block 0 is streamed with all entries of the channel, which are read in chunks of
+// MaxRange blocks.
 func (s *FabexServer) Get(req *pb.Entry, stream pb.Fabex_GetServer) error {
 	if req.Channelid == "" {
-		return errors.New("no channel ID specified")
+		return status.Error(codes.InvalidArgument, "no channel ID specified")
 	}
 
 	switch {
 	case req.Txid != "":
-		queryFunc := func() ([]db.Tx, error) {
-			return s.db.GetByTxId(req.Channelid, req.Txid)
+		queryFunc := func(ctx context.Context) ([]db.Tx, error) {
+			return s.db.GetByTxId(ctx, req.Channelid, req.Txid)
 		}
 		return query(stream, queryFunc)
 
 	case req.Blocknum != 0:
-		queryFunc := func() ([]db.Tx, error) {
-			return s.db.GetByBlocknum(req.Channelid, req.Blocknum)
+		queryFunc := func(ctx context.Context) ([]db.Tx, error) {
+			return s.db.GetByBlocknum(ctx, req.Channelid, req.Blocknum)
 		}
 		return query(stream, queryFunc)
 
 	case req.Payload != nil:
-		queryFunc := func() ([]db.Tx, error) {
-			return s.db.GetBlockInfoByPayload(req.Channelid, string(req.Payload))
+		queryFunc := func(ctx context.Context) ([]db.Tx, error) {
+			return s.db.GetBlockInfoByPayload(ctx, req.Channelid, string(req.Payload))
 		}
 		return query(stream, queryFunc)
 
 	default:
-		// set blocks counter to latest saved in db block number value
-		blockCounter := 1
-
-		// insert missing blocks/txs into db
-		for {
-			queryResults, err := s.db.GetByBlocknum(req.Channelid, uint64(blockCounter))
-			if err != nil {
-				return errors.Wrapf(err, "failed to get txs by block number %d", blockCounter)
+		last, err := s.db.GetLastEntry(stream.Context(), req.Channelid)
+		if err != nil {
+			if err.Error() == db.NOT_FOUND_ERR {
+				return nil
 			}
-			if queryResults == nil {
-				break
+			return storageStatus(err, "failed to get the last block")
+		}
+
+		send := func(_ uint64, txs []db.Tx) error {
+			return sendStream(stream, txs)
+		}
+		for from := uint64(0); from <= last.Blocknum; from += s.opts.MaxRange {
+			to := from + s.opts.MaxRange - 1
+			if to > last.Blocknum {
+				to = last.Blocknum
 			}
-			if err = sendStream(stream, queryResults); err != nil {
-				return err
+			err = helpers.QueryBlocks(stream.Context(), s.db, req.Channelid, db.Filter{}, from, to, send)
+			if err != nil {
+				return streamStatus(err, "failed to query blocks %d-%d", from, to)
 			}
+		}
+		return nil
+	}
+}
+
+func (s *FabexServer) List(ctx context.Context, req *pb.RequestPage) (*pb.Page, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	page := db.Page{Limit: req.Pagesize, Cursor: req.Pagetoken}
+	if req.Order == pb.SortOrder_DESC {
+		page.Order = db.Descending
+	}
+
+	var (
+		txs  []db.Tx
+		next string
+		err  error
+	)
+	if req.Payload != nil {
+		txs, next, err = s.db.GetBlockInfoByPayloadPage(ctx, req.Channelid, string(req.Payload), page)
+	} else {
+		txs, next, err = s.db.QueryAllPage(ctx, req.Channelid, page)
+	}
+	if err != nil {
+		return nil, storageStatus(err, "failed to query txs")
+	}
+
+	resp := &pb.Page{Nextpagetoken: next}
+	for _, tx := range txs {
+		resp.Entries = append(resp.Entries, pb.EntryFromTx(tx))
+	}
+
+	return resp, nil
+}
+
+func (s *FabexServer) Query(ctx context.Context, req *pb.RequestQuery) (*pb.Page, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	filter := db.Filter{
+		FromBlock:  req.Fromblock,
+		ToBlock:    req.Toblock,
+		Chaincode:  req.Chaincode,
+		Key:        req.Key,
+		KeyPrefix:  req.Keyprefix,
+		CreatorMSP: req.Creatormsp,
+		TxType:     req.Txtype,
+		Page:       db.Page{Limit: req.Pagesize, Cursor: req.Pagetoken},
+	}
+	if req.Fromtime != 0 {
+		filter.FromTime = time.Unix(req.Fromtime, 0)
+	}
+	if req.Totime != 0 {
+		filter.ToTime = time.Unix(req.Totime, 0)
+	}
+	if req.Validationcode != nil {
+		code := req.Validationcode.Value
+		filter.ValidationCode = &code
+	}
+	if req.Order == pb.SortOrder_DESC {
+		filter.Order = db.Descending
+	}
+	fields, err := fieldFilters(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	filter.Fields = fields
+
+	txs, next, err := s.db.Query(ctx, req.Channelid, filter)
+	if err != nil {
+		return nil, storageStatus(err, "failed to query txs")
+	}
+
+	resp := &pb.Page{Nextpagetoken: next}
+	for _, tx := range txs {
+		resp.Entries = append(resp.Entries, pb.EntryFromTx(tx))
+	}
+
+	return resp, nil
+}
+
+// fieldFilters parses document field filters by paths in the order of paths
+func fieldFilters(fields map[string]string) ([]db.FieldFilter, error) {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var filters []db.FieldFilter
+	for _, path := range paths {
+		field, err := db.ParseFieldFilter(path, fields[path])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		filters = append(filters, field)
+	}
+	return filters, nil
+}
+
+func (s *FabexServer) GetKeyHistory(ctx context.Context, req *pb.RequestKeyHistory) (*pb.KeyHistory, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+	if req.Namespace == "" || req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace and key must be specified")
+	}
+
+	page := db.Page{Limit: req.Pagesize, Cursor: req.Pagetoken}
+	if req.Order == pb.SortOrder_DESC {
+		page.Order = db.Descending
+	}
+
+	mods, next, err := s.db.GetKeyHistory(ctx, req.Channelid, req.Namespace, req.Key, page)
+	if err != nil {
+		return nil, storageStatus(err, "failed to get key history")
+	}
+
+	resp := &pb.KeyHistory{Nextpagetoken: next}
+	for _, mod := range mods {
+		resp.Modifications = append(resp.Modifications, pb.KeyModificationFromDB(mod))
+	}
+
+	return resp, nil
+}
+
+func (s *FabexServer) GetCompositeKeyHistory(ctx context.Context, req *pb.RequestCompositeKeyHistory) (*pb.KeyHistory, error) {
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+	if req.Namespace == "" || req.Objecttype == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace and object type must be specified")
+	}
+
+	page := db.Page{Limit: req.Pagesize, Cursor: req.Pagetoken}
+	if req.Order == pb.SortOrder_DESC {
+		page.Order = db.Descending
+	}
+
+	mods, next, err := s.db.GetCompositeKeyHistory(ctx, req.Channelid, req.Namespace, req.Objecttype, req.Attributes, page)
+	if err != nil {
+		return nil, storageStatus(err, "failed to get composite key history")
+	}
+
+	resp := &pb.KeyHistory{Nextpagetoken: next}
+	for _, mod := range mods {
+		resp.Modifications = append(resp.Modifications, pb.KeyModificationFromDB(mod))
+	}
+
+	return resp, nil
+}
+
+func (s *FabexServer) stateStore() (db.StateStore, error) {
+	store, ok := s.db.(db.StateStore)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "world state is not supported by the database")
+	}
+	return store, nil
+}
+
+func (s *FabexServer) GetState(ctx context.Context, req *pb.RequestState) (*pb.KeyModification, error) {
+	store, err := s.stateStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+	if req.Namespace == "" || req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace and key must be specified")
+	}
+
+	var mod db.KeyModification
+	if req.Block == nil {
+		mod, err = store.GetState(ctx, req.Channelid, req.Namespace, req.Key)
+	} else {
+		mod, err = store.GetStateAt(ctx, req.Channelid, req.Namespace, req.Key, req.Block.Value)
+	}
+	if err != nil {
+		return nil, storageStatus(err, "failed to get state of %s", req.Key)
+	}
+
+	return pb.KeyModificationFromDB(mod), nil
+}
 
-			blockCounter++
+func (s *FabexServer) ScanState(ctx context.Context, req *pb.RequestStateScan) (*pb.StatePage, error) {
+	store, err := s.stateStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+	if req.Namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace must be specified")
+	}
+
+	scan := db.StateScan{
+		StartKey: req.Startkey,
+		EndKey:   req.Endkey,
+		Prefix:   req.Prefix,
+		Page:     db.Page{Limit: req.Pagesize, Cursor: req.Pagetoken},
+	}
+	if req.Order == pb.SortOrder_DESC {
+		scan.Order = db.Descending
+	}
+	if req.Block != nil {
+		block := req.Block.Value
+		scan.AsOfBlock = &block
+	}
+	if req.Objecttype != "" {
+		if scan.Prefix, err = helpers.CreateCompositeKey(req.Objecttype, req.Attributes); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
 	}
 
-	return nil
+	mods, next, err := store.ScanState(ctx, req.Channelid, req.Namespace, scan)
+	if err != nil {
+		return nil, storageStatus(err, "failed to scan state")
+	}
+
+	resp := &pb.StatePage{Nextpagetoken: next}
+	for _, mod := range mods {
+		resp.Entries = append(resp.Entries, pb.KeyModificationFromDB(mod))
+	}
+
+	return resp, nil
 }
 
-func query(stream pb.Fabex_GetServer, queryf func() ([]db.Tx, error)) error {
-	queryResults, err := queryf()
+func query(stream pb.Fabex_GetServer, queryf func(ctx context.Context) ([]db.Tx, error)) error {
+	queryResults, err := queryf(stream.Context())
 	if err != nil {
-		return err
+		return storageStatus(err, "failed to query txs")
 	}
 
 	return sendStream(stream, queryResults)
@@ -133,17 +448,29 @@ func query(stream pb.Fabex_GetServer, queryf func() ([]db.Tx, error)) error {
 
 func sendStream(stream pb.Fabex_GetServer, queryResults []db.Tx) error {
 	for _, qr := range queryResults {
-		if err := stream.Send(&pb.Entry{
-			Channelid:      qr.ChannelId,
-			Txid:           qr.Txid,
-			Hash:           qr.Hash,
-			Previoushash:   qr.PreviousHash,
-			Blocknum:       qr.Blocknum,
-			Payload:        qr.Payload,
-			Time:           qr.Time,
-			Validationcode: qr.ValidationCode}); err != nil {
+		if err := stream.Send(pb.EntryFromTx(qr)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+func (s *FabexServer) GetRawBlock(ctx context.Context, req *pb.RequestRawBlock) (*pb.RawBlock, error) {
+	if s.blocks == nil {
+		return nil, status.Error(codes.Unimplemented, "block archive is disabled")
+	}
+	if req.Channelid == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	raw, err := s.blocks.GetRawBlock(ctx, req.Channelid, req.Blocknum)
+	if err != nil {
+		return nil, storageStatus(err, "failed to get raw block %d", req.Blocknum)
+	}
+	_, hash, err := archive.Verify(raw)
+	if err != nil {
+		return nil, status.Errorf(codes.DataLoss, "raw block %d: %s", req.Blocknum, err)
+	}
+
+	return &pb.RawBlock{Block: raw, Headerhash: hash}, nil
+}
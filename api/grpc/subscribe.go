@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Subscribe streams txs of complete blocks only, so a block being stored is never cut in half
+func (s *FabexServer) Subscribe(req *pb.RequestSubscribe, stream pb.Fabex_SubscribeServer) error {
+	if req.Channelid == "" {
+		return status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	from := req.Fromblock
+	if req.Checkpoint != nil {
+		from = req.Checkpoint.Value + 1
+	}
+	filter := db.Filter{Chaincode: req.Chaincode, KeyPrefix: req.Keyprefix}
+	if req.Validationcode != nil {
+		code := req.Validationcode.Value
+		filter.ValidationCode = &code
+	}
+
+	return s.follow(stream.Context(), req.Channelid, filter, from, func(_ uint64, txs []db.Tx) error {
+		for _, tx := range txs {
+			if err := stream.Send(pb.EntryFromTx(tx)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// follow calls helpers.Follow until ctx is done or the server stops, Unavailable is returned if the server stops
+func (s *FabexServer) follow(ctx context.Context, ch string, filter db.Filter, from uint64, send func(blocknum uint64, txs []db.Tx) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.stop:
+			cancel()
+		}
+	}()
+
+	err := helpers.Follow(ctx, s.db, s.events, ch, filter, from, send)
+	select {
+	case <-s.stop:
+		return status.Error(codes.Unavailable, "server is shutting down")
+	default:
+		return streamStatus(err, "failed to follow blocks of %s", ch)
+	}
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/bus"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/helpers"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSubscribe(t *testing.T) {
+	storage := dbtest.New()
+	storage.Add("mychannel", db.Tx{Txid: "tx0", Blocknum: 0}, db.Tx{Txid: "tx1", Blocknum: 1, Chaincode: "fabcar"}, db.Tx{Txid: "tx2", Blocknum: 2, Chaincode: "fabcar"})
+	require.NoError(t, storage.SetCheckpoint(context.Background(), "mychannel", helpers.StoredCheckpoint, 2))
+	events := bus.New()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterFabexServer(server, NewFabexServer("", "", storage, nil, events, Options{}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := pb.NewFabexClient(conn).Subscribe(ctx, &pb.RequestSubscribe{Channelid: "mychannel", Checkpoint: wrapperspb.UInt64(0), Chaincode: "fabcar"})
+	require.NoError(t, err)
+
+	// stored blocks are replayed after the checkpoint
+	for _, txid := range []string{"tx1", "tx2"} {
+		entry, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, txid, entry.Txid)
+	}
+
+	// new blocks are streamed when they are stored completely
+	storage.Add("mychannel", db.Tx{Txid: "tx3", Blocknum: 3, Chaincode: "other"}, db.Tx{Txid: "tx4", Blocknum: 3, Chaincode: "fabcar"})
+	require.NoError(t, events.Publish(ctx, bus.Event{Channel: "mychannel", Block: &blockhandler.CustomBlock{Number: 3}}))
+	entry, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "tx4", entry.Txid)
+}
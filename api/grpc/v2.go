@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/hyperledger-labs/fabex/archive"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	pbv2 "github.com/hyperledger-labs/fabex/proto/v2"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// V2Server serves fabex.v2 API with storages of the v1 server
+type V2Server struct {
+	pbv2.UnimplementedFabexServer
+	s *FabexServer
+}
+
+// NewV2Server creates server of fabex.v2 API, it stops streaming when the v1 server stops
+func NewV2Server(s *FabexServer) *V2Server {
+	return &V2Server{s: s}
+}
+
+func (v *V2Server) Query(ctx context.Context, req *pbv2.QueryRequest) (*pbv2.QueryResponse, error) {
+	if req.ChannelId == "" {
+		return nil, status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	filter, err := filterFromV2(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Page = db.Page{Limit: req.PageSize, Cursor: req.PageToken}
+	if req.Order == pbv2.Order_DESC {
+		filter.Order = db.Descending
+	}
+
+	txs, next, err := v.s.db.Query(ctx, req.ChannelId, filter)
+	if err != nil {
+		return nil, storageStatus(err, "failed to query txs")
+	}
+	transactions, err := pbv2.TransactionsFromTxs(txs, v.details(ctx, req.ChannelId))
+	if err != nil {
+		return nil, streamStatus(err, "failed to convert txs")
+	}
+
+	return &pbv2.QueryResponse{Transactions: transactions, NextPageToken: next}, nil
+}
+
+func (v *V2Server) GetBlocks(req *pbv2.GetBlocksRequest, stream pbv2.Fabex_GetBlocksServer) error {
+	if req.ChannelId == "" {
+		return status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	ctx := stream.Context()
+	var to uint64
+	if req.ToBlock != nil {
+		to = req.ToBlock.Value
+	} else {
+		last, err := v.s.db.GetLastEntry(ctx, req.ChannelId)
+		if err != nil {
+			if err.Error() == db.NOT_FOUND_ERR {
+				return nil
+			}
+			return storageStatus(err, "failed to get the last block")
+		}
+		to = last.Blocknum
+	}
+	if req.FromBlock > to {
+		return nil
+	}
+	if err := v.s.checkRange(req.FromBlock, to); err != nil {
+		return err
+	}
+
+	details := v.details(ctx, req.ChannelId)
+	err := helpers.QueryBlocks(ctx, v.s.db, req.ChannelId, db.Filter{}, req.FromBlock, to, func(blocknum uint64, txs []db.Tx) error {
+		return sendBlock(stream, txs, details)
+	})
+	return streamStatus(err, "failed to get blocks %d-%d", req.FromBlock, to)
+}
+
+func (v *V2Server) Subscribe(req *pbv2.SubscribeRequest, stream pbv2.Fabex_SubscribeServer) error {
+	if req.ChannelId == "" {
+		return status.Error(codes.InvalidArgument, "no channel ID specified")
+	}
+
+	from := req.FromBlock
+	if req.Checkpoint != nil {
+		from = req.Checkpoint.Value + 1
+	}
+	filter, err := filterFromV2(req.Filter)
+	if err != nil {
+		return err
+	}
+
+	details := v.details(stream.Context(), req.ChannelId)
+	return v.s.follow(stream.Context(), req.ChannelId, filter, from, func(_ uint64, txs []db.Tx) error {
+		return sendBlock(stream, txs, details)
+	})
+}
+
+// blockSender is a stream of blocks
+type blockSender interface {
+	Send(*pbv2.Block) error
+}
+
+func sendBlock(stream blockSender, txs []db.Tx, details func(blocknum uint64) (*blockhandler.CustomBlock, error)) error {
+	custom, err := details(txs[0].Blocknum)
+	if err != nil {
+		return err
+	}
+	block, err := pbv2.BlockFromTxs(txs, custom)
+	if err != nil {
+		return streamStatus(err, "failed to convert block %d", txs[0].Blocknum)
+	}
+	return stream.Send(block)
+}
+
+// details returns decoded archived blocks of the channel with reads and events of txs, the last block is cached
+// as consecutive txs mostly belong to the same block. Nil is returned if blocks aren't archived.
+func (v *V2Server) details(ctx context.Context, ch string) func(blocknum uint64) (*blockhandler.CustomBlock, error) {
+	var cached *blockhandler.CustomBlock
+	return func(blocknum uint64) (*blockhandler.CustomBlock, error) {
+		if v.s.blocks == nil {
+			return nil, nil
+		}
+		if cached != nil && cached.Number == blocknum {
+			return cached, nil
+		}
+
+		raw, err := v.s.blocks.GetRawBlock(ctx, ch, blocknum)
+		if err != nil {
+			// blocks stored before archiving was enabled
+			if err.Error() == db.NOT_FOUND_ERR {
+				return nil, nil
+			}
+			return nil, storageStatus(err, "failed to get raw block %d", blocknum)
+		}
+		block, _, err := archive.Verify(raw)
+		if err != nil {
+			return nil, status.Errorf(codes.DataLoss, "raw block %d: %s", blocknum, err)
+		}
+		if cached, err = blockhandler.HandleBlock(block); err != nil {
+			return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to decode block %d", blocknum).Error())
+		}
+		return cached, nil
+	}
+}
+
+// filterFromV2 converts the filter of v2 requests, nil filter selects all txs
+func filterFromV2(f *pbv2.Filter) (db.Filter, error) {
+	if f == nil {
+		return db.Filter{}, nil
+	}
+
+	filter := db.Filter{
+		FromBlock:  f.FromBlock,
+		ToBlock:    f.ToBlock,
+		Chaincode:  f.Chaincode,
+		Key:        f.Key,
+		KeyPrefix:  f.KeyPrefix,
+		CreatorMSP: f.CreatorMspId,
+		TxType:     f.Type,
+	}
+	if f.FromTime != nil {
+		filter.FromTime = f.FromTime.AsTime()
+	}
+	if f.ToTime != nil {
+		filter.ToTime = f.ToTime.AsTime()
+	}
+	if f.ValidationCode != nil {
+		code := f.ValidationCode.Value
+		filter.ValidationCode = &code
+	}
+	fields, err := fieldFilters(f.Fields)
+	if err != nil {
+		return filter, err
+	}
+	filter.Fields = fields
+	return filter, nil
+}
@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/models"
+	pbv2 "github.com/hyperledger-labs/fabex/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func writeSet(t *testing.T, key, value string) []byte {
+	payload, err := json.Marshal([]models.WriteKV{{Key: key, Value: base64.StdEncoding.EncodeToString([]byte(value))}})
+	require.NoError(t, err)
+	return payload
+}
+
+func TestV2(t *testing.T) {
+	storage := dbtest.New()
+	storage.Add("mychannel",
+		db.Tx{Txid: "tx1", Blocknum: 1, TxNum: 0, Chaincode: "fabcar", Payload: writeSet(t, "CAR1", `{"make":"Toyota"}`),
+			Documents: []db.Document{{Key: "CAR1", Value: map[string]interface{}{"make": "Toyota"}}}},
+		db.Tx{Txid: "tx1", Blocknum: 1, TxNum: 0, Chaincode: "marbles", Payload: writeSet(t, "marble1", "")},
+		db.Tx{Txid: "tx2", Blocknum: 2, TxNum: 0, Chaincode: "fabcar", ValidationCode: 11, Payload: writeSet(t, "CAR1", "blue")},
+		// positions of txs stored before they were kept are 0
+		db.Tx{Txid: "tx3", Blocknum: 2, TxNum: 0, Chaincode: "fabcar", Payload: writeSet(t, "CAR2", "red")},
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pbv2.RegisterFabexServer(server, NewV2Server(NewFabexServer("", "", storage, nil, nil, Options{})))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pbv2.NewFabexClient(conn)
+
+	// writes of a tx to several chaincodes are merged
+	resp, err := client.Query(context.Background(), &pbv2.QueryRequest{ChannelId: "mychannel", Filter: &pbv2.Filter{ToBlock: 1}})
+	require.NoError(t, err)
+	require.Len(t, resp.Transactions, 1)
+	tx := resp.Transactions[0]
+	assert.Equal(t, "tx1", tx.Id)
+	assert.True(t, tx.Valid)
+	require.Len(t, tx.Writes, 2)
+	assert.Equal(t, "fabcar", tx.Writes[0].Namespace)
+	assert.Equal(t, []byte(`{"make":"Toyota"}`), tx.Writes[0].Value)
+	assert.Equal(t, "Toyota", tx.Writes[0].Document.Fields["make"].GetStringValue())
+	assert.Equal(t, "marbles", tx.Writes[1].Namespace)
+	assert.True(t, tx.Writes[1].IsDelete)
+
+	stream, err := client.GetBlocks(context.Background(), &pbv2.GetBlocksRequest{ChannelId: "mychannel", FromBlock: 1})
+	require.NoError(t, err)
+	var blocks []*pbv2.Block
+	for {
+		block, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		blocks = append(blocks, block)
+	}
+	require.Len(t, blocks, 2)
+	assert.EqualValues(t, 2, blocks[1].Number)
+	// txs with the same position aren't merged if their ids differ
+	require.Len(t, blocks[1].Transactions, 2)
+	assert.False(t, blocks[1].Transactions[0].Valid)
+	assert.Equal(t, "blue", string(blocks[1].Transactions[0].Writes[0].Value))
+	assert.Equal(t, "tx3", blocks[1].Transactions[1].Id)
+	require.Len(t, blocks[1].Transactions[1].Writes, 1)
+
+	_, err = client.Query(context.Background(), &pbv2.QueryRequest{ChannelId: "mychannel", PageToken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	stream, err = client.GetBlocks(context.Background(), &pbv2.GetBlocksRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -1,109 +1,484 @@
 package rest
 
 import (
+	"encoding/hex"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/archive"
+	"github.com/hyperledger-labs/fabex/bus"
 	fabdb "github.com/hyperledger-labs/fabex/db"
 	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/sink"
+	"github.com/pkg/errors"
 )
 
-func bytxid(db fabdb.Storage) func(c *gin.Context) {
+// blockHandler responds with the block of txs returned by queryf, 404 if there are no txs
+func blockHandler(queryf func(c *gin.Context, ch string) ([]fabdb.Tx, error)) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		txid := c.Param("txid")
 		ch := c.Param("channel")
 		if ch == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "no channel ID specified",
-				"msg":   nil,
-			})
+			fail(c, http.StatusBadRequest, errors.New("no channel ID specified"))
 			return
 		}
 
-		queryResults, err := db.GetByTxId(ch, txid)
+		queryResults, err := queryf(c, ch)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": err.Error(),
-				"msg":   nil,
-			})
+			failStorage(c, err)
 			return
 		}
 
 		if len(queryResults) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "no such data",
-				"msg":   nil,
-			})
+			fail(c, http.StatusNotFound, errNoData)
 			return
 		}
 
 		blocks, err := helpers.PackTxsToBlocks(queryResults)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-				"msg":   nil,
-			})
+			fail(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		c.JSON(200, gin.H{
-			"error": "",
-			"msg":   blocks[0],
-		})
+		respond(c, http.StatusOK, blocks[0])
 	}
 }
 
+func bytxid(db fabdb.Storage) func(c *gin.Context) {
+	return blockHandler(func(c *gin.Context, ch string) ([]fabdb.Tx, error) {
+		return db.GetByTxId(c.Request.Context(), ch, c.Param("txid"))
+	})
+}
+
 func byblocknum(db fabdb.Storage) func(c *gin.Context) {
+	return blockHandler(func(c *gin.Context, ch string) ([]fabdb.Tx, error) {
+		blocknum, err := parseBlocknum(c)
+		if err != nil {
+			return nil, err
+		}
+		return db.GetByBlocknum(c.Request.Context(), ch, blocknum)
+	})
+}
+
+func byhash(db fabdb.Storage) func(c *gin.Context) {
+	return blockHandler(func(c *gin.Context, ch string) ([]fabdb.Tx, error) {
+		return db.QueryBlockByHash(c.Request.Context(), ch, c.Param("hash"))
+	})
+}
+
+// parseBlocknum parses :blocknum param
+func parseBlocknum(c *gin.Context) (uint64, error) {
+	blocknum, err := strconv.ParseUint(c.Param("blocknum"), 10, 64)
+	if err != nil {
+		return 0, badRequest{errors.Errorf("invalid block number: %s", c.Param("blocknum"))}
+	}
+	return blocknum, nil
+}
+
+// parsePage reads pagination parameters from ?limit=&cursor=&order= query
+func parsePage(c *gin.Context) (fabdb.Page, error) {
+	page := fabdb.Page{Cursor: c.Query("cursor")}
+
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || l <= 0 {
+			return page, errors.Errorf("invalid limit: %s", limit)
+		}
+		page.Limit = l
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+		page.Order = fabdb.Ascending
+	case "desc":
+		page.Order = fabdb.Descending
+	default:
+		return page, errors.Errorf("invalid order: %s", c.Query("order"))
+	}
+
+	return page, nil
+}
+
+// pageHandler responds with a page of txs packed to blocks and a cursor of the next page
+func pageHandler(queryf func(c *gin.Context, ch string, page fabdb.Page) ([]fabdb.Tx, string, error)) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		blocknum := c.Param("blocknum")
 		ch := c.Param("channel")
 		if ch == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "no channel ID specified",
-				"msg":   nil,
-			})
+			fail(c, http.StatusBadRequest, errors.New("no channel ID specified"))
 			return
 		}
 
-		blocknumconverted, err := strconv.Atoi(blocknum)
+		page, err := parsePage(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-				"msg":   nil,
-			})
+			fail(c, http.StatusBadRequest, err)
 			return
 		}
 
-		queryResults, err := db.GetByBlocknum(ch, uint64(blocknumconverted))
+		queryResults, next, err := queryf(c, ch, page)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": err.Error(),
-				"msg":   nil,
-			})
+			failStorage(c, err)
 			return
 		}
 
-		if len(queryResults) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "no such data",
-				"msg":   nil,
-			})
+		blocks, err := helpers.PackTxsToBlocks(queryResults)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		blocks, err := helpers.PackTxsToBlocks(queryResults)
+		respondPage(c, blocks, next)
+	}
+}
+
+func txs(db fabdb.Storage) func(c *gin.Context) {
+	return pageHandler(func(c *gin.Context, ch string, page fabdb.Page) ([]fabdb.Tx, string, error) {
+		return db.QueryAllPage(c.Request.Context(), ch, page)
+	})
+}
+
+func bypayload(db fabdb.Storage) func(c *gin.Context) {
+	return pageHandler(func(c *gin.Context, ch string, page fabdb.Page) ([]fabdb.Tx, string, error) {
+		return db.GetBlockInfoByPayloadPage(c.Request.Context(), ch, c.Param("payload"), page)
+	})
+}
+
+// parseFilter reads query filters from ?fromblock=&toblock=&fromtime=&totime=&validationcode=&chaincode=&key=&keyprefix=&creatormsp=&txtype=,
+// time is unix time in seconds. Document fields are filtered by ?field.<path>=<value>, e.g. ?field.owner=Tomoko
+func parseFilter(c *gin.Context, page fabdb.Page) (fabdb.Filter, error) {
+	filter := fabdb.Filter{
+		Chaincode:  c.Query("chaincode"),
+		Key:        c.Query("key"),
+		KeyPrefix:  c.Query("keyprefix"),
+		CreatorMSP: c.Query("creatormsp"),
+		TxType:     c.Query("txtype"),
+		Page:       page,
+	}
+
+	for param, dest := range map[string]*uint64{"fromblock": &filter.FromBlock, "toblock": &filter.ToBlock} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return filter, errors.Errorf("invalid %s: %s", param, v)
+			}
+			*dest = n
+		}
+	}
+
+	for param, dest := range map[string]*time.Time{"fromtime": &filter.FromTime, "totime": &filter.ToTime} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return filter, errors.Errorf("invalid %s: %s", param, v)
+			}
+			*dest = time.Unix(n, 0)
+		}
+	}
+
+	if v := c.Query("validationcode"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return filter, errors.Errorf("invalid validationcode: %s", v)
+		}
+		code := int32(n)
+		filter.ValidationCode = &code
+	}
+
+	params := c.Request.URL.Query()
+	paths := make([]string, 0, len(params))
+	for param := range params {
+		if strings.HasPrefix(param, fieldParamPrefix) {
+			paths = append(paths, strings.TrimPrefix(param, fieldParamPrefix))
+		}
+	}
+	// keep filters order stable, so cursors of the same query are interchangeable
+	sort.Strings(paths)
+	for _, path := range paths {
+		field, err := fabdb.ParseFieldFilter(path, params.Get(fieldParamPrefix+path))
+		if err != nil {
+			return filter, err
+		}
+		filter.Fields = append(filter.Fields, field)
+	}
+
+	return filter, nil
+}
+
+const fieldParamPrefix = "field."
+
+func query(db fabdb.Storage) func(c *gin.Context) {
+	return pageHandler(func(c *gin.Context, ch string, page fabdb.Page) ([]fabdb.Tx, string, error) {
+		filter, err := parseFilter(c, page)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-				"msg":   nil,
-			})
+			return nil, "", badRequest{err}
+		}
+		return db.Query(c.Request.Context(), ch, filter)
+	})
+}
+
+func keyhistory(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ch := c.Param("channel")
+		namespace, key := c.Query("namespace"), c.Query("key")
+		if ch == "" || namespace == "" || key == "" {
+			fail(c, http.StatusBadRequest, errors.New("channel ID, namespace and key must be specified"))
+			return
+		}
+
+		page, err := parsePage(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
 			return
 		}
 
-		c.JSON(200, gin.H{
-			"error": "",
-			"msg":   blocks[0],
+		mods, next, err := db.GetKeyHistory(c.Request.Context(), ch, namespace, key, page)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respondPage(c, mods, next)
+	}
+}
+
+func compositekeyhistory(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ch := c.Param("channel")
+		namespace, objectType := c.Query("namespace"), c.Query("objecttype")
+		if ch == "" || namespace == "" || objectType == "" {
+			fail(c, http.StatusBadRequest, errors.New("channel ID, namespace and object type must be specified"))
+			return
+		}
+
+		page, err := parsePage(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+
+		mods, next, err := db.GetCompositeKeyHistory(c.Request.Context(), ch, namespace, objectType, c.QueryArray("attribute"), page)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respondPage(c, mods, next)
+	}
+}
+
+// stateStore returns state store of the database or responds with 501 if the database doesn't support it
+func stateStore(c *gin.Context, db fabdb.Storage) (fabdb.StateStore, bool) {
+	store, ok := db.(fabdb.StateStore)
+	if !ok {
+		fail(c, http.StatusNotImplemented, errors.New("world state is not supported by the database"))
+	}
+	return store, ok
+}
+
+// parseBlock parses optional ?block= param
+func parseBlock(c *gin.Context) (*uint64, error) {
+	v := c.Query("block")
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid block: %s", v)
+	}
+	return &n, nil
+}
+
+func state(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		store, ok := stateStore(c, db)
+		if !ok {
+			return
+		}
+
+		ch := c.Param("channel")
+		namespace, key := c.Query("namespace"), c.Query("key")
+		if ch == "" || namespace == "" || key == "" {
+			fail(c, http.StatusBadRequest, errors.New("channel ID, namespace and key must be specified"))
+			return
+		}
+
+		block, err := parseBlock(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+
+		var mod fabdb.KeyModification
+		if block == nil {
+			mod, err = store.GetState(c.Request.Context(), ch, namespace, key)
+		} else {
+			mod, err = store.GetStateAt(c.Request.Context(), ch, namespace, key, *block)
+		}
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respond(c, http.StatusOK, mod)
+	}
+}
+
+func statescan(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		store, ok := stateStore(c, db)
+		if !ok {
+			return
+		}
+
+		ch := c.Param("channel")
+		namespace := c.Query("namespace")
+		if ch == "" || namespace == "" {
+			fail(c, http.StatusBadRequest, errors.New("channel ID and namespace must be specified"))
+			return
+		}
+
+		page, err := parsePage(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+		block, err := parseBlock(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+
+		scan := fabdb.StateScan{
+			StartKey:  c.Query("start"),
+			EndKey:    c.Query("end"),
+			Prefix:    c.Query("prefix"),
+			AsOfBlock: block,
+			Page:      page,
+		}
+		// partial composite key is a prefix of composite keys
+		if objectType := c.Query("objecttype"); objectType != "" {
+			if scan.Prefix, err = helpers.CreateCompositeKey(objectType, c.QueryArray("attribute")); err != nil {
+				fail(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+		mods, next, err := store.ScanState(c.Request.Context(), ch, namespace, scan)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respondPage(c, mods, next)
+	}
+}
+
+// blockHashHeader is the response header with hex encoded header hash of the raw block
+const blockHashHeader = "X-Block-Hash"
+
+func rawblock(blocks fabdb.RawBlockStore) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if blocks == nil {
+			fail(c, http.StatusNotImplemented, errors.New("block archive is disabled"))
+			return
+		}
+
+		ch := c.Param("channel")
+		blocknum, err := parseBlocknum(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+
+		raw, err := blocks.GetRawBlock(c.Request.Context(), ch, blocknum)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		// damaged archive must not be served as a valid block
+		_, hash, err := archive.Verify(raw)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		c.Header(blockHashHeader, hex.EncodeToString(hash))
+		c.Data(http.StatusOK, "application/octet-stream", raw)
+	}
+}
+
+func healthcheck(health HealthReporter) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		sinks := []sink.Health{}
+		if health != nil {
+			sinks = health.Health()
+		}
+
+		status, code := "ok", http.StatusOK
+		for _, s := range sinks {
+			if !s.Healthy {
+				status, code = "degraded", http.StatusServiceUnavailable
+			}
+		}
+
+		respond(c, code, gin.H{"status": status, "sinks": sinks})
+	}
+}
+
+// live streams blocks with txs matching filters of query as server-sent "block" events with blocknum as event ID.
+// Streaming starts after Last-Event-ID of a reconnected client, from ?fromblock= or from the next stored block,
+// the stream is endless, so ?toblock= is rejected.
+func live(db fabdb.Storage, events *bus.Bus) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ch := c.Param("channel")
+		if c.Query("toblock") != "" {
+			fail(c, http.StatusBadRequest, errors.New("toblock is not supported by live streams"))
+			return
+		}
+		filter, err := parseFilter(c, fabdb.Page{})
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+
+		from := filter.FromBlock
+		if lastID := c.GetHeader("Last-Event-ID"); lastID != "" {
+			last, err := strconv.ParseUint(lastID, 10, 64)
+			if err != nil {
+				fail(c, http.StatusBadRequest, errors.Wrap(err, "invalid Last-Event-ID"))
+				return
+			}
+			from = last + 1
+		} else if c.Query("fromblock") == "" {
+			last, err := db.GetLastEntry(c.Request.Context(), ch)
+			switch {
+			case err == nil:
+				from = last.Blocknum + 1
+			case err.Error() != fabdb.NOT_FOUND_ERR:
+				failStorage(c, err)
+				return
+			}
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		err = helpers.Follow(c.Request.Context(), db, events, ch, filter, from, func(blocknum uint64, txs []fabdb.Tx) error {
+			blocks, err := helpers.PackTxsToBlocks(txs)
+			if err != nil {
+				return err
+			}
+			c.Render(-1, sse.Event{Id: strconv.FormatUint(blocknum, 10), Event: "block", Data: blocks[0]})
+			c.Writer.Flush()
+			return nil
 		})
+		if err != nil {
+			c.SSEvent("error", err.Error())
+		}
 	}
 }
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/bus"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is a server-sent event, data is a block
+type sseEvent struct {
+	id, event string
+	block     models.Block
+}
+
+// liveStream requests the live stream of mychannel, the stream is closed when the test ends
+func liveStream(t *testing.T, srv *httptest.Server, query, lastID string) (*http.Response, func() sseEvent) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/channels/mychannel/live"+query, nil)
+	require.NoError(t, err)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	reader := bufio.NewReader(resp.Body)
+	return resp, func() sseEvent {
+		var ev sseEvent
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return ev
+			}
+			field, value, _ := strings.Cut(line, ":")
+			switch field {
+			case "id":
+				ev.id = value
+			case "event":
+				ev.event = value
+			case "data":
+				require.NoError(t, json.Unmarshal([]byte(value), &ev.block))
+			}
+		}
+	}
+}
+
+func TestLive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := dbtest.New()
+	for blocknum := uint64(0); blocknum < 3; blocknum++ {
+		storage.Add("mychannel",
+			db.Tx{ChannelId: "mychannel", Txid: "fabcar", Blocknum: blocknum, Chaincode: "fabcar", Payload: []byte("[]")},
+			db.Tx{ChannelId: "mychannel", Txid: "marbles", Blocknum: blocknum, TxNum: 1, Chaincode: "marbles", Payload: []byte("[]")})
+	}
+	require.NoError(t, storage.SetCheckpoint(context.Background(), "mychannel", helpers.StoredCheckpoint, 2))
+	events := bus.New()
+	srv := httptest.NewServer(NewRouter(storage, []string{"mychannel"}, nil, nil, events, nil, nil, nil, false))
+	t.Cleanup(srv.Close)
+
+	// the stream is endless
+	resp, err := srv.Client().Get(srv.URL + "/api/v1/channels/mychannel/live?toblock=2")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// stored blocks are replayed from ?fromblock=, then new blocks are streamed
+	resp, next := liveStream(t, srv, "?fromblock=1&chaincode=fabcar", "")
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	for _, id := range []string{"1", "2"} {
+		ev := next()
+		assert.Equal(t, id, ev.id)
+		assert.Equal(t, "block", ev.event)
+		assert.Equal(t, "mychannel", ev.block.ChannelId)
+		require.Len(t, ev.block.Txs, 1)
+		assert.Equal(t, "fabcar", ev.block.Txs[0].Txid)
+	}
+
+	// blocks without matching txs are skipped
+	storage.Add("mychannel", db.Tx{ChannelId: "mychannel", Txid: "marbles", Blocknum: 3, Chaincode: "marbles", Payload: []byte("[]")})
+	require.NoError(t, events.Publish(context.Background(), bus.Event{Channel: "mychannel", Block: &blockhandler.CustomBlock{Number: 3}}))
+	storage.Add("mychannel", db.Tx{ChannelId: "mychannel", Txid: "fabcar", Blocknum: 4, Chaincode: "fabcar", Payload: []byte("[]")})
+	require.NoError(t, events.Publish(context.Background(), bus.Event{Channel: "mychannel", Block: &blockhandler.CustomBlock{Number: 4}}))
+	ev := next()
+	assert.Equal(t, "4", ev.id)
+	assert.Equal(t, uint64(4), ev.block.Blocknum)
+
+	// a reconnected client resumes after Last-Event-ID
+	_, next = liveStream(t, srv, "", "1")
+	assert.Equal(t, "2", next().id)
+	ev = next()
+	assert.Equal(t, "3", ev.id)
+	require.Len(t, ev.block.Txs, 1)
+	assert.Equal(t, "marbles", ev.block.Txs[0].Txid)
+}
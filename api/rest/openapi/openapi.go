@@ -0,0 +1,16 @@
+// Package openapi holds the OpenAPI 3 definition of the REST API
+package openapi
+
+import (
+	_ "embed"
+)
+
+// Spec is the OpenAPI 3 document in YAML
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// Docs is the page rendering the spec served from openapi.yaml next to it
+//
+//go:embed docs.html
+var Docs []byte
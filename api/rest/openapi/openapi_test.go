@@ -0,0 +1,158 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/api/rest"
+	"github.com/hyperledger-labs/fabex/api/rest/openapi"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/sink"
+	"github.com/hyperledger-labs/fabex/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorage keeps txs of blocks 0-2 of mychannel, history and state of a single key and the state checkpoint
+func newStorage(t *testing.T) *dbtest.Storage {
+	ctx := context.Background()
+	storage := dbtest.New()
+	for i := uint64(0); i < 3; i++ {
+		storage.Add("mychannel", db.Tx{ChannelId: "mychannel", Txid: "tx" + string(rune('0'+i)), Hash: "hash" + string(rune('0'+i)), Blocknum: i,
+			Payload: []byte(`[{"key":"CAR1","value":"eyJvd25lciI6IlRvbW9rbyJ9"}]`), Chaincode: "fabcar", Time: 1600000000})
+	}
+	mods := []db.KeyModification{{ChannelId: "mychannel", Namespace: "fabcar", Key: "CAR1", Blocknum: 1, Txid: "tx1", Value: []byte(`{"owner":"Tomoko"}`)}}
+	require.NoError(t, storage.InsertKeyHistory(ctx, "mychannel", mods))
+	require.NoError(t, storage.ApplyState(ctx, "mychannel", mods))
+	require.NoError(t, storage.SetCheckpoint(ctx, "mychannel", "state", 2))
+	return storage
+}
+
+type fakeHealth struct{}
+
+func (fakeHealth) Health() []sink.Health {
+	return []sink.Health{{Name: "kafka", Healthy: true, Blocks: map[string]uint64{"mychannel": 2}}}
+}
+
+func TestSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(ctx))
+	router, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+
+	storage := newStorage(t)
+	webhooks, err := webhook.NewDispatcher(storage, webhook.Options{})
+	require.NoError(t, err)
+	handler := rest.NewRouter(storage, []string{"mychannel"}, nil, fakeHealth{}, nil, webhooks, nil, nil, false)
+
+	var subID string
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+		// invalid requests are rejected by the spec too
+		invalid bool
+	}{
+		{method: "GET", path: "/api/v1/channels", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel", status: 200},
+		{method: "GET", path: "/api/v1/channels/otherchannel", status: 404},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks?limit=2", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks?from=1&order=desc", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks?limit=1000", status: 400, invalid: true},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks/1", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks/7", status: 404},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks/abc", status: 400, invalid: true},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks/1/raw", status: 501},
+		{method: "GET", path: "/api/v1/channels/mychannel/blocks/hash/hash2", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/txs?limit=10&order=desc", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/txs?order=random", status: 400, invalid: true},
+		{method: "GET", path: "/api/v1/channels/mychannel/txs/tx0", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/txs/unknown", status: 404},
+		{method: "GET", path: "/api/v1/channels/mychannel/payload/CAR", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/search?chaincode=fabcar&validationcode=0&fromblock=1", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/search?fromtime=yesterday", status: 400, invalid: true},
+		{method: "GET", path: "/api/v1/channels/mychannel/history?namespace=fabcar&key=CAR1", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/history?namespace=fabcar", status: 400, invalid: true},
+		{method: "GET", path: "/api/v1/channels/mychannel/history/composite?namespace=fabcar&objecttype=car&attribute=red", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/state?namespace=fabcar&key=CAR1&block=1", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/state?namespace=fabcar&key=CAR2", status: 404},
+		{method: "GET", path: "/api/v1/channels/mychannel/state/scan?namespace=fabcar&prefix=CAR", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/checkpoints/state", status: 200},
+		{method: "GET", path: "/api/v1/channels/mychannel/checkpoints/kafka", status: 404},
+		{method: "GET", path: "/api/v1/channels/mychannel/live?fromblock=abc", status: 400, invalid: true},
+		{method: "POST", path: "/api/v1/webhooks", body: `{"channel":"mychannel","url":"https://example.com/hook","filter":{"chaincode":"fabcar"}}`, status: 201},
+		{method: "POST", path: "/api/v1/webhooks", body: `{"channel":"mychannel","url":"example.com"}`, status: 400},
+		{method: "POST", path: "/api/v1/webhooks", body: `{"channel":"mychannel","url":"http://169.254.169.254/latest"}`, status: 400},
+		{method: "GET", path: "/api/v1/webhooks", status: 200},
+		{method: "GET", path: "/api/v1/webhooks/{id}", status: 200},
+		{method: "PUT", path: "/api/v1/webhooks/{id}", body: `{"channel":"mychannel","url":"https://example.com/other"}`, status: 200},
+		{method: "GET", path: "/api/v1/webhooks/{id}/deadletters", status: 200},
+		{method: "DELETE", path: "/api/v1/webhooks/{id}/deadletters/1", status: 200},
+		{method: "DELETE", path: "/api/v1/webhooks/{id}/deadletters/2", status: 404},
+		{method: "DELETE", path: "/api/v1/webhooks/{id}", status: 200},
+		{method: "GET", path: "/api/v1/webhooks/{id}", status: 404},
+		{method: "GET", path: "/api/v1/health", status: 200},
+		// legacy routes
+		{method: "GET", path: "/api/mychannel/bytxid/tx1", status: 200},
+		{method: "GET", path: "/api/mychannel/byblocknum/7", status: 404},
+		{method: "GET", path: "/api/mychannel/txs?limit=2", status: 200},
+		{method: "GET", path: "/api/mychannel/query?chaincode=fabcar", status: 200},
+		{method: "GET", path: "/api/mychannel/state?namespace=fabcar&key=CAR1", status: 200},
+		{method: "GET", path: "/api/webhooks", status: 200},
+		{method: "GET", path: "/api/health", status: 200},
+	} {
+		path := strings.ReplaceAll(tc.path, "{id}", subID)
+		t.Run(tc.method+" "+path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			route, params, err := router.FindRoute(req)
+			require.NoError(t, err)
+			input := &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route,
+				Options: &openapi3filter.Options{IncludeResponseStatus: true}}
+			if err = openapi3filter.ValidateRequest(ctx, input); tc.invalid {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			req = httptest.NewRequest(tc.method, path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, tc.status, w.Code, w.Body.String())
+
+			body := w.Body.Bytes()
+			assert.NoError(t, openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 w.Code,
+				Header:                 w.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(body)),
+				Options:                input.Options,
+			}))
+
+			if tc.method == http.MethodPost && w.Code == http.StatusCreated {
+				subs, err := storage.ListSubscriptions(ctx)
+				require.NoError(t, err)
+				subID = subs[0].ID
+				require.NoError(t, storage.AddDeadLetter(ctx, db.DeadLetter{SubscriptionID: subID, Channel: "mychannel", Blocknum: 2,
+					Payload: []byte("{}"), Error: "status 500", Attempts: 5}))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	fabdb "github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+)
+
+// v1Key marks requests of /api/v1 routes, their errors are objects instead of strings
+const v1Key = "fabex.v1"
+
+// APIError is the error of /api/v1 responses
+type APIError struct {
+	// Code is a stable machine-readable error code, e.g. "invalid_argument"
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorCodes are codes of APIError by HTTP status
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusNotFound:            "not_found",
+	http.StatusInternalServerError: "internal",
+	http.StatusNotImplemented:      "not_implemented",
+	http.StatusServiceUnavailable:  "unavailable",
+}
+
+// errNoData is returned when the storage has no txs for the request
+var errNoData = errors.New("no such data")
+
+func isV1(c *gin.Context) bool {
+	return c.GetBool(v1Key)
+}
+
+// fail responds with the error, /api/v1 errors are APIError objects and legacy errors are strings
+func fail(c *gin.Context, status int, err error) {
+	var body interface{} = err.Error()
+	if isV1(c) {
+		code, ok := errorCodes[status]
+		if !ok {
+			code = "unknown"
+		}
+		body = APIError{Code: code, Message: err.Error()}
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": body,
+		"msg":   nil,
+	})
+}
+
+// failStorage responds with 404 for NOT_FOUND_ERR and errNoData, 400 for badRequest and invalid cursors and 500 for
+// other errors
+func failStorage(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case err == errNoData || err.Error() == fabdb.NOT_FOUND_ERR:
+		status = http.StatusNotFound
+	case fabdb.IsInvalidCursor(err):
+		status = http.StatusBadRequest
+	default:
+		if _, ok := err.(badRequest); ok {
+			status = http.StatusBadRequest
+		}
+	}
+	fail(c, status, err)
+}
+
+// respond responds with the result, the error is null for /api/v1 and an empty string for legacy routes
+func respond(c *gin.Context, status int, msg interface{}) {
+	c.JSON(status, gin.H{
+		"error": noError(c),
+		"msg":   msg,
+	})
+}
+
+// respondPage responds with a page of results and a cursor of the next page
+func respondPage(c *gin.Context, msg interface{}, cursor string) {
+	c.JSON(http.StatusOK, gin.H{
+		"error":  noError(c),
+		"msg":    msg,
+		"cursor": cursor,
+	})
+}
+
+func noError(c *gin.Context) interface{} {
+	if isV1(c) {
+		return nil
+	}
+	return ""
+}
+
+// badRequest marks errors caused by invalid request parameters
+type badRequest struct {
+	error
+}
@@ -1,14 +1,66 @@
 package rest
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/bus"
 	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/sink"
 )
 
-func Run(db db.Storage, host, port string, withUI bool) error {
+// gatewayPrefixes are path prefixes of HTTP bindings of v1 and v2 gRPC services
+var gatewayPrefixes = []string{"/v1/", "/v2/"}
+
+// shutdownTimeout limits time for finishing active requests after ctx is done
+const shutdownTimeout = 5 * time.Second
+
+// HealthReporter reports states of sinks
+type HealthReporter interface {
+	Health() []sink.Health
+}
+
+// Webhooks checks webhook subscriptions and is notified about their changes
+type Webhooks interface {
+	// Validate checks the subscription before it's stored
+	Validate(sub db.Subscription) error
+	// Invalidate is called after subscriptions are changed
+	Invalidate()
+}
+
+// Run starts REST server and blocks until ctx is done or server fails, channels are channels explored by fabex,
+// blocks is nil if blocks aren't archived, health is nil if there are no sinks, events is nil if blocks aren't indexed
+// by this process, webhooks is nil if webhooks are disabled and their subscriptions aren't served, gateway is nil
+// if the JSON transcoding gateway of the gRPC service isn't served, graphQL is nil if GraphQL queries aren't served
+func Run(ctx context.Context, db db.Storage, channels []string, blocks db.RawBlockStore, health HealthReporter, events *bus.Bus, webhooks Webhooks,
+	gateway, graphQL http.Handler, host, port string, withUI bool) error {
+	srv := &http.Server{
+		Addr:        net.JoinHostPort(host, port),
+		Handler:     NewRouter(db, channels, blocks, health, events, webhooks, gateway, graphQL, withUI),
+		BaseContext: func(_ net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// NewRouter creates the handler of all routes, arguments are the same as of Run
+func NewRouter(db db.Storage, channels []string, blocks db.RawBlockStore, health HealthReporter, events *bus.Bus, webhooks Webhooks,
+	gateway, graphQL http.Handler, withUI bool) *gin.Engine {
 	r := gin.Default()
 
 	if withUI {
@@ -19,9 +71,58 @@ func Run(db db.Storage, host, port string, withUI bool) error {
 		c.Redirect(http.StatusMovedPermanently, "/ui")
 	})
 
+	registerV1(r, db, channels, blocks, health, events, webhooks)
+
+	// HTTP bindings of gRPC services
+	if gateway != nil {
+		for _, prefix := range gatewayPrefixes {
+			r.Any(prefix+"*path", gin.WrapH(gateway))
+		}
+	}
+
+	// GraphQL queries as POST body or GET parameters
+	if graphQL != nil {
+		r.GET("/graphql", gin.WrapH(graphQL))
+		r.POST("/graphql", gin.WrapH(graphQL))
+	}
+
+	// routes before /api/v1, kept for compatibility
 	r.GET("/api/:channel/bytxid/:txid", bytxid(db))
 
 	r.GET("/api/:channel/byblocknum/:blocknum", byblocknum(db))
 
-	return r.Run(net.JoinHostPort(host, port))
+	// paginated queries, use ?limit=&cursor=&order=asc|desc
+	r.GET("/api/:channel/txs", txs(db))
+
+	r.GET("/api/:channel/bypayload/:payload", bypayload(db))
+
+	// compound query, see parseFilter for supported filters
+	r.GET("/api/:channel/query", query(db))
+
+	// versions of the world state key, use ?namespace=&key=
+	r.GET("/api/:channel/history", keyhistory(db))
+
+	// versions of composite keys, use ?namespace=&objecttype=&attribute=&attribute= (leading attributes are optional)
+	r.GET("/api/:channel/history/composite", compositekeyhistory(db))
+
+	// world state, use ?namespace=&key=&block= (block is optional)
+	r.GET("/api/:channel/state", state(db))
+
+	// range of world state keys, use ?namespace=&start=&end=&prefix=&block= and paging params,
+	// ?objecttype=&attribute= select keys by partial composite key
+	r.GET("/api/:channel/state/scan", statescan(db))
+
+	// server-sent events with new blocks, filters are the same as of query, use ?fromblock= to replay stored blocks
+	r.GET("/api/:channel/live", live(db, events))
+
+	// original marshaled common.Block, the header hash is returned in X-Block-Hash header
+	r.GET("/api/:channel/rawblock/:blocknum", rawblock(blocks))
+
+	// webhook subscriptions, secrets are returned only on creation, the routes are served if webhooks are enabled
+	registerWebhooks(r.Group("/api/webhooks"), db, webhooks)
+
+	// states of sinks, 503 if some sink fails
+	r.GET("/api/health", healthcheck(health))
+
+	return r
 }
@@ -17,173 +17,128 @@
 package rest
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
 	"github.com/hyperledger-labs/fabex/models"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"os"
-	"os/exec"
+	"net/http/httptest"
 	"testing"
-	"time"
 )
 
 type Response struct {
-	Error string         `json:"error"`
-	Msg   []models.Block `json:"msg"`
+	Error string       `json:"error"`
+	Msg   models.Block `json:"msg"`
 }
 
-func ExecuteCMD(command string, args ...string) (*exec.Cmd, error) {
-	cmd := exec.Command(command, args...)
-	cmd.Dir = "../"
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	go func(cmd *exec.Cmd) {
-		err := cmd.Run()
-		if err != nil {
-			log.Fatalf(err.Error() + ": " + stderr.String())
-		}
-	}(cmd)
-	return cmd, nil
-}
-
-func TestMain(m *testing.M) {
-
-	// start MongoDB
-	_, err := ExecuteCMD("make", "mongo-test")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// start test network
-	log.Println("Test setup")
-	_, err = ExecuteCMD("make", "fabric-test")
-	if err != nil {
-		log.Fatal(err)
-	}
-	// wait until containers start, channel and chaincode created
-	time.Sleep(135 * time.Second)
-
-	log.Println("Start Fabex")
-	cancelCh := make(chan bool)
-
-	go func(cancelCh chan bool) {
-
-		cmd, err := ExecuteCMD("make", "fabex-test-integration")
-		if err != nil {
-			log.Fatal(err)
-		}
-		<-cancelCh
-
-		if cmd != nil && cmd.Process != nil {
-			if err := cmd.Process.Kill(); err != nil {
-				log.Fatal("failed to kill Fabex process: ", err)
-			}
-		}
-	}(cancelCh)
-	time.Sleep(55 * time.Second)
+// fabexURL is the address of Fabex run by integration tests, TestEndpoints serves the router if it's empty
+var fabexURL string
 
-	log.Println("Run tests")
-	code := m.Run()
-	cancelCh <- true
-	log.Println("Clean test artifacts")
-
-	// purge
-	_, err = ExecuteCMD("make", "stop-fabric-test")
-	_, err = ExecuteCMD("make", "stop-mongo-test")
-	if err != nil {
-		log.Fatal(err)
+func TestEndpoints(t *testing.T) {
+	url := fabexURL
+	if url == "" {
+		gin.SetMode(gin.TestMode)
+		storage := dbtest.New()
+		storage.Add("mychannel", db.Tx{ChannelId: "mychannel", Txid: "tx1", Blocknum: 1, Hash: "hash1", Payload: []byte("[]")})
+		server := httptest.NewServer(NewRouter(storage, []string{"mychannel"}, nil, nil, nil, nil, nil, nil, false))
+		defer server.Close()
+		url = server.URL
 	}
 
-	//wait for containers to be removed
-	time.Sleep(10 * time.Second)
-
-	os.Exit(code)
-}
-
-func TestEndpoints(t *testing.T) {
 	t.Run("byblocknum", func(t *testing.T) {
-		resp, err := http.Get("http://localhost:5252/byblocknum/1")
+		resp, err := http.Get(url + "/api/mychannel/byblocknum/1")
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		var txs Response
-		err = json.Unmarshal(bodyBytes, &txs)
+		var block Response
+		err = json.Unmarshal(bodyBytes, &block)
 		if err != nil {
-			t.Errorf(err.Error())
-		}
-		assert.Greater(t, len(txs.Msg), 0, "No transactions found")
-		for _, tx := range txs.Msg {
-			assert.EqualValuesf(t, tx.Blocknum, 1, "Not valid tx retrieved, got %d, want %d", tx.Blocknum, 1)
+			t.Fatal(err)
 		}
+		assert.Greater(t, len(block.Msg.Txs), 0, "No transactions found")
+		assert.EqualValuesf(t, 1, block.Msg.Blocknum, "Not valid block retrieved, got %d, want %d", block.Msg.Blocknum, 1)
 	})
 
 	t.Run("bytxid", func(t *testing.T) {
-		var TXID string
 		// get tx ID
-		respbyblocknum, err := http.Get("http://localhost:5252/byblocknum/1")
+		respbyblocknum, err := http.Get(url + "/api/mychannel/byblocknum/1")
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
 		bodyBytesByBlocknum, err := ioutil.ReadAll(respbyblocknum.Body)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		var txsByBlocknum Response
-		err = json.Unmarshal(bodyBytesByBlocknum, &txsByBlocknum)
+		var blockByBlocknum Response
+		err = json.Unmarshal(bodyBytesByBlocknum, &blockByBlocknum)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		assert.Greater(t, len(txsByBlocknum.Msg), 0, "No transactions found")
-		for _, tx := range txsByBlocknum.Msg {
-			assert.EqualValuesf(t, tx.Blocknum, 1, "Not valid tx retrieved, got %d, want %d", tx.Blocknum, 1)
-			TXID = tx.Txs[0].Txid
+		if !assert.Greater(t, len(blockByBlocknum.Msg.Txs), 0, "No transactions found") {
+			return
 		}
+		TXID := blockByBlocknum.Msg.Txs[0].Txid
 
 		// check tx with this ID
-		resp, err := http.Get(fmt.Sprintf("http://localhost:5252/bytxid/%s", TXID))
+		resp, err := http.Get(fmt.Sprintf("%s/api/mychannel/bytxid/%s", url, TXID))
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		var txs Response
-		err = json.Unmarshal(bodyBytes, &txs)
+		var block Response
+		err = json.Unmarshal(bodyBytes, &block)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		assert.Greater(t, len(txs.Msg), 0, "No transactions found")
-		for _, tx := range txs.Msg {
-			assert.EqualValuesf(t, tx.Txs[0].Txid, TXID, "Not valid tx retrieved, got tx ID %d, want %d", tx.Txs[0].Txid, TXID)
+		if assert.Greater(t, len(block.Msg.Txs), 0, "No transactions found") {
+			assert.Equalf(t, TXID, block.Msg.Txs[0].Txid, "Not valid tx retrieved, got tx ID %s, want %s", block.Msg.Txs[0].Txid, TXID)
 		}
 	})
 
 	t.Run("InvalidBlockNumber", func(t *testing.T) {
-		resp, err := http.Get("http://localhost:5252/byblocknum/999999999999")
+		resp, err := http.Get(url + "/api/mychannel/byblocknum/999999999999")
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
 
-		var txs Response
-		err = json.Unmarshal(bodyBytes, &txs)
+		var block Response
+		err = json.Unmarshal(bodyBytes, &block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "no such data", block.Error, "failed to handle invalid block number")
+	})
+
+	t.Run("V1InvalidBlockNumber", func(t *testing.T) {
+		resp, err := http.Get(url + "/api/v1/channels/mychannel/blocks/abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Error APIError `json:"error"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
 		if err != nil {
-			t.Errorf(err.Error())
+			t.Fatal(err)
 		}
-		assert.Equal(t, "no such data", txs.Error, "failed to handle invalid block number")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "invalid_argument", body.Error.Code)
 	})
 }
@@ -0,0 +1,252 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/api/rest/openapi"
+	"github.com/hyperledger-labs/fabex/bus"
+	fabdb "github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultBlockRange is the number of blocks of a block range page by default
+	defaultBlockRange = 10
+	// maxBlockRange limits the number of blocks of a block range page
+	maxBlockRange = 100
+)
+
+// ChainInfo describes the stored part of the channel ledger
+type ChainInfo struct {
+	Channel string `json:"channelid"`
+	// Height is the number of the last stored block + 1, 0 if there are no blocks
+	Height            uint64 `json:"height"`
+	CurrentBlockHash  string `json:"currentblockhash"`
+	PreviousBlockHash string `json:"previousblockhash"`
+}
+
+// registerV1 adds /api/v1 routes, channels are channels explored by fabex, other channels aren't served if it's not empty
+func registerV1(r *gin.Engine, db fabdb.Storage, channels []string, blocks fabdb.RawBlockStore, health HealthReporter, events *bus.Bus,
+	webhooks Webhooks) {
+	v1 := r.Group("/api/v1", func(c *gin.Context) {
+		c.Set(v1Key, true)
+	})
+
+	// channels with their heights
+	v1.GET("/channels", channellist(db, channels))
+
+	ch := v1.Group("/channels/:channel", knownChannel(channels))
+	ch.GET("", chaininfo(db))
+
+	// pages of blocks, use ?from=&to=&limit=&cursor=&order=asc|desc, limit is the number of blocks
+	ch.GET("/blocks", blockrange(db))
+	ch.GET("/blocks/:blocknum", byblocknum(db))
+	ch.GET("/blocks/:blocknum/raw", rawblock(blocks))
+	ch.GET("/blocks/hash/:hash", byhash(db))
+
+	// pages of txs packed to blocks, use ?limit=&cursor=&order=asc|desc
+	ch.GET("/txs", txs(db))
+	ch.GET("/txs/:txid", bytxid(db))
+	ch.GET("/payload/:payload", bypayload(db))
+
+	// compound query, see parseFilter for supported filters
+	ch.GET("/search", query(db))
+
+	ch.GET("/history", keyhistory(db))
+	ch.GET("/history/composite", compositekeyhistory(db))
+	ch.GET("/state", state(db))
+	ch.GET("/state/scan", statescan(db))
+	ch.GET("/live", live(db, events))
+
+	// last blocks processed by consumers, e.g. /checkpoints/fanout/kafka
+	ch.GET("/checkpoints/*consumer", checkpoint(db))
+
+	registerWebhooks(v1.Group("/webhooks"), db, webhooks)
+
+	v1.GET("/health", healthcheck(health))
+
+	// OpenAPI definition of /api/v1 and its rendered docs
+	v1.GET("/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", openapi.Spec)
+	})
+	v1.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.Docs)
+	})
+}
+
+// knownChannel responds with 404 for channels which aren't explored
+func knownChannel(channels []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(channels) == 0 {
+			return
+		}
+		for _, ch := range channels {
+			if ch == c.Param("channel") {
+				return
+			}
+		}
+		fail(c, http.StatusNotFound, errors.Errorf("unknown channel: %s", c.Param("channel")))
+	}
+}
+
+// getChainInfo returns info of the last stored block of the channel
+func getChainInfo(c *gin.Context, db fabdb.Storage, ch string) (ChainInfo, error) {
+	info := ChainInfo{Channel: ch}
+	last, err := db.GetLastEntry(c.Request.Context(), ch)
+	if err != nil {
+		if err.Error() == fabdb.NOT_FOUND_ERR {
+			return info, nil
+		}
+		return info, err
+	}
+	info.Height = last.Blocknum + 1
+	info.CurrentBlockHash, info.PreviousBlockHash = last.Hash, last.PreviousHash
+	return info, nil
+}
+
+func channellist(db fabdb.Storage, channels []string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		infos := make([]ChainInfo, 0, len(channels))
+		for _, ch := range channels {
+			info, err := getChainInfo(c, db, ch)
+			if err != nil {
+				failStorage(c, err)
+				return
+			}
+			infos = append(infos, info)
+		}
+
+		respond(c, http.StatusOK, infos)
+	}
+}
+
+func chaininfo(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		info, err := getChainInfo(c, db, c.Param("channel"))
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respond(c, http.StatusOK, info)
+	}
+}
+
+// blockrange responds with complete blocks of the range, the cursor is the number of the next block of the range.
+// The range ends with the last stored block by default.
+func blockrange(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ch := c.Param("channel")
+		page, err := parsePage(c)
+		if err != nil {
+			fail(c, http.StatusBadRequest, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultBlockRange
+		}
+		if page.Limit > maxBlockRange {
+			fail(c, http.StatusBadRequest, errors.Errorf("limit exceeds %d blocks", maxBlockRange))
+			return
+		}
+
+		var from, to uint64
+		for param, dest := range map[string]*uint64{"from": &from, "to": &to} {
+			if v := c.Query(param); v != "" {
+				if *dest, err = strconv.ParseUint(v, 10, 64); err != nil {
+					fail(c, http.StatusBadRequest, errors.Errorf("invalid %s: %s", param, v))
+					return
+				}
+			}
+		}
+		if c.Query("to") == "" {
+			info, err := getChainInfo(c, db, ch)
+			if err != nil {
+				failStorage(c, err)
+				return
+			}
+			if info.Height == 0 {
+				respondPage(c, []models.Block{}, "")
+				return
+			}
+			to = info.Height - 1
+		}
+		if page.Cursor != "" {
+			next, err := strconv.ParseUint(page.Cursor, 10, 64)
+			if err != nil {
+				fail(c, http.StatusBadRequest, errors.Errorf("invalid cursor: %s", page.Cursor))
+				return
+			}
+			if page.Order == fabdb.Descending {
+				to = next
+			} else {
+				from = next
+			}
+		}
+		if from > to {
+			respondPage(c, []models.Block{}, "")
+			return
+		}
+
+		// window of the page and the first block of the next page
+		first, last, cursor := from, to, ""
+		limit := uint64(page.Limit)
+		if page.Order == fabdb.Descending {
+			if to-from >= limit {
+				first = to - limit + 1
+				cursor = strconv.FormatUint(first-1, 10)
+			}
+		} else if to-from >= limit {
+			last = from + limit - 1
+			cursor = strconv.FormatUint(last+1, 10)
+		}
+
+		blocks := []models.Block{}
+		err = helpers.QueryBlocks(c.Request.Context(), db, ch, fabdb.Filter{}, first, last, func(_ uint64, txs []fabdb.Tx) error {
+			packed, err := helpers.PackTxsToBlocks(txs)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, packed...)
+			return nil
+		})
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+		if page.Order == fabdb.Descending {
+			for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+				blocks[i], blocks[j] = blocks[j], blocks[i]
+			}
+		}
+
+		respondPage(c, blocks, cursor)
+	}
+}
+
+func checkpoint(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ch, consumer := c.Param("channel"), strings.TrimPrefix(c.Param("consumer"), "/")
+		if consumer == "" {
+			fail(c, http.StatusBadRequest, errors.New("no consumer specified"))
+			return
+		}
+
+		blocknum, ok, err := db.GetCheckpoint(c.Request.Context(), ch, consumer)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+		if !ok {
+			fail(c, http.StatusNotFound, errors.Errorf("no blocks processed by %s", consumer))
+			return
+		}
+
+		respond(c, http.StatusOK, gin.H{"consumer": consumer, "blocknum": blocknum})
+	}
+}
@@ -0,0 +1,176 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v1Response is the body of /api/v1 responses
+type v1Response struct {
+	Error  *APIError       `json:"error"`
+	Msg    json.RawMessage `json:"msg"`
+	Cursor string          `json:"cursor"`
+}
+
+// newV1Router serves blocks 0-24 of mychannel, emptychannel has no blocks
+func newV1Router(t *testing.T) (*gin.Engine, *dbtest.Storage) {
+	gin.SetMode(gin.TestMode)
+	storage := dbtest.New()
+	for blocknum := uint64(0); blocknum < 25; blocknum++ {
+		storage.Add("mychannel", db.Tx{ChannelId: "mychannel", Txid: fmt.Sprintf("tx%d", blocknum), Blocknum: blocknum,
+			Hash: fmt.Sprintf("hash%d", blocknum), PreviousHash: fmt.Sprintf("hash%d", int(blocknum)-1), Payload: []byte("[]")})
+	}
+	return NewRouter(storage, []string{"mychannel", "emptychannel"}, nil, nil, nil, nil, nil, nil, false), storage
+}
+
+// getV1 requests the path and decodes msg of the response into msg
+func getV1(t *testing.T, router http.Handler, path string, msg interface{}) (int, v1Response) {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var resp v1Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	if resp.Error == nil && msg != nil {
+		require.NoError(t, json.Unmarshal(resp.Msg, msg))
+	}
+	return rec.Code, resp
+}
+
+func TestChannels(t *testing.T) {
+	router, _ := newV1Router(t)
+
+	var infos []ChainInfo
+	code, _ := getV1(t, router, "/api/v1/channels", &infos)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []ChainInfo{
+		{Channel: "mychannel", Height: 25, CurrentBlockHash: "hash24", PreviousBlockHash: "hash23"},
+		{Channel: "emptychannel"},
+	}, infos)
+
+	var info ChainInfo
+	code, _ = getV1(t, router, "/api/v1/channels/mychannel", &info)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint64(25), info.Height)
+
+	// channels which aren't explored are unknown
+	code, resp := getV1(t, router, "/api/v1/channels/otherchannel", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "not_found", resp.Error.Code)
+	code, _ = getV1(t, router, "/api/v1/channels/otherchannel/blocks", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestBlockRange(t *testing.T) {
+	router, _ := newV1Router(t)
+
+	for _, tc := range []struct {
+		name, query string
+		status      int
+		// first and last are block numbers of the page
+		first, last int
+		cursor      string
+	}{
+		{name: "last block by default", query: "", status: 200, first: 0, last: 9, cursor: "10"},
+		{name: "next page", query: "?cursor=10", status: 200, first: 10, last: 19, cursor: "20"},
+		{name: "last page", query: "?cursor=20", status: 200, first: 20, last: 24},
+		{name: "descending", query: "?order=desc", status: 200, first: 24, last: 15, cursor: "14"},
+		{name: "next descending page", query: "?order=desc&cursor=14", status: 200, first: 14, last: 5, cursor: "4"},
+		{name: "last descending page", query: "?order=desc&cursor=4", status: 200, first: 4, last: 0},
+		{name: "from and to", query: "?from=5&to=7", status: 200, first: 5, last: 7},
+		{name: "descending from", query: "?from=20&order=desc&limit=3", status: 200, first: 24, last: 22, cursor: "21"},
+		{name: "from after to", query: "?from=7&to=5", status: 200, first: -1},
+		{name: "max range", query: "?limit=100", status: 200, first: 0, last: 24},
+		{name: "range over max", query: "?limit=101", status: 400},
+		{name: "invalid from", query: "?from=abc", status: 400},
+		{name: "invalid cursor", query: "?cursor=abc", status: 400},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var blocks []models.Block
+			code, resp := getV1(t, router, "/api/v1/channels/mychannel/blocks"+tc.query, &blocks)
+			require.Equal(t, tc.status, code)
+			if tc.status != http.StatusOK {
+				require.NotNil(t, resp.Error)
+				assert.Equal(t, "invalid_argument", resp.Error.Code)
+				return
+			}
+
+			var expected []uint64
+			for num := tc.first; tc.first >= 0; {
+				expected = append(expected, uint64(num))
+				if num == tc.last {
+					break
+				}
+				if tc.first < tc.last {
+					num++
+				} else {
+					num--
+				}
+			}
+			var nums []uint64
+			for _, block := range blocks {
+				nums = append(nums, block.Blocknum)
+			}
+			assert.Equal(t, expected, nums)
+			assert.Equal(t, tc.cursor, resp.Cursor)
+		})
+	}
+
+	// the range of a channel without blocks is empty
+	var blocks []models.Block
+	code, resp := getV1(t, router, "/api/v1/channels/emptychannel/blocks", &blocks)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, blocks)
+	assert.Empty(t, resp.Cursor)
+}
+
+func TestCheckpoint(t *testing.T) {
+	router, storage := newV1Router(t)
+	require.NoError(t, storage.SetCheckpoint(context.Background(), "mychannel", "fanout/kafka", 7))
+
+	var checkpoint struct {
+		Consumer string `json:"consumer"`
+		Blocknum uint64 `json:"blocknum"`
+	}
+	code, _ := getV1(t, router, "/api/v1/channels/mychannel/checkpoints/fanout/kafka", &checkpoint)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "fanout/kafka", checkpoint.Consumer)
+	assert.Equal(t, uint64(7), checkpoint.Blocknum)
+
+	code, _ = getV1(t, router, "/api/v1/channels/mychannel/checkpoints/s3", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = getV1(t, router, "/api/v1/channels/mychannel/checkpoints/", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestStorageErrors(t *testing.T) {
+	router, _ := newV1Router(t)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{path: "/txs?cursor=abc", status: 400},
+		{path: "/history?namespace=fabcar&key=CAR1&cursor=abc", status: 400},
+		{path: "/history/composite?namespace=fabcar&objecttype=car&cursor=abc", status: 400},
+		{path: "/state/scan?namespace=fabcar&cursor=abc", status: 400},
+		{path: "/state?namespace=fabcar&key=CAR1", status: 404},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			code, resp := getV1(t, router, "/api/v1/channels/mychannel"+tc.path, nil)
+			assert.Equal(t, tc.status, code)
+			assert.NotNil(t, resp.Error)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	fabdb "github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/webhook"
+	"github.com/pkg/errors"
+)
+
+// registerWebhooks adds routes of subscriptions and their dead letters if webhooks are enabled
+func registerWebhooks(r *gin.RouterGroup, db fabdb.Storage, webhooks Webhooks) {
+	if webhooks == nil {
+		return
+	}
+
+	r.GET("", subscriptions(db))
+	r.POST("", createsubscription(db, webhooks))
+	r.GET("/:id", subscription(db))
+	r.PUT("/:id", updatesubscription(db, webhooks))
+	r.DELETE("/:id", deletesubscription(db, webhooks))
+
+	// deliveries failed after all attempts
+	r.GET("/:id/deadletters", deadletters(db))
+	r.DELETE("/:id/deadletters/:letter", deletedeadletter(db))
+}
+
+// bindSubscription reads the subscription from the request body or responds with 400
+func bindSubscription(c *gin.Context, webhooks Webhooks) (fabdb.Subscription, bool) {
+	var sub fabdb.Subscription
+	if err := c.ShouldBindJSON(&sub); err != nil {
+		fail(c, http.StatusBadRequest, errors.Wrap(err, "invalid subscription"))
+		return sub, false
+	}
+	if err := webhooks.Validate(sub); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return sub, false
+	}
+	return sub, true
+}
+
+// createsubscription stores the subscription, its secret is generated if it's empty and returned only once
+func createsubscription(db fabdb.Storage, webhooks Webhooks) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		sub, ok := bindSubscription(c, webhooks)
+		if !ok {
+			return
+		}
+		sub.ID = webhook.NewID()
+		sub.Created = time.Now().Unix()
+		if sub.Secret == "" {
+			sub.Secret = webhook.NewSecret()
+		}
+
+		if err := db.PutSubscription(c.Request.Context(), sub); err != nil {
+			failStorage(c, err)
+			return
+		}
+		webhooks.Invalidate()
+
+		respond(c, http.StatusCreated, sub)
+	}
+}
+
+func subscriptions(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		subs, err := db.ListSubscriptions(c.Request.Context())
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+		for i := range subs {
+			subs[i].Secret = ""
+		}
+
+		respond(c, http.StatusOK, subs)
+	}
+}
+
+func subscription(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		sub, err := db.GetSubscription(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+		sub.Secret = ""
+
+		respond(c, http.StatusOK, sub)
+	}
+}
+
+// updatesubscription replaces the subscription, the secret is kept if it's empty
+func updatesubscription(db fabdb.Storage, webhooks Webhooks) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		old, err := db.GetSubscription(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+		sub, ok := bindSubscription(c, webhooks)
+		if !ok {
+			return
+		}
+		sub.ID, sub.Created = old.ID, old.Created
+		if sub.Secret == "" {
+			sub.Secret = old.Secret
+		}
+
+		if err = db.PutSubscription(c.Request.Context(), sub); err != nil {
+			failStorage(c, err)
+			return
+		}
+		webhooks.Invalidate()
+		sub.Secret = ""
+
+		respond(c, http.StatusOK, sub)
+	}
+}
+
+func deletesubscription(db fabdb.Storage, webhooks Webhooks) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if err := db.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+			failStorage(c, err)
+			return
+		}
+		webhooks.Invalidate()
+
+		respond(c, http.StatusOK, nil)
+	}
+}
+
+func deadletters(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if _, err := db.GetSubscription(c.Request.Context(), id); err != nil {
+			failStorage(c, err)
+			return
+		}
+		letters, err := db.ListDeadLetters(c.Request.Context(), id)
+		if err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respond(c, http.StatusOK, letters)
+	}
+}
+
+func deletedeadletter(db fabdb.Storage) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if err := db.DeleteDeadLetter(c.Request.Context(), c.Param("id"), c.Param("letter")); err != nil {
+			failStorage(c, err)
+			return
+		}
+
+		respond(c, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,53 @@
+// Package archive keeps original blocks of channels, so they can be served to clients and parsed again
+package archive
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	fabcommon "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// Processor stores marshaled blocks into the archive
+type Processor struct {
+	archive db.RawBlockArchive
+}
+
+func NewProcessor(archive db.RawBlockArchive) *Processor {
+	return &Processor{archive: archive}
+}
+
+func (p *Processor) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	if block.Block == nil {
+		return errors.Errorf("block %d has no original block", block.Number)
+	}
+
+	raw, err := proto.Marshal(block.Block)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal block %d", block.Number)
+	}
+
+	return errors.Wrapf(p.archive.PutRawBlock(ctx, ch, block.Number, raw), "failed to archive block %d", block.Number)
+}
+
+// Verify unmarshals the block and checks that its data matches the data hash of the header. It returns
+// the header hash, which equals the previous hash of the next block.
+func Verify(raw []byte) (*fabcommon.Block, []byte, error) {
+	block, err := protoutil.UnmarshalBlock(raw)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal block")
+	}
+	if block.Header == nil || block.Data == nil {
+		return nil, nil, errors.New("block has no header or data")
+	}
+	if !bytes.Equal(protoutil.BlockDataHash(block.Data), block.Header.DataHash) {
+		return nil, nil, errors.Errorf("data hash of block %d doesn't match its header", block.Header.Number)
+	}
+
+	return block, protoutil.BlockHeaderHash(block.Header), nil
+}
@@ -0,0 +1,42 @@
+package archive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.NewFSArchive(t.TempDir())
+	require.NoError(t, err)
+
+	block := protoutil.NewBlock(7, []byte("previous"))
+	block.Data.Data = [][]byte{[]byte("tx1"), []byte("tx2")}
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+
+	require.NoError(t, NewProcessor(store).ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 7, Block: block}))
+
+	raw, err := store.GetRawBlock(ctx, "mychannel", 7)
+	require.NoError(t, err)
+	restored, hash, err := Verify(raw)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(block, restored))
+	assert.Equal(t, protoutil.BlockHeaderHash(block.Header), hash)
+
+	_, err = store.GetRawBlock(ctx, "mychannel", 8)
+	require.Error(t, err)
+	assert.Equal(t, db.NOT_FOUND_ERR, err.Error())
+
+	block.Data.Data[1] = []byte("forged")
+	raw, err = proto.Marshal(block)
+	require.NoError(t, err)
+	_, _, err = Verify(raw)
+	assert.Error(t, err)
+}
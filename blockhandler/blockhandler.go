@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger-labs/fabex/models"
 	fabcommon "github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -45,12 +46,52 @@ type LedgerClient interface {
 
 // CustomBlock stores slice of transactions (with block data)
 type CustomBlock struct {
-	Txs []db.Tx
+	Number uint64
+	Txs    []db.Tx
+	// KeyHistory stores world state keys modified by valid txs of the block
+	KeyHistory []db.KeyModification
+	// Events are chaincode events of txs, including invalid ones
+	Events []db.ChaincodeEvent
+	// Reads are keys read by txs, including invalid ones, they aren't stored
+	Reads []KeyRead
+	// Block is the original block, e.g. for archiving
+	Block *fabcommon.Block
 }
 
-// GetBlock gets information about specified block with blocknum number
-func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
-	customBlock := &CustomBlock{}
+// KeyRead is a world state key read by the tx, Version is nil if the key didn't exist
+type KeyRead struct {
+	TxNum     uint64
+	Namespace string
+	Key       string
+	Version   *kvrwset.Version
+}
+
+// TxNum returns the position of the tx with the txid in the block. Storages may not keep positions of txs,
+// so txNum of the stored tx is used only if several txs of the block have the txid.
+func (b *CustomBlock) TxNum(txid string, txNum uint64) uint64 {
+	found, seen := uint64(0), false
+	for _, tx := range b.Txs {
+		if tx.Txid != txid {
+			continue
+		}
+		// a tx writing to several chaincodes is decoded into several txs with the same position
+		if seen && tx.TxNum != found {
+			return txNum
+		}
+		found, seen = tx.TxNum, true
+	}
+	if !seen {
+		return txNum
+	}
+	return found
+}
+
+// GetBlock gets information about specified block with blocknum number. Write values are decoded into documents
+// by decoders, JSON objects are always decoded.
+func HandleBlock(block *fabcommon.Block, decoders ...ValueDecoder) (*CustomBlock, error) {
+	decoder := append(append(Decoders{}, decoders...), JSONDecoder{})
+
+	customBlock := &CustomBlock{Number: block.Header.Number, Block: block}
 
 	// get block hash
 	hash := hex.EncodeToString(block.Header.DataHash)
@@ -59,7 +100,7 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 	previoushash := hex.EncodeToString(block.Header.PreviousHash)
 
 	rawdata := block.GetData()
-	for _, value := range rawdata.Data {
+	for txNum, value := range rawdata.Data {
 
 		// get validation code (0 is valid)
 		processedtx := &peer.ProcessedTransaction{}
@@ -86,6 +127,12 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 			return nil, err
 		}
 
+		creatorMSP, err := creatorMSPFromEnvelope(envelope)
+		if err != nil {
+			return nil, err
+		}
+		txType := fabcommon.HeaderType(channelHeader.Type).String()
+
 		// get RW sets
 		action, _ := protoutil.GetActionFromEnvelopeMsg(envelope)
 		actionResults := action.GetResults()
@@ -114,6 +161,27 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 			return nil, err
 		}
 
+		// get chaincode event
+		if len(action.GetEvents()) != 0 {
+			event := &peer.ChaincodeEvent{}
+			if err = proto.Unmarshal(action.GetEvents(), event); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal chaincode event")
+			}
+			if event.EventName != "" {
+				customBlock.Events = append(customBlock.Events, db.ChaincodeEvent{
+					ChannelId:      channelHeader.ChannelId,
+					Txid:           TxId,
+					Blocknum:       block.Header.Number,
+					TxNum:          uint64(txNum),
+					Chaincode:      event.ChaincodeId,
+					Name:           event.EventName,
+					Payload:        event.Payload,
+					ValidationCode: validationCode,
+					Time:           txtime.Unix(),
+				})
+			}
+		}
+
 		if protoutil.IsConfigBlock(block) {
 			// cast "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common".Block to
 			// "github.com/hyperledger/fabric/fabric-protos-go/common".Block
@@ -198,24 +266,57 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 			}
 
 			tx := db.Tx{
-				channelHeader.ChannelId,
-				TxId,
-				hash,
-				previoushash,
-				block.Header.Number,
-				jsonPayload,
-				validationCode,
-				txtime.Unix(),
+				ChannelId:      channelHeader.ChannelId,
+				Txid:           TxId,
+				Hash:           hash,
+				PreviousHash:   previoushash,
+				Blocknum:       block.Header.Number,
+				TxNum:          uint64(txNum),
+				Payload:        jsonPayload,
+				ValidationCode: validationCode,
+				Time:           txtime.Unix(),
+				CreatorMSP:     creatorMSP,
+				TxType:         txType,
+				Keys:           writeSetKeys(writeSet),
 			}
 			customBlock.Txs = append(customBlock.Txs, tx)
 
 		}
 		for _, nsRwSet := range txRWSet.NsRwSets {
+			for _, read := range nsRwSet.KvRwSet.Reads {
+				customBlock.Reads = append(customBlock.Reads, KeyRead{TxNum: uint64(txNum), Namespace: nsRwSet.NameSpace, Key: read.Key,
+					Version: read.Version})
+			}
+
 			// get only those txs that changes state
 			if len(nsRwSet.KvRwSet.Writes) != 0 {
-				var writeSet []models.WriteKV
+				var (
+					writeSet  []models.WriteKV
+					documents []db.Document
+				)
 				for _, write := range nsRwSet.KvRwSet.Writes {
-					writeSet = append(writeSet, models.WriteKV{Key: write.Key, Value: base64.StdEncoding.EncodeToString(write.Value)})
+					writeSet = append(writeSet, models.WriteKV{Key: write.Key, Value: base64.StdEncoding.EncodeToString(write.Value), IsDelete: write.IsDelete})
+					if doc, ok := decoder.Decode(nsRwSet.NameSpace, write.Key, write.Value); ok {
+						documents = append(documents, db.Document{Key: write.Key, Value: doc})
+					}
+
+					// invalid txs don't change world state
+					if validationCode == int32(peer.TxValidationCode_VALID) {
+						objectType, attributes, _ := db.SplitCompositeKey(write.Key)
+						customBlock.KeyHistory = append(customBlock.KeyHistory, db.KeyModification{
+							ChannelId:  channelHeader.ChannelId,
+							Namespace:  nsRwSet.NameSpace,
+							Key:        write.Key,
+							Blocknum:   block.Header.Number,
+							TxNum:      uint64(txNum),
+							Txid:       TxId,
+							Value:      write.Value,
+							IsDelete:   write.IsDelete,
+							Time:       txtime.Unix(),
+							ObjectType: objectType,
+							Attributes: attributes,
+						})
+					}
 				}
 
 				jsonPayload, err := json.Marshal(writeSet)
@@ -223,14 +324,20 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 					return nil, err
 				}
 				tx := db.Tx{
-					channelHeader.ChannelId,
-					TxId,
-					hash,
-					previoushash,
-					block.Header.Number,
-					jsonPayload,
-					validationCode,
-					txtime.Unix(),
+					ChannelId:      channelHeader.ChannelId,
+					Txid:           TxId,
+					Hash:           hash,
+					PreviousHash:   previoushash,
+					Blocknum:       block.Header.Number,
+					TxNum:          uint64(txNum),
+					Payload:        jsonPayload,
+					ValidationCode: validationCode,
+					Time:           txtime.Unix(),
+					Chaincode:      nsRwSet.NameSpace,
+					CreatorMSP:     creatorMSP,
+					TxType:         txType,
+					Keys:           writeSetKeys(writeSet),
+					Documents:      documents,
 				}
 				customBlock.Txs = append(customBlock.Txs, tx)
 			}
@@ -240,6 +347,40 @@ func HandleBlock(block *fabcommon.Block) (*CustomBlock, error) {
 	return customBlock, nil
 }
 
+// creatorMSPFromEnvelope returns MSP ID of the identity which signed the tx
+func creatorMSPFromEnvelope(envelope *fabcommon.Envelope) (string, error) {
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal payload")
+	}
+	if payload.Header == nil {
+		return "", nil
+	}
+
+	signatureHeader, err := protoutil.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal signature header")
+	}
+	if len(signatureHeader.Creator) == 0 {
+		return "", nil
+	}
+
+	creator, err := protoutil.UnmarshalSerializedIdentity(signatureHeader.Creator)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal tx creator")
+	}
+
+	return creator.Mspid, nil
+}
+
+func writeSetKeys(writeSet []models.WriteKV) []string {
+	keys := make([]string, 0, len(writeSet))
+	for _, kv := range writeSet {
+		keys = append(keys, kv.Key)
+	}
+	return keys
+}
+
 // ConfigEnvelopeFromBlock extracts configuration envelope from the block based on the
 // config type, i.e. HeaderType_ORDERER_TRANSACTION or HeaderType_CONFIG
 func ConfigEnvelopeFromBlock(block *fabcommon.Block) (*fabcommon.Envelope, string, error) {
@@ -277,3 +418,18 @@ func ConfigEnvelopeFromBlock(block *fabcommon.Block) (*fabcommon.Envelope, strin
 		return nil, "", errors.Errorf("unexpected header type: %v", channelHeader.Type)
 	}
 }
+
+// Derive returns db.DeriveFunc parsing marshaled blocks the same way as HandleBlock
+func Derive(decoders ...ValueDecoder) db.DeriveFunc {
+	return func(raw []byte) ([]db.Tx, []db.KeyModification, error) {
+		block, err := protoutil.UnmarshalBlock(raw)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to unmarshal block")
+		}
+		customBlock, err := HandleBlock(block, decoders...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return customBlock.Txs, customBlock.KeyHistory, nil
+	}
+}
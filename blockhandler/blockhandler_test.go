@@ -1,6 +1,7 @@
 package blockhandler
 
 import (
+	"github.com/hyperledger-labs/fabex/db"
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
@@ -20,3 +21,93 @@ func TestGetBlock(t *testing.T) {
 	assert.Equal(t, nil, err, "GetBlock err not nil")
 	assert.Greater(t, len(block.Txs), 0, "GetBlock result empty")
 }
+
+func TestGetBlockTxAttributes(t *testing.T) {
+	blockBytes, err := ioutil.ReadFile("../tests/custom.block")
+	if err != nil {
+		panic(err)
+	}
+	rawBlock, err := protoutil.UnmarshalBlock(blockBytes)
+	if err != nil {
+		panic(err)
+	}
+	block, err := HandleBlock(rawBlock)
+	assert.Equal(t, nil, err, "GetBlock err not nil")
+	assert.Greater(t, len(block.Txs), 0, "GetBlock result empty")
+
+	tx := block.Txs[0]
+	assert.Equal(t, "lscc", tx.Chaincode)
+	assert.Equal(t, "Org1MSP", tx.CreatorMSP)
+	assert.Equal(t, "ENDORSER_TRANSACTION", tx.TxType)
+	assert.Equal(t, []string{"fabcar"}, tx.Keys)
+}
+
+func TestGetBlockKeyHistory(t *testing.T) {
+	blockBytes, err := ioutil.ReadFile("../tests/custom.block")
+	if err != nil {
+		panic(err)
+	}
+	rawBlock, err := protoutil.UnmarshalBlock(blockBytes)
+	if err != nil {
+		panic(err)
+	}
+	block, err := HandleBlock(rawBlock)
+	assert.Equal(t, nil, err, "GetBlock err not nil")
+	assert.Greater(t, len(block.KeyHistory), 0, "key history is empty")
+
+	mod := block.KeyHistory[0]
+	assert.Equal(t, "lscc", mod.Namespace)
+	assert.Equal(t, "fabcar", mod.Key)
+	assert.Equal(t, block.Txs[0].Txid, mod.Txid)
+	assert.Equal(t, rawBlock.Header.Number, mod.Blocknum)
+	assert.False(t, mod.IsDelete)
+	assert.NotEmpty(t, mod.Value)
+}
+
+func TestGetBlockReads(t *testing.T) {
+	blockBytes, err := ioutil.ReadFile("../tests/custom.block")
+	if err != nil {
+		panic(err)
+	}
+	rawBlock, err := protoutil.UnmarshalBlock(blockBytes)
+	if err != nil {
+		panic(err)
+	}
+	block, err := HandleBlock(rawBlock)
+	assert.Equal(t, nil, err, "GetBlock err not nil")
+	assert.Len(t, block.Reads, 1)
+
+	// chaincode is read from lscc before deployment, so it has no version
+	read := block.Reads[0]
+	assert.Equal(t, "lscc", read.Namespace)
+	assert.Equal(t, "fabcar", read.Key)
+	assert.Nil(t, read.Version)
+}
+
+func TestDerive(t *testing.T) {
+	blockBytes, err := ioutil.ReadFile("../tests/custom.block")
+	if err != nil {
+		panic(err)
+	}
+	txs, history, err := Derive()(blockBytes)
+	assert.Equal(t, nil, err, "Derive err not nil")
+	assert.Greater(t, len(txs), 0, "Derive txs empty")
+	assert.Equal(t, "lscc", txs[0].Chaincode)
+	assert.Greater(t, len(history), 0, "Derive key history empty")
+
+	_, _, err = Derive()([]byte("not a block"))
+	assert.Error(t, err)
+}
+
+func TestCustomBlockTxNum(t *testing.T) {
+	block := &CustomBlock{Txs: []db.Tx{
+		{Txid: "tx1", TxNum: 0, Chaincode: "fabcar"},
+		{Txid: "tx1", TxNum: 0, Chaincode: "marbles"},
+		{Txid: "dup", TxNum: 1},
+		{Txid: "dup", TxNum: 2},
+	}}
+	// positions of txs with unique ids don't depend on stored positions
+	assert.Equal(t, uint64(0), block.TxNum("tx1", 5))
+	assert.Equal(t, uint64(2), block.TxNum("dup", 2))
+	assert.Equal(t, uint64(3), block.TxNum("unknown", 3))
+}
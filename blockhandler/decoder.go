@@ -0,0 +1,141 @@
+package blockhandler
+
+import (
+	"io/ioutil"
+	"regexp"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ValueDecoder decodes write values of the chaincode into documents, ok is false if the value can't be decoded
+type ValueDecoder interface {
+	Decode(namespace, key string, value []byte) (doc map[string]interface{}, ok bool)
+}
+
+// JSONDecoder decodes values which are JSON objects
+type JSONDecoder struct{}
+
+func (JSONDecoder) Decode(_, _ string, value []byte) (map[string]interface{}, bool) {
+	return db.DecodeJSON(value)
+}
+
+// Decoders tries decoders in order, the first decoded document is used
+type Decoders []ValueDecoder
+
+func (d Decoders) Decode(namespace, key string, value []byte) (map[string]interface{}, bool) {
+	for _, decoder := range d {
+		if doc, ok := decoder.Decode(namespace, key, value); ok {
+			return doc, true
+		}
+	}
+	return nil, false
+}
+
+// ProtoRule binds protobuf message to values of the chaincode keys
+type ProtoRule struct {
+	Chaincode string
+	// KeyPattern is a regular expression of keys, empty pattern matches all keys of the chaincode
+	KeyPattern string
+	// DescriptorSet is a path to FileDescriptorSet, e.g. produced by protoc --include_imports --descriptor_set_out
+	DescriptorSet string
+	// Message is the full name of the message, e.g. assets.Asset
+	Message string
+}
+
+type protoRule struct {
+	chaincode string
+	key       *regexp.Regexp
+	message   protoreflect.MessageDescriptor
+}
+
+// ProtoDecoder decodes protobuf values into documents using dynamic messages
+type ProtoDecoder struct {
+	rules []protoRule
+}
+
+// NewProtoDecoder loads descriptor sets of the rules
+func NewProtoDecoder(rules []ProtoRule) (*ProtoDecoder, error) {
+	files := make(map[string]*protoregistry.Files)
+	decoder := &ProtoDecoder{}
+	for _, rule := range rules {
+		key, err := regexp.Compile(rule.KeyPattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key pattern of chaincode %s", rule.Chaincode)
+		}
+
+		set, ok := files[rule.DescriptorSet]
+		if !ok {
+			if set, err = loadDescriptorSet(rule.DescriptorSet); err != nil {
+				return nil, err
+			}
+			files[rule.DescriptorSet] = set
+		}
+
+		desc, err := set.FindDescriptorByName(protoreflect.FullName(rule.Message))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find message %s in %s", rule.Message, rule.DescriptorSet)
+		}
+		message, ok := desc.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, errors.Errorf("%s is not a message", rule.Message)
+		}
+
+		decoder.rules = append(decoder.rules, protoRule{chaincode: rule.Chaincode, key: key, message: message})
+	}
+
+	return decoder, nil
+}
+
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read descriptor set")
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = protov2.Unmarshal(raw, set); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal descriptor set %s", path)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid descriptor set %s", path)
+	}
+
+	return files, nil
+}
+
+// Decode decodes the value with the message of the first matching rule. Values with unknown fields are not decoded,
+// because arbitrary bytes often look like valid protobuf with unknown fields.
+func (d *ProtoDecoder) Decode(namespace, key string, value []byte) (map[string]interface{}, bool) {
+	// deletes have no value
+	if len(value) == 0 {
+		return nil, false
+	}
+
+	for _, rule := range d.rules {
+		if rule.chaincode != namespace || !rule.key.MatchString(key) {
+			continue
+		}
+
+		msg := dynamicpb.NewMessage(rule.message)
+		if err := protov2.Unmarshal(value, msg); err != nil || len(msg.GetUnknown()) != 0 {
+			return nil, false
+		}
+		raw, err := protojson.Marshal(msg)
+		if err != nil {
+			return nil, false
+		}
+		return db.DecodeJSON(raw)
+	}
+
+	return nil, false
+}
@@ -0,0 +1,74 @@
+package blockhandler
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// writeDescriptorSet writes descriptor set of assets.Asset{string owner = 1; int64 size = 2;}
+func writeDescriptorSet(t *testing.T) (string, *descriptorpb.FileDescriptorProto) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protov2.String("assets.proto"),
+		Package: protov2.String("assets"),
+		Syntax:  protov2.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protov2.String("Asset"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: protov2.String("owner"), JsonName: protov2.String("owner"), Number: protov2.Int32(1),
+					Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: protov2.String("size"), JsonName: protov2.String("size"), Number: protov2.Int32(2),
+					Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+			},
+		}},
+	}
+	raw, err := protov2.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "assets.pb")
+	assert.NoError(t, ioutil.WriteFile(path, raw, 0644))
+
+	return path, file
+}
+
+func TestProtoDecoder(t *testing.T) {
+	path, file := writeDescriptorSet(t)
+	decoder, err := NewProtoDecoder([]ProtoRule{{Chaincode: "assets", KeyPattern: "^asset", DescriptorSet: path, Message: "assets.Asset"}})
+	assert.NoError(t, err)
+
+	fd, err := protodesc.NewFile(file, nil)
+	assert.NoError(t, err)
+	msg := dynamicpb.NewMessage(fd.Messages().ByName("Asset"))
+	msg.Set(fd.Messages().ByName("Asset").Fields().ByName("owner"), protoreflect.ValueOfString("Tomoko"))
+	value, err := protov2.Marshal(msg)
+	assert.NoError(t, err)
+
+	doc, ok := decoder.Decode("assets", "asset1", value)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"owner": "Tomoko"}, doc)
+
+	// other chaincode or key
+	_, ok = decoder.Decode("fabcar", "asset1", value)
+	assert.False(t, ok)
+	_, ok = decoder.Decode("assets", "car1", value)
+	assert.False(t, ok)
+
+	// not a message, falls back to raw bytes
+	_, ok = decoder.Decode("assets", "asset1", []byte(`{"owner":"Tomoko"}`))
+	assert.False(t, ok)
+
+	// JSON is decoded by the next decoder of the chain
+	doc, ok = Decoders{decoder, JSONDecoder{}}.Decode("assets", "asset1", []byte(`{"owner":"Tomoko"}`))
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"owner": "Tomoko"}, doc)
+
+	_, err = NewProtoDecoder([]ProtoRule{{Chaincode: "assets", DescriptorSet: path, Message: "assets.Car"}})
+	assert.Error(t, err)
+}
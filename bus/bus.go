@@ -0,0 +1,175 @@
+// Package bus notifies subscribers in the process about committed blocks. Events aren't kept over restarts,
+// so consumers which must see every block (sinks and webhooks) are run through sink.Fanout, which checkpoints them.
+package bus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/pkg/errors"
+)
+
+// DefaultBufferSize is the number of events buffered for a subscriber by default
+const DefaultBufferSize = 64
+
+// Policy defines what Publish does when the buffer of a subscriber is full
+type Policy int
+
+const (
+	// DropOldest discards the oldest buffered event, so a slow subscriber sees the latest blocks
+	DropOldest Policy = iota
+	// DropNewest discards the published event
+	DropNewest
+	// Block makes Publish wait for the subscriber, it slows down ingestion of all channels
+	Block
+)
+
+// Event is published when all txs of the block are stored and the block is processed
+type Event struct {
+	Channel string
+	Block   *blockhandler.CustomBlock
+}
+
+// Options of a subscription, events of all channels are received if Channel is empty
+type Options struct {
+	Channel string
+	Buffer  int
+	Policy  Policy
+}
+
+// Bus delivers block committed events from explorers to subscribers in the process
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+	last map[string]uint64
+}
+
+// New creates bus without subscribers
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{}), last: make(map[string]uint64)}
+}
+
+// Publish delivers the event to subscribers of its channel, it returns an error only if ctx is done
+// while waiting for a subscriber with Block policy. Events are delivered outside of the bus lock,
+// so a waiting publisher doesn't block subscribing, closing and other publishers.
+func (b *Bus) Publish(ctx context.Context, ev Event) error {
+	b.mu.Lock()
+	b.last[ev.Channel] = ev.Block.Number
+	var subs []*Subscription
+	for s := range b.subs {
+		if s.opts.Channel == "" || s.opts.Channel == ev.Channel {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if err := s.deliver(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Last returns the last block of the channel published since start
+func (b *Bus) Last(ch string) (uint64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	blocknum, ok := b.last[ch]
+	return blocknum, ok
+}
+
+// Subscribe starts receiving events, the subscription must be closed when it isn't needed anymore
+func (b *Bus) Subscribe(opts Options) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBufferSize
+	}
+	s := &Subscription{bus: b, opts: opts, events: make(chan Event, opts.Buffer), done: make(chan struct{})}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Subscription is a bounded buffer of events of a single subscriber
+type Subscription struct {
+	bus     *Bus
+	opts    Options
+	events  chan Event
+	dropped uint64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	// mu guards events from being closed during delivery
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Events returns buffered events, the channel is closed by Close
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events discarded because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close unsubscribes, events which are still buffered can be read
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		// unblocks publishers waiting for the subscriber, so the lock can be taken
+		close(s.done)
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+	})
+}
+
+// deliver buffers the event, events of a closed subscription are discarded
+func (s *Subscription) deliver(ctx context.Context, ev Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+
+	switch s.opts.Policy {
+	case Block:
+		select {
+		case s.events <- ev:
+		case <-s.done:
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "failed to publish block %d", ev.Block.Number)
+		}
+	case DropNewest:
+		select {
+		case s.events <- ev:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	default:
+		for {
+			select {
+			case s.events <- ev:
+				return nil
+			default:
+			}
+			// the subscriber may take the event concurrently, so nothing is dropped then
+			select {
+			case <-s.events:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	}
+	return nil
+}
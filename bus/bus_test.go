@@ -0,0 +1,91 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func event(ch string, blocknum uint64) Event {
+	return Event{Channel: ch, Block: &blockhandler.CustomBlock{Number: blocknum}}
+}
+
+func blocknums(sub *Subscription) []uint64 {
+	var nums []uint64
+	for {
+		select {
+		case ev := <-sub.Events():
+			nums = append(nums, ev.Block.Number)
+		default:
+			return nums
+		}
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	b := New()
+	oldest := b.Subscribe(Options{Buffer: 2, Policy: DropOldest})
+	defer oldest.Close()
+	newest := b.Subscribe(Options{Buffer: 2, Policy: DropNewest})
+	defer newest.Close()
+	other := b.Subscribe(Options{Channel: "other"})
+	defer other.Close()
+
+	for i := uint64(1); i <= 4; i++ {
+		require.NoError(t, b.Publish(context.Background(), event("mychannel", i)))
+	}
+
+	assert.Equal(t, []uint64{3, 4}, blocknums(oldest))
+	assert.Equal(t, uint64(2), oldest.Dropped())
+	assert.Equal(t, []uint64{1, 2}, blocknums(newest))
+	assert.Equal(t, uint64(2), newest.Dropped())
+	assert.Empty(t, blocknums(other))
+
+	last, ok := b.Last("mychannel")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), last)
+	_, ok = b.Last("other")
+	assert.False(t, ok)
+}
+
+func TestBlock(t *testing.T) {
+	b := New()
+	sub := b.Subscribe(Options{Buffer: 1, Policy: Block})
+	require.NoError(t, b.Publish(context.Background(), event("mychannel", 1)))
+
+	// the publisher waits for the full buffer until ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, b.Publish(ctx, event("mychannel", 2)))
+
+	// and is released when the subscriber is closed
+	published := make(chan error)
+	go func() {
+		published <- b.Publish(context.Background(), event("mychannel", 3))
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the waiting publisher doesn't hold the bus
+	subscribed := make(chan struct{})
+	go func() {
+		b.Subscribe(Options{Channel: "other"}).Close()
+		close(subscribed)
+	}()
+	select {
+	case <-subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("subscribing is blocked by the publisher")
+	}
+	sub.Close()
+	require.NoError(t, <-published)
+
+	var nums []uint64
+	for ev := range sub.Events() {
+		nums = append(nums, ev.Block.Number)
+	}
+	assert.Equal(t, []uint64{1}, nums)
+}
@@ -80,6 +80,12 @@ func main() {
 	//	l.Panic(err.Error())
 	//}
 
+	// get the first page of txs, pass returned token to the next call for getting the next page
+	//txs, nextPageToken, err := client.List("ch1", nil, 10, "", proto.SortOrder_ASC)
+	//if err != nil {
+	//	l.Panic(err.Error())
+	//}
+
 	blocks, err := helpers.PackTxsToBlocks(txs)
 	if err != nil {
 		l.Panic(err.Error())
@@ -17,19 +17,26 @@
 package client
 
 import (
+	"bytes"
 	"io"
 	"net"
 	"reflect"
 
+	"github.com/hyperledger-labs/fabex/archive"
 	"github.com/hyperledger-labs/fabex/db"
 	pb "github.com/hyperledger-labs/fabex/proto"
+	pbv2 "github.com/hyperledger-labs/fabex/proto/v2"
+	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type FabexClient struct {
 	Client pb.FabexClient
+	// V2 is the client of fabex.v2 API with typed blocks and transactions
+	V2 pbv2.FabexClient
 }
 
 func New(addr, port string) (*FabexClient, error) {
@@ -39,7 +46,7 @@ func New(addr, port string) (*FabexClient, error) {
 		return nil, errors.Wrap(err, "failed to connect")
 	}
 
-	return &FabexClient{pb.NewFabexClient(conn)}, nil
+	return &FabexClient{Client: pb.NewFabexClient(conn), V2: pbv2.NewFabexClient(conn)}, nil
 }
 
 func (fabexCli *FabexClient) GetRange(channel string, startblock, endblock int) ([]db.Tx, error) {
@@ -58,7 +65,7 @@ func (fabexCli *FabexClient) GetRange(channel string, startblock, endblock int)
 		if err != nil {
 			return nil, err
 		}
-		txs = append(txs, db.Tx{ChannelId: in.Channelid, Blocknum: in.Blocknum, Hash: in.Hash, PreviousHash: in.Previoushash, Txid: in.Txid, Payload: in.Payload, Time: in.Time, ValidationCode: in.Validationcode})
+		txs = append(txs, txFromEntry(in))
 	}
 }
 
@@ -85,6 +92,159 @@ func (fabexCli *FabexClient) Get(filter *pb.Entry) ([]db.Tx, error) {
 		if err != nil {
 			return txs, err
 		}
-		txs = append(txs, db.Tx{ChannelId: in.Channelid, Blocknum: in.Blocknum, Hash: in.Hash, PreviousHash: in.Previoushash, Txid: in.Txid, Payload: in.Payload, Time: in.Time, ValidationCode: in.Validationcode})
+		txs = append(txs, txFromEntry(in))
 	}
 }
+
+// List returns a page of channel txs (filtered by payload if it's not empty) and a token of the next page.
+// Pass empty pageToken to get the first page, empty returned token means there are no more txs.
+func (fabexCli *FabexClient) List(channel string, payload []byte, pageSize int, pageToken string, order pb.SortOrder) ([]db.Tx, string, error) {
+	page, err := fabexCli.Client.List(context.Background(), &pb.RequestPage{Channelid: channel, Payload: payload, Pagesize: int64(pageSize), Pagetoken: pageToken, Order: order})
+	if err != nil {
+		return nil, "", err
+	}
+
+	txs := make([]db.Tx, 0, len(page.Entries))
+	for _, in := range page.Entries {
+		txs = append(txs, txFromEntry(in))
+	}
+
+	return txs, page.Nextpagetoken, nil
+}
+
+// Query returns a page of channel txs matching the query filters and a token of the next page
+func (fabexCli *FabexClient) Query(query *pb.RequestQuery) ([]db.Tx, string, error) {
+	page, err := fabexCli.Client.Query(context.Background(), query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	txs := make([]db.Tx, 0, len(page.Entries))
+	for _, in := range page.Entries {
+		txs = append(txs, txFromEntry(in))
+	}
+
+	return txs, page.Nextpagetoken, nil
+}
+
+// GetKeyHistory returns a page of versions of the chaincode key and a token of the next page
+func (fabexCli *FabexClient) GetKeyHistory(channel, namespace, key string, pageSize int, pageToken string, order pb.SortOrder) ([]db.KeyModification, string, error) {
+	history, err := fabexCli.Client.GetKeyHistory(context.Background(), &pb.RequestKeyHistory{Channelid: channel, Namespace: namespace, Key: key, Pagesize: int64(pageSize), Pagetoken: pageToken, Order: order})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mods := make([]db.KeyModification, 0, len(history.Modifications))
+	for _, in := range history.Modifications {
+		mods = append(mods, keyModificationFromPb(in))
+	}
+
+	return mods, history.Nextpagetoken, nil
+}
+
+// GetCompositeKeyHistory returns a page of versions of composite keys with the object type and leading attributes
+func (fabexCli *FabexClient) GetCompositeKeyHistory(channel, namespace, objectType string, attributes []string, pageSize int, pageToken string, order pb.SortOrder) ([]db.KeyModification, string, error) {
+	history, err := fabexCli.Client.GetCompositeKeyHistory(context.Background(), &pb.RequestCompositeKeyHistory{Channelid: channel, Namespace: namespace, Objecttype: objectType,
+		Attributes: attributes, Pagesize: int64(pageSize), Pagetoken: pageToken, Order: order})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mods := make([]db.KeyModification, 0, len(history.Modifications))
+	for _, in := range history.Modifications {
+		mods = append(mods, keyModificationFromPb(in))
+	}
+
+	return mods, history.Nextpagetoken, nil
+}
+
+// GetState returns the current value of the chaincode key, or its value as of the block if block is not nil
+func (fabexCli *FabexClient) GetState(channel, namespace, key string, block *uint64) (db.KeyModification, error) {
+	req := &pb.RequestState{Channelid: channel, Namespace: namespace, Key: key}
+	if block != nil {
+		req.Block = wrapperspb.UInt64(*block)
+	}
+
+	mod, err := fabexCli.Client.GetState(context.Background(), req)
+	if err != nil {
+		return db.KeyModification{}, err
+	}
+
+	return keyModificationFromPb(mod), nil
+}
+
+// GetRawBlock returns the archived block, it is verified against its header, which must be checked by the caller,
+// e.g. by comparing the returned header hash with previous hash of the next block
+func (fabexCli *FabexClient) GetRawBlock(channel string, blocknum uint64) (*common.Block, []byte, error) {
+	resp, err := fabexCli.Client.GetRawBlock(context.Background(), &pb.RequestRawBlock{Channelid: channel, Blocknum: blocknum})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, hash, err := archive.Verify(resp.Block)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(hash, resp.Headerhash) {
+		return nil, nil, errors.Errorf("header hash of block %d doesn't match", blocknum)
+	}
+	if block.Header.Number != blocknum {
+		return nil, nil, errors.Errorf("got block %d instead of %d", block.Header.Number, blocknum)
+	}
+
+	return block, hash, nil
+}
+
+// ScanState returns a page of world state keys of the chaincode and a token of the next page
+func (fabexCli *FabexClient) ScanState(req *pb.RequestStateScan) ([]db.KeyModification, string, error) {
+	page, err := fabexCli.Client.ScanState(context.Background(), req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mods := make([]db.KeyModification, 0, len(page.Entries))
+	for _, in := range page.Entries {
+		mods = append(mods, keyModificationFromPb(in))
+	}
+
+	return mods, page.Nextpagetoken, nil
+}
+
+// Subscribe calls handle for stored txs matching the request, then for txs of new blocks as they are indexed.
+// It blocks until ctx is done (nil is returned then), handle or the stream fails. Pass the last processed block
+// as req.Checkpoint to resume after reconnecting.
+func (fabexCli *FabexClient) Subscribe(ctx context.Context, req *pb.RequestSubscribe, handle func(tx db.Tx) error) error {
+	stream, err := fabexCli.Client.Subscribe(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		in, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err = handle(txFromEntry(in)); err != nil {
+			return err
+		}
+	}
+}
+
+func keyModificationFromPb(in *pb.KeyModification) db.KeyModification {
+	return db.KeyModification{ChannelId: in.Channelid, Namespace: in.Namespace, Key: in.Key, Blocknum: in.Blocknum, TxNum: in.Txnum,
+		Txid: in.Txid, Value: in.Value, IsDelete: in.Isdelete, Time: in.Time, ObjectType: in.Objecttype, Attributes: in.Attributes}
+}
+
+func txFromEntry(in *pb.Entry) db.Tx {
+	tx := db.Tx{ChannelId: in.Channelid, Blocknum: in.Blocknum, TxNum: in.Txnum, Hash: in.Hash, PreviousHash: in.Previoushash, Txid: in.Txid, Payload: in.Payload, Time: in.Time, ValidationCode: in.Validationcode,
+		Chaincode: in.Chaincode, CreatorMSP: in.Creatormsp, TxType: in.Txtype, Keys: in.Keys}
+	for _, doc := range in.Documents {
+		if value, ok := db.DecodeJSON(doc.Value); ok {
+			tx.Documents = append(tx.Documents, db.Document{Key: doc.Key, Value: value})
+		}
+	}
+	return tx
+}
@@ -18,22 +18,17 @@ package client
 
 import (
 	"bytes"
-	"github.com/hyperledger-labs/fabex/models"
 	pb "github.com/hyperledger-labs/fabex/proto"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"os"
 	"os/exec"
 	"path"
-	"sync"
 	"testing"
 	"time"
 )
 
-var (
-	fabex *models.Fabex
-	wg    *sync.WaitGroup
-)
+const channel = "mychannel"
 
 func ExecuteCMD(command string, args ...string) (*exec.Cmd, error) {
 	cmd := exec.Command(command, args...)
@@ -116,7 +111,7 @@ func TestGetRange(t *testing.T) {
 	if err != nil {
 		t.Errorf(err.Error())
 	}
-	txs, err := fabcli.GetRange(0, 3)
+	txs, err := fabcli.GetRange(channel, 0, 3)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -129,7 +124,7 @@ func TestGet(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 
-	txs, err := fabcli.Get(&pb.Entry{Blocknum: 1})
+	txs, err := fabcli.Get(&pb.Entry{Channelid: channel, Blocknum: 1})
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -138,7 +133,7 @@ func TestGet(t *testing.T) {
 		assert.EqualValuesf(t, tx.Blocknum, 1, "Not valid tx retrieved, got %d, want %d", tx.Blocknum, 1)
 	}
 
-	txs, err = fabcli.Get(&pb.Entry{Txid: txs[0].Txid})
+	txs, err = fabcli.Get(&pb.Entry{Channelid: channel, Txid: txs[0].Txid})
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -150,7 +145,7 @@ func TestGetAllAndCheckValidationCode(t *testing.T) {
 	if err != nil {
 		t.Errorf(err.Error())
 	}
-	txs, err := fabcli.Get(nil)
+	txs, err := fabcli.Get(&pb.Entry{Channelid: channel})
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -159,3 +154,35 @@ func TestGetAllAndCheckValidationCode(t *testing.T) {
 		assert.Equal(t, tx.ValidationCode, int32(0), "validation code of tx %s is %d (invalid)", tx.Txid, tx.ValidationCode)
 	}
 }
+
+func TestList(t *testing.T) {
+	fabcli, err := New("localhost", "6000")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	all, err := fabcli.Get(&pb.Entry{Channelid: channel})
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	var (
+		listed []string
+		token  string
+	)
+	for {
+		txs, next, err := fabcli.List(channel, nil, 2, token, pb.SortOrder_ASC)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		assert.LessOrEqual(t, len(txs), 2, "page is bigger than requested")
+		for _, tx := range txs {
+			listed = append(listed, tx.Txid)
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	assert.Equal(t, len(all), len(listed), "paginated listing differs from full listing")
+}
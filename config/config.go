@@ -1,23 +1,44 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v6"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
 type Mongo struct {
+	// Uri is a connection string (mongodb:// or mongodb+srv://), Host and Port are used if it's empty
+	Uri        string
 	Host       string
 	Port       int
 	Dbuser     string
 	Dbsecret   string
-	Dbname     string
-	Collection string
+	Authsource string
+	Tls        bool
+	Tlscafile  string
+	// Tlsinsecure disables verification of server certificates
+	Tlsinsecure bool
+	Dbname      string
+	Collection  string
+}
+
+type Cassandra struct {
+	Host         string
+	Dbuser       string
+	Dbsecret     string
+	Keyspace     string
+	Columnfamily string
 }
 
+// GRPCServer limits block ranges of finite streams to Maxrange blocks and their duration to Streamtimeout,
+// defaults are used for zero values
 type GRPCServer struct {
-	Host string
-	Port string
+	Host          string
+	Port          string
+	Maxrange      uint64
+	Streamtimeout time.Duration
 }
 
 type Fabric struct {
@@ -33,11 +54,103 @@ type UI struct {
 	Port string
 }
 
+type State struct {
+	Enabled bool
+}
+
+// Archive stores original blocks, Backend is "database" (the index database) or "fs" (files under Path)
+type Archive struct {
+	Enabled bool
+	Backend string
+	Path    string
+}
+
+// S3 exports blocks into S3-compatible bucket, Format is "raw" or "json"
+type S3 struct {
+	Enabled   bool
+	Endpoint  string
+	Accesskey string
+	Secretkey string
+	Region    string
+	Usessl    bool
+	Bucket    string
+	Prefix    string
+	Format    string
+	// Segmentsize is the number of blocks listed by a single manifest
+	Segmentsize uint64
+}
+
+// Kafka publishes decoded data into topics, Encoding is "json" or "protobuf". Messages of a block are published
+// in a Kafka transaction if Transactionalid is set. Empty topics are not published.
+type Kafka struct {
+	Enabled         bool
+	Brokers         []string
+	Transactionalid string
+	Encoding        string
+	Blockstopic     string
+	Txstopic        string
+	Writestopic     string
+	Eventstopic     string
+}
+
+// Webhooks delivers txs matching subscriptions, failed deliveries are retried Attempts times with delays
+// from Retrymin to Retrymax. Every subscription has a queue of Queuesize blocks. Callback targets in
+// loopback, link-local and private networks are rejected unless they are in Allowednetworks (CIDR).
+type Webhooks struct {
+	Enabled         bool
+	Attempts        int
+	Retrymin        time.Duration
+	Retrymax        time.Duration
+	Timeout         time.Duration
+	Queuesize       int
+	Allowednetworks []string
+}
+
+// Fanout writes blocks into several storages. Read is the storage serving APIs (mongo or cassandra), DB env
+// variable is used if it's empty. Blocks are written into Storages through queues of Queuesize blocks.
+type Fanout struct {
+	Read      string
+	Storages  []string
+	Queuesize int
+}
+
+// Retention prunes data of the channels every Interval
+type Retention struct {
+	Interval time.Duration
+	Channels []RetentionPolicy
+}
+
+// RetentionPolicy keeps the last Keepblocks blocks, blocks of the last Keepdays days and txs of Chaincodes only,
+// zero values are not used
+type RetentionPolicy struct {
+	Channel    string
+	Keepblocks uint64
+	Keepdays   int
+	Chaincodes []string
+}
+
+// Protobuf binds protobuf message from the descriptor set to values of the chaincode keys matching the pattern
+type Protobuf struct {
+	Chaincode     string
+	Keypattern    string
+	Descriptorset string
+	Message       string
+}
+
 type Config struct {
 	Mongo      `mapstructure:"mongo"`
+	Cassandra  `mapstructure:"cassandra"`
 	Fabric     `mapstructure:"fabric"`
 	GRPCServer `mapstructure:"grpc"`
 	UI         `mapstructure:"ui"`
+	State      `mapstructure:"state"`
+	Archive    `mapstructure:"archive"`
+	S3         `mapstructure:"s3"`
+	Kafka      `mapstructure:"kafka"`
+	Webhooks   `mapstructure:"webhooks"`
+	Retention  `mapstructure:"retention"`
+	Fanout     `mapstructure:"fanout"`
+	Protobuf   []Protobuf `mapstructure:"protobuf"`
 }
 
 type BootConfig struct {
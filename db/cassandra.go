@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -30,14 +32,44 @@ const (
 	PAYLOAD         = "Payload"
 	VALIDATION_CODE = "ValidationCode"
 	TIME            = "Time"
+	CHAINCODE       = "Chaincode"
+	CREATOR_MSP     = "CreatorMSP"
+	TX_TYPE         = "TxType"
 	PAYLOADKEYS     = "Payloadkeys"
+	NAMESPACE       = "Namespace"
+	KEY             = "Key"
+	TXNUM           = "TxNum"
+	VALUE           = "Value"
+	IS_DELETE       = "IsDelete"
+	DOCUMENTS       = "Documents"
+	OBJECT_TYPE     = "ObjectType"
+	ATTRIBUTES      = "Attributes"
 )
 
+// txColumns are columns read by scanTx
+var txColumns = strings.Join([]string{CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME,
+	CHAINCODE, CREATOR_MSP, TX_TYPE, PAYLOADKEYS, DOCUMENTS, TXNUM}, ", ")
+
+func scanTx(sc interface{ Scan(...interface{}) error }, tx *Tx) error {
+	// cassandra has no document type, documents are kept as JSON text
+	var documents string
+	// TxNum of txs stored before the column was added is 0
+	if err := sc.Scan(&tx.ChannelId, &tx.Txid, &tx.Hash, &tx.PreviousHash, &tx.Blocknum, &tx.Payload, &tx.ValidationCode, &tx.Time,
+		&tx.Chaincode, &tx.CreatorMSP, &tx.TxType, &tx.Keys, &documents, &tx.TxNum); err != nil {
+		return err
+	}
+	if documents == "" {
+		return nil
+	}
+
+	return errors.Wrap(json.Unmarshal([]byte(documents), &tx.Documents), "failed to unmarshal documents")
+}
+
 func NewCassandraClient(host, user, password, keyspace, columnfamily string) *Cassandra {
 	return &Cassandra{host, user, password, keyspace, columnfamily, nil}
 }
 
-func (c *Cassandra) Connect() error {
+func (c *Cassandra) Connect(ctx context.Context) error {
 	var err error
 	cluster := gocql.NewCluster(c.Host)
 	cluster.Timeout = 1000 * time.Second
@@ -52,11 +84,12 @@ func (c *Cassandra) Connect() error {
 	if err != nil {
 		return errors.WithStack(errors.Wrap(err, "cassandra system session creation failed"))
 	}
-	if err := c.Session.Query(fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH REPLICATION = { 'class' : 'SimpleStrategy', 'replication_factor' : 1 };", c.Keyspace)).Exec(); err != nil {
+	if err := c.Session.Query(fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH REPLICATION = { 'class' : 'SimpleStrategy', 'replication_factor' : 1 };", c.Keyspace)).WithContext(ctx).Exec(); err != nil {
 		return errors.WithStack(errors.Wrap(err, "failed to create keyspace"))
 	}
 
 	// reconnect with new keyspace
+	c.Session.Close()
 	cluster.Keyspace = c.Keyspace
 	c.Session, err = cluster.CreateSession()
 	if err != nil {
@@ -65,100 +98,163 @@ func (c *Cassandra) Connect() error {
 	return nil
 }
 
-func (c *Cassandra) Init(ch string) error {
-	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (ID UUID, %s text, %s text, %s text, %s text, %s bigint, %s text, %s int, %s int, %s list<text>, PRIMARY KEY(ID,%s));`, fmt.Sprintf("%s_%s", ch, c.Columnfamily),
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, PAYLOADKEYS, BLOCKNUM)
-	if err := c.Session.Query(query).Exec(); err != nil {
+func (c *Cassandra) Close(_ context.Context) error {
+	if c.Session != nil {
+		c.Session.Close()
+	}
+	return nil
+}
+
+func (c *Cassandra) Init(ctx context.Context, ch string) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (ID UUID, %s text, %s text, %s text, %s text, %s bigint, %s text, %s int, %s int, %s text, %s text, %s text, %s list<text>, %s text, %s bigint, PRIMARY KEY(ID,%s));`, fmt.Sprintf("%s_%s", ch, c.Columnfamily),
+		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, CHAINCODE, CREATOR_MSP, TX_TYPE, PAYLOADKEYS, DOCUMENTS, TXNUM, BLOCKNUM)
+	if err := c.Session.Query(query).WithContext(ctx).Exec(); err != nil {
 		return errors.Wrapf(err, "failed to create column family: %s", c.Columnfamily)
 	}
 
+	if err := c.createOrderedTable(ctx, ch); err != nil {
+		return err
+	}
+
 	// create hash index
 	indexHash := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS hash ON %s(%s);`, fmt.Sprintf("%s_%s", ch, c.Columnfamily), HASH)
-	if err := c.Session.Query(indexHash).Exec(); err != nil {
+	if err := c.Session.Query(indexHash).WithContext(ctx).Exec(); err != nil {
 		return errors.Wrapf(err, "failed to create index: %s", c.Columnfamily)
 	}
 
+	// key history is partitioned by key, so its versions can be read in block order without filtering
+	historyTable := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s text, %s text, %s bigint, %s bigint, %s text, %s text, %s blob, %s boolean, %s bigint, %s text, %s list<text>, PRIMARY KEY((%s, %s), %s, %s));`,
+		c.historyTable(ch), NAMESPACE, KEY, BLOCKNUM, TXNUM, CHANNEL_ID, TXID, VALUE, IS_DELETE, TIME, OBJECT_TYPE, ATTRIBUTES, NAMESPACE, KEY, BLOCKNUM, TXNUM)
+	if err := c.Session.Query(historyTable).WithContext(ctx).Exec(); err != nil {
+		return errors.Wrapf(err, "failed to create column family: %s", c.historyTable(ch))
+	}
+
+	checkpoints := fmt.Sprintf("CREATE TABLE IF NOT EXISTS checkpoints (channel text, consumer text, %s bigint, PRIMARY KEY(channel, consumer));", BLOCKNUM)
+	if err := c.Session.Query(checkpoints).WithContext(ctx).Exec(); err != nil {
+		return errors.Wrap(err, "failed to create column family: checkpoints")
+	}
+
+	blocksTable := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s bigint PRIMARY KEY, data blob);", c.blocksTable(ch), BLOCKNUM)
+	if err := c.Session.Query(blocksTable).WithContext(ctx).Exec(); err != nil {
+		return errors.Wrapf(err, "failed to create column family: %s", c.blocksTable(ch))
+	}
+
 	// Normalization. We can't use slow aggregation queries, so create column family with last entry
 	aggregationTable := fmt.Sprintf("CREATE TABLE IF NOT EXISTS MAX_%s (fortable text PRIMARY KEY, id UUID, hash text, blocknum bigint);", ch)
-	if err := c.Session.Query(aggregationTable).Exec(); err != nil {
+	if err := c.Session.Query(aggregationTable).WithContext(ctx).Exec(); err != nil {
 		return errors.Wrap(err, "failed to create column family: MAX")
 	}
-	return nil
-}
 
-func (c *Cassandra) Insert(ch string, tx Tx) error {
-	insert := fmt.Sprintf("INSERT INTO %s (ID, %s, %s, %s, %s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", fmt.Sprintf("%s_%s", ch, c.Columnfamily),
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, PAYLOADKEYS)
+	if err := c.initWebhooks(ctx); err != nil {
+		return err
+	}
 
-	var Payload []RW
-	err := json.Unmarshal(tx.Payload, &Payload)
+	if err := c.Session.Query("CREATE TABLE IF NOT EXISTS schema_versions (channel text PRIMARY KEY, version int);").WithContext(ctx).Exec(); err != nil {
+		return errors.Wrap(err, "failed to create column family: schema_versions")
+	}
+	return Migrate(ctx, c, ch)
+}
+
+func (c *Cassandra) Insert(ctx context.Context, ch string, tx Tx) error {
+	id, err := c.insertTx(ctx, ch, tx)
 	if err != nil {
 		return err
 	}
 
+	return c.UpdateMax(ctx, ch, id, tx.Blocknum, tx.Hash)
+}
+
+// insertTx stores the tx without updating the last entry
+func (c *Cassandra) insertTx(ctx context.Context, ch string, tx Tx) (gocql.UUID, error) {
+	insert := fmt.Sprintf("INSERT INTO %s (ID, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", fmt.Sprintf("%s_%s", ch, c.Columnfamily), txColumns)
+
+	var Payload []RW
+	if err := json.Unmarshal(tx.Payload, &Payload); err != nil {
+		return gocql.UUID{}, err
+	}
+
 	// extract keys from RWSet
 	var payloadkeys []string
 	for _, kv := range Payload {
 		payloadkeys = append(payloadkeys, kv.Key)
 	}
 
-	id := gocql.TimeUUID()
-	if err := c.Session.Query(insert, id, tx.ChannelId, tx.Txid, tx.Hash, tx.PreviousHash,
-		tx.Blocknum, tx.Payload, tx.ValidationCode, tx.Time, payloadkeys).Exec(); err != nil {
-		return err
+	var documents string
+	if len(tx.Documents) != 0 {
+		raw, err := json.Marshal(tx.Documents)
+		if err != nil {
+			return gocql.UUID{}, errors.Wrap(err, "failed to marshal documents")
+		}
+		documents = string(raw)
 	}
 
-	err = c.UpdateMax(ch, id, tx.Blocknum, tx.Hash)
-
-	return err
+	id := gocql.TimeUUID()
+	batch := c.Session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(insert, id, tx.ChannelId, tx.Txid, tx.Hash, tx.PreviousHash,
+		tx.Blocknum, tx.Payload, tx.ValidationCode, tx.Time, tx.Chaincode, tx.CreatorMSP, tx.TxType, payloadkeys, documents, tx.TxNum)
+	c.insertOrdered(batch, ch, id, tx, payloadkeys, documents)
+	return id, errors.WithStack(c.Session.ExecuteBatch(batch))
 }
 
-func (c *Cassandra) UpdateMax(ch string, id gocql.UUID, blocknum uint64, hash string) error {
-	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s LIMIT 1;", ch)).Exec()
+func (c *Cassandra) UpdateMax(ctx context.Context, ch string, id gocql.UUID, blocknum uint64, hash string) error {
+	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s LIMIT 1;", ch)).WithContext(ctx).Exec()
 	if err != nil && err.Error() != NOT_FOUND_ERR {
 		return errors.WithStack(err)
 	}
 	if err != nil && err.Error() == NOT_FOUND_ERR {
-		if err = c.Session.Query(fmt.Sprintf("INSERT INTO MAX_%s (fortable, id, hash, blocknum) VALUES (?, ?, ?, ?)", ch), fmt.Sprintf("%s_%s", ch, c.Columnfamily), id, hash, blocknum).Exec(); err != nil {
+		if err = c.Session.Query(fmt.Sprintf("INSERT INTO MAX_%s (fortable, id, hash, blocknum) VALUES (?, ?, ?, ?)", ch), fmt.Sprintf("%s_%s", ch, c.Columnfamily), id, hash, blocknum).WithContext(ctx).Exec(); err != nil {
 			return errors.WithStack(err)
 		}
 
 	}
-	err = c.Session.Query(fmt.Sprintf(`UPDATE MAX_%s SET id = ?, hash = ?, blocknum = ? where fortable = ?;`, ch), id, hash, blocknum, fmt.Sprintf("%s_%s", ch, c.Columnfamily)).Exec()
+	err = c.Session.Query(fmt.Sprintf(`UPDATE MAX_%s SET id = ?, hash = ?, blocknum = ? where fortable = ?;`, ch), id, hash, blocknum, fmt.Sprintf("%s_%s", ch, c.Columnfamily)).WithContext(ctx).Exec()
 	return errors.WithStack(err)
 }
 
-func (c *Cassandra) GetBlockInfoByPayload(ch string, payloadkey string) ([]Tx, error) {
+func (c *Cassandra) GetBlockInfoByPayload(_ context.Context, ch string, payloadkey string) ([]Tx, error) {
 	return nil, errors.WithStack(errors.New("not implemented for cassandra"))
 }
 
-func (c *Cassandra) QueryBlockByHash(ch string, hash string) ([]Tx, error) {
+func (c *Cassandra) QueryBlockByHash(ctx context.Context, ch string, hash string) ([]Tx, error) {
 	var id string
-	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s where hash = ? LIMIT 1 ALLOW FILTERING;", ch), hash).Scan(&id)
+	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s where hash = ? LIMIT 1 ALLOW FILTERING;", ch), hash).WithContext(ctx).Scan(&id)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return c.getByFilter(fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE id = ?",
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), id)
+	return c.getByFilter(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE id = ?",
+		txColumns, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), id)
 }
 
-func (c *Cassandra) GetByTxId(ch string, txID string) ([]Tx, error) {
-	return c.getByFilter(fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ?",
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, fmt.Sprintf("%s_%s", ch, c.Columnfamily), TXID), txID)
+func (c *Cassandra) GetByTxId(ctx context.Context, ch string, txID string) ([]Tx, error) {
+	return c.getByFilter(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?",
+		txColumns, fmt.Sprintf("%s_%s", ch, c.Columnfamily), TXID), txID)
 }
 
-func (c *Cassandra) QueryAll(ch string) ([]Tx, error) {
-	return c.getByFilter(fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s",
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), "")
+func (c *Cassandra) QueryAll(ctx context.Context, ch string) ([]Tx, error) {
+	return c.getByFilter(ctx, fmt.Sprintf("SELECT %s FROM %s",
+		txColumns, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), "")
 }
 
-func (c *Cassandra) GetByBlocknum(ch string, blocknum uint64) ([]Tx, error) {
-	return c.getByFilter(fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ?",
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, fmt.Sprintf("%s_%s", ch, c.Columnfamily), BLOCKNUM), strconv.FormatUint(blocknum, 10))
+// GetByBlocknum reads the block from the ordered table, so txs are returned in the order of insertion
+func (c *Cassandra) GetByBlocknum(ctx context.Context, ch string, blocknum uint64) ([]Tx, error) {
+	sc := c.Session.Query(fmt.Sprintf("SELECT %s FROM %s WHERE Bucket = ? AND %s = ?", txColumns, c.orderedTable(ch), BLOCKNUM),
+		blockBucket(blocknum), blocknum).WithContext(ctx).Iter().Scanner()
+	var txs []Tx
+	for sc.Next() {
+		var tx Tx
+		if err := scanTx(sc, &tx); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.WithStack(errors.Wrap(err, "cassandra query error"))
+	}
+	return txs, nil
 }
 
-func (c *Cassandra) GetLastEntry(ch string) (Tx, error) {
+func (c *Cassandra) GetLastEntry(ctx context.Context, ch string) (Tx, error) {
 	var (
 		tx     Tx
 		lastID string
@@ -169,7 +265,7 @@ func (c *Cassandra) GetLastEntry(ch string) (Tx, error) {
 	*/
 
 	// id (UUID) includes timestamp, so we use it for getting last tx ID
-	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s where fortable = ?;", ch), fmt.Sprintf("%s_%s", ch, c.Columnfamily)).Scan(&lastID)
+	err := c.Session.Query(fmt.Sprintf("SELECT id FROM MAX_%s where fortable = ?;", ch), fmt.Sprintf("%s_%s", ch, c.Columnfamily)).WithContext(ctx).Scan(&lastID)
 	if err != nil {
 		return Tx{}, err
 	}
@@ -178,26 +274,25 @@ func (c *Cassandra) GetLastEntry(ch string) (Tx, error) {
 	}
 
 	// get last tx using id as filter
-	err = c.Session.Query(fmt.Sprintf("SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s WHERE id = ? LIMIT 1",
-		CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), lastID).Scan(
-		&tx.ChannelId, &tx.Txid, &tx.Hash, &tx.PreviousHash, &tx.Blocknum, &tx.Payload, &tx.ValidationCode, &tx.Time)
+	query := c.Session.Query(fmt.Sprintf("SELECT %s FROM %s WHERE id = ? LIMIT 1",
+		txColumns, fmt.Sprintf("%s_%s", ch, c.Columnfamily)), lastID).WithContext(ctx)
+	err = scanTx(query, &tx)
 
 	return tx, err
 }
 
-func (c *Cassandra) getByFilter(sel string, filter string) ([]Tx, error) {
+func (c *Cassandra) getByFilter(ctx context.Context, sel string, filter string) ([]Tx, error) {
 	var txs []Tx
 	var sc gocql.Scanner
 
 	if filter != "" {
-		sc = c.Session.Query(fmt.Sprintf("%s ALLOW FILTERING", sel), filter).Iter().Scanner()
+		sc = c.Session.Query(fmt.Sprintf("%s ALLOW FILTERING", sel), filter).WithContext(ctx).Iter().Scanner()
 	} else {
-		sc = c.Session.Query(fmt.Sprintf("%s", sel)).Iter().Scanner()
+		sc = c.Session.Query(sel).WithContext(ctx).Iter().Scanner()
 	}
 	for sc.Next() {
 		var tx Tx
-		if err := sc.Scan(&tx.ChannelId, &tx.Txid, &tx.Hash, &tx.PreviousHash, &tx.Blocknum,
-			&tx.Payload, &tx.ValidationCode, &tx.Time); err != nil {
+		if err := scanTx(sc, &tx); err != nil {
 			return nil, err
 		}
 		txs = append(txs, tx)
@@ -207,3 +302,206 @@ func (c *Cassandra) getByFilter(sel string, filter string) ([]Tx, error) {
 	}
 	return txs, nil
 }
+
+func decodeCassandraCursor(page Page) ([]byte, error) {
+	state, err := base64.RawURLEncoding.DecodeString(page.Cursor)
+	if err != nil {
+		return nil, InvalidCursor(err)
+	}
+	return state, nil
+}
+
+func (c *Cassandra) QueryAllPage(ctx context.Context, ch string, page Page) ([]Tx, string, error) {
+	return c.queryOrdered(ctx, ch, 0, 0, nil, nil, page, nil)
+}
+
+// Query filters txs by all conditions except key prefix and document fields on cassandra side. Cassandra can't
+// filter by prefix of list items, so such txs are skipped after fetching and further rows are read to fill the page.
+func (c *Cassandra) Query(ctx context.Context, ch string, filter Filter) ([]Tx, string, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if !filter.FromTime.IsZero() {
+		conds, args = append(conds, TIME+" >= ?"), append(args, filter.FromTime.Unix())
+	}
+	if !filter.ToTime.IsZero() {
+		conds, args = append(conds, TIME+" <= ?"), append(args, filter.ToTime.Unix())
+	}
+	if filter.ValidationCode != nil {
+		conds, args = append(conds, VALIDATION_CODE+" = ?"), append(args, *filter.ValidationCode)
+	}
+	if filter.Chaincode != "" {
+		conds, args = append(conds, CHAINCODE+" = ?"), append(args, filter.Chaincode)
+	}
+	if filter.CreatorMSP != "" {
+		conds, args = append(conds, CREATOR_MSP+" = ?"), append(args, filter.CreatorMSP)
+	}
+	if filter.TxType != "" {
+		conds, args = append(conds, TX_TYPE+" = ?"), append(args, filter.TxType)
+	}
+	if filter.Key != "" {
+		conds, args = append(conds, PAYLOADKEYS+" CONTAINS ?"), append(args, filter.Key)
+	}
+
+	// key prefix and document fields can't be expressed in CQL, they are checked by filter.Match
+	return c.queryOrdered(ctx, ch, filter.FromBlock, filter.ToBlock, conds, args, filter.Page, filter.Match)
+}
+
+// fillPage fetches cassandra pages until limit rows are matched or there are no more rows. Every fetch is limited
+// to the remaining size, so the paging state of the last fetch is the exact cursor of the next page.
+func fillPage[T any](limit int, state []byte, fetch func(state []byte, size int) ([]T, []byte, error)) ([]T, string, error) {
+	var rows []T
+	for {
+		matched, next, err := fetch(state, limit-len(rows))
+		if err != nil {
+			return nil, "", err
+		}
+		rows, state = append(rows, matched...), next
+		if len(rows) >= limit || len(state) == 0 {
+			return rows, base64.RawURLEncoding.EncodeToString(state), nil
+		}
+	}
+}
+
+func (c *Cassandra) GetBlockInfoByPayloadPage(_ context.Context, _ string, _ string, _ Page) ([]Tx, string, error) {
+	return nil, "", errors.WithStack(errors.New("not implemented for cassandra"))
+}
+
+func (c *Cassandra) IterateAll(_ context.Context, ch string, page Page) (TxIterator, error) {
+	if _, err := decodeCassandraCursor(page); err != nil {
+		return nil, err
+	}
+
+	return &cassandraIterator{cursor: page.Cursor, page: func(ctx context.Context, cursor string) ([]Tx, string, error) {
+		return c.QueryAllPage(ctx, ch, Page{Cursor: cursor, Order: page.Order, Limit: DefaultPageLimit})
+	}}, nil
+}
+
+func (c *Cassandra) historyTable(ch string) string {
+	return fmt.Sprintf("%s_%s_history", ch, c.Columnfamily)
+}
+
+func (c *Cassandra) InsertKeyHistory(ctx context.Context, ch string, mods []KeyModification) error {
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", c.historyTable(ch), historyColumns)
+
+	batch := c.Session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for _, mod := range mods {
+		batch.Query(insert, mod.Namespace, mod.Key, mod.Blocknum, mod.TxNum, mod.ChannelId, mod.Txid, mod.Value, mod.IsDelete, mod.Time,
+			mod.ObjectType, mod.Attributes)
+	}
+	if batch.Size() == 0 {
+		return nil
+	}
+
+	return errors.WithStack(c.Session.ExecuteBatch(batch))
+}
+
+func (c *Cassandra) GetKeyHistory(ctx context.Context, ch, namespace, key string, page Page) ([]KeyModification, string, error) {
+	state, err := base64.RawURLEncoding.DecodeString(page.Cursor)
+	if err != nil {
+		return nil, "", InvalidCursor(err)
+	}
+
+	order := "ASC"
+	if page.Order == Descending {
+		order = "DESC"
+	}
+	sel := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s %s, %s %s",
+		historyColumns, c.historyTable(ch), NAMESPACE, KEY, BLOCKNUM, order, TXNUM, order)
+
+	return fillPage(int(page.PageLimit()), state, func(state []byte, size int) ([]KeyModification, []byte, error) {
+		return c.queryKeyHistory(c.Session.Query(sel, namespace, key).WithContext(ctx).PageSize(size).PageState(state), nil)
+	})
+}
+
+// GetCompositeKeyHistory reads modifications across partitions, so they are not ordered and the order of the page is ignored
+func (c *Cassandra) GetCompositeKeyHistory(ctx context.Context, ch, namespace, objectType string, attributes []string, page Page) ([]KeyModification, string, error) {
+	state, err := base64.RawURLEncoding.DecodeString(page.Cursor)
+	if err != nil {
+		return nil, "", InvalidCursor(err)
+	}
+
+	// attributes are checked by the client, so pages are filled by fetching more rows
+	sel := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ? AND %s = ? ALLOW FILTERING", historyColumns, c.historyTable(ch), NAMESPACE, OBJECT_TYPE)
+	match := func(mod KeyModification) bool {
+		return matchAttributes(mod.Attributes, attributes)
+	}
+
+	return fillPage(int(page.PageLimit()), state, func(state []byte, size int) ([]KeyModification, []byte, error) {
+		return c.queryKeyHistory(c.Session.Query(sel, namespace, objectType).WithContext(ctx).PageSize(size).PageState(state), match)
+	})
+}
+
+// historyColumns are columns of key history read by queryKeyHistory
+var historyColumns = strings.Join([]string{NAMESPACE, KEY, BLOCKNUM, TXNUM, CHANNEL_ID, TXID, VALUE, IS_DELETE, TIME, OBJECT_TYPE, ATTRIBUTES}, ", ")
+
+// queryKeyHistory returns matching modifications of a single page of the query and the paging state of the next page
+func (c *Cassandra) queryKeyHistory(query *gocql.Query, match func(KeyModification) bool) ([]KeyModification, []byte, error) {
+	iter := query.Iter()
+	next := iter.PageState()
+
+	var mods []KeyModification
+	sc := iter.Scanner()
+	for sc.Next() {
+		var mod KeyModification
+		if err := sc.Scan(&mod.Namespace, &mod.Key, &mod.Blocknum, &mod.TxNum, &mod.ChannelId, &mod.Txid, &mod.Value, &mod.IsDelete, &mod.Time,
+			&mod.ObjectType, &mod.Attributes); err != nil {
+			return nil, nil, err
+		}
+		if match == nil || match(mod) {
+			mods = append(mods, mod)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, errors.WithStack(errors.Wrap(err, "cassandra query error"))
+	}
+
+	return mods, next, nil
+}
+
+func (c *Cassandra) GetCheckpoint(ctx context.Context, ch, consumer string) (uint64, bool, error) {
+	var blocknum uint64
+	err := c.Session.Query(fmt.Sprintf("SELECT %s FROM checkpoints WHERE channel = ? AND consumer = ?", BLOCKNUM), ch, consumer).WithContext(ctx).Scan(&blocknum)
+	if err == gocql.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+
+	return blocknum, true, nil
+}
+
+func (c *Cassandra) SetCheckpoint(ctx context.Context, ch, consumer string, blocknum uint64) error {
+	err := c.Session.Query(fmt.Sprintf("INSERT INTO checkpoints (channel, consumer, %s) VALUES (?, ?, ?)", BLOCKNUM), ch, consumer, blocknum).WithContext(ctx).Exec()
+	return errors.WithStack(err)
+}
+
+func (c *Cassandra) blocksTable(ch string) string {
+	return fmt.Sprintf("%s_%s_blocks", ch, c.Columnfamily)
+}
+
+// PutRawBlock stores compressed block keyed by its number
+func (c *Cassandra) PutRawBlock(ctx context.Context, ch string, blocknum uint64, raw []byte) error {
+	compressed, err := compressBlock(raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to compress block")
+	}
+
+	err = c.Session.Query(fmt.Sprintf("INSERT INTO %s (%s, data) VALUES (?, ?)", c.blocksTable(ch), BLOCKNUM), blocknum, compressed).WithContext(ctx).Exec()
+	return errors.WithStack(err)
+}
+
+func (c *Cassandra) GetRawBlock(ctx context.Context, ch string, blocknum uint64) ([]byte, error) {
+	var compressed []byte
+	err := c.Session.Query(fmt.Sprintf("SELECT data FROM %s WHERE %s = ?", c.blocksTable(ch), BLOCKNUM), blocknum).WithContext(ctx).Scan(&compressed)
+	if err == gocql.ErrNotFound {
+		return nil, errors.New(NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return decompressBlock(compressed)
+}
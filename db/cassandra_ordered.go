@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+// Txs are also stored in a table partitioned by buckets of blockBucketSize blocks and clustered by block number and
+// ID, which is a time UUID of insertion, so paginated queries read txs grouped by block in the order of blocks.
+// The table of txs is partitioned by random IDs, its pages are returned in token order.
+
+// blockBucketSize is the number of blocks in a partition of the ordered table
+const blockBucketSize = 10000
+
+func (c *Cassandra) orderedTable(ch string) string {
+	return fmt.Sprintf("%s_%s_ordered", ch, c.Columnfamily)
+}
+
+func blockBucket(blocknum uint64) uint64 {
+	return blocknum / blockBucketSize
+}
+
+func (c *Cassandra) createOrderedTable(ctx context.Context, ch string) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (Bucket bigint, ID UUID, %s text, %s text, %s text, %s text, %s bigint, %s text, %s int, %s int, %s text, %s text, %s text, %s list<text>, %s text, %s bigint, PRIMARY KEY((Bucket), %s, ID)) WITH CLUSTERING ORDER BY (%s ASC, ID ASC);`,
+		c.orderedTable(ch), CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, CHAINCODE, CREATOR_MSP, TX_TYPE,
+		PAYLOADKEYS, DOCUMENTS, TXNUM, BLOCKNUM, BLOCKNUM)
+	return errors.Wrapf(c.Session.Query(query).WithContext(ctx).Exec(), "failed to create column family: %s", c.orderedTable(ch))
+}
+
+// blockPosition is a position in the ordered table, paginated queries start at the first tx of the block if id
+// is nil, otherwise after (before for descending order) the tx with the id
+type blockPosition struct {
+	blocknum uint64
+	id       *gocql.UUID
+}
+
+// state encodes the position as "<blocknum>" or "<blocknum>/<id>"
+func (p blockPosition) state() []byte {
+	state := strconv.FormatUint(p.blocknum, 10)
+	if p.id != nil {
+		state += "/" + p.id.String()
+	}
+	return []byte(state)
+}
+
+func parseBlockPosition(state []byte) (blockPosition, error) {
+	blocknum, id, hasID := strings.Cut(string(state), "/")
+	num, err := strconv.ParseUint(blocknum, 10, 64)
+	if err != nil {
+		return blockPosition{}, InvalidCursor(err)
+	}
+	pos := blockPosition{blocknum: num}
+	if hasID {
+		uuid, err := gocql.ParseUUID(id)
+		if err != nil {
+			return blockPosition{}, InvalidCursor(err)
+		}
+		pos.id = &uuid
+	}
+	return pos, nil
+}
+
+// nextBucket returns the position of the bucket following the exhausted bucket in the order of the query limited
+// by [from, to] blocks, ok is false if there are no more buckets
+func nextBucket(bucket uint64, order SortOrder, from, to uint64) (blockPosition, bool) {
+	if order == Descending {
+		if bucket == 0 || bucket*blockBucketSize <= from {
+			return blockPosition{}, false
+		}
+		return blockPosition{blocknum: bucket*blockBucketSize - 1}, true
+	}
+	next := (bucket + 1) * blockBucketSize
+	return blockPosition{blocknum: next}, next <= to
+}
+
+// queryOrdered returns a page of txs of [from, to] blocks matching conditions of non-key columns and match,
+// to is the last stored block if it's 0
+func (c *Cassandra) queryOrdered(ctx context.Context, ch string, from, to uint64, conds []string, args []interface{},
+	page Page, match func(Tx) bool) ([]Tx, string, error) {
+	state, err := decodeCassandraCursor(page)
+	if err != nil {
+		return nil, "", err
+	}
+	if to == 0 {
+		last, err := c.GetLastEntry(ctx, ch)
+		if err != nil && err.Error() == NOT_FOUND_ERR {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to get the last block")
+		}
+		to = last.Blocknum
+	}
+	if from > to {
+		return nil, "", nil
+	}
+
+	order := "ASC"
+	if page.Order == Descending {
+		order = "DESC"
+	}
+	if len(state) == 0 {
+		state = blockPosition{blocknum: from}.state()
+		if page.Order == Descending {
+			state = blockPosition{blocknum: to}.state()
+		}
+	}
+
+	return fillPage(int(page.PageLimit()), state, func(state []byte, size int) ([]Tx, []byte, error) {
+		pos, err := parseBlockPosition(state)
+		if err != nil {
+			return nil, nil, err
+		}
+		bucket := blockBucket(pos.blocknum)
+
+		where, values := []string{"Bucket = ?"}, []interface{}{bucket}
+		switch {
+		case pos.id == nil && page.Order == Descending:
+			where, values = append(where, fmt.Sprintf("(%s) <= (?)", BLOCKNUM)), append(values, pos.blocknum)
+		case pos.id == nil:
+			where, values = append(where, fmt.Sprintf("(%s) >= (?)", BLOCKNUM)), append(values, pos.blocknum)
+		case page.Order == Descending:
+			where, values = append(where, fmt.Sprintf("(%s, ID) < (?, ?)", BLOCKNUM)), append(values, pos.blocknum, *pos.id)
+		default:
+			where, values = append(where, fmt.Sprintf("(%s, ID) > (?, ?)", BLOCKNUM)), append(values, pos.blocknum, *pos.id)
+		}
+		sel := fmt.Sprintf("SELECT ID, %s FROM %s WHERE %s ORDER BY %s %s, ID %s LIMIT %d ALLOW FILTERING",
+			txColumns, c.orderedTable(ch), strings.Join(append(where, conds...), " AND "), BLOCKNUM, order, order, size)
+
+		var (
+			txs     []Tx
+			scanned int
+			last    blockPosition
+		)
+		sc := c.Session.Query(sel, append(values, args...)...).WithContext(ctx).Iter().Scanner()
+		for sc.Next() {
+			var (
+				tx Tx
+				id gocql.UUID
+			)
+			if err := scanTx(idScanner{sc: sc, id: &id}, &tx); err != nil {
+				return nil, nil, err
+			}
+			// the rest of the bucket is out of the range
+			if tx.Blocknum > to || tx.Blocknum < from {
+				return txs, nil, errors.WithStack(sc.Err())
+			}
+			scanned, last = scanned+1, blockPosition{blocknum: tx.Blocknum, id: &id}
+			if match == nil || match(tx) {
+				txs = append(txs, tx)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, nil, errors.WithStack(errors.Wrap(err, "cassandra query error"))
+		}
+
+		if scanned == size {
+			return txs, last.state(), nil
+		}
+		next, ok := nextBucket(bucket, page.Order, from, to)
+		if !ok {
+			return txs, nil, nil
+		}
+		return txs, next.state(), nil
+	})
+}
+
+// idScanner scans the ID column selected before columns scanned by the caller
+type idScanner struct {
+	sc interface{ Scan(...interface{}) error }
+	id *gocql.UUID
+}
+
+func (s idScanner) Scan(dest ...interface{}) error {
+	return s.sc.Scan(append([]interface{}{s.id}, dest...)...)
+}
+
+// insertOrdered stores the tx with the id into the ordered table
+func (c *Cassandra) insertOrdered(batch *gocql.Batch, ch string, id gocql.UUID, tx Tx, payloadkeys []string, documents string) {
+	insert := fmt.Sprintf("INSERT INTO %s (Bucket, ID, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", c.orderedTable(ch), txColumns)
+	batch.Query(insert, blockBucket(tx.Blocknum), id, tx.ChannelId, tx.Txid, tx.Hash, tx.PreviousHash,
+		tx.Blocknum, tx.Payload, tx.ValidationCode, tx.Time, tx.Chaincode, tx.CreatorMSP, tx.TxType, payloadkeys, documents, tx.TxNum)
+}
+
+// deleteOrdered deletes the tx with the id from the ordered table
+func (c *Cassandra) deleteOrdered(ctx context.Context, ch string, blocknum uint64, id gocql.UUID) error {
+	err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE Bucket = ? AND %s = ? AND ID = ?", c.orderedTable(ch), BLOCKNUM),
+		blockBucket(blocknum), blocknum, id).WithContext(ctx).Exec()
+	return errors.WithStack(err)
+}
+
+// cassandraIterator reads pages of the ordered table one by one
+type cassandraIterator struct {
+	page   func(ctx context.Context, cursor string) ([]Tx, string, error)
+	cursor string
+	done   bool
+	txs    []Tx
+	tx     Tx
+	err    error
+}
+
+func (it *cassandraIterator) Next(ctx context.Context) bool {
+	for len(it.txs) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.txs, it.cursor, it.err = it.page(ctx, it.cursor)
+		it.done = it.cursor == ""
+	}
+	it.tx, it.txs = it.txs[0], it.txs[1:]
+	return true
+}
+
+func (it *cassandraIterator) Tx() Tx {
+	return it.tx
+}
+
+func (it *cassandraIterator) Err() error {
+	return it.err
+}
+
+func (it *cassandraIterator) Close(_ context.Context) error {
+	return nil
+}
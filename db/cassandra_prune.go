@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+// Cassandra can't delete by non-key columns, so rows are selected with filtering and deleted by primary key
+
+func (c *Cassandra) PruneBlocks(ctx context.Context, ch string, before uint64) (int64, error) {
+	deleted, err := c.pruneTxs(ctx, ch, fmt.Sprintf("%s < ?", BLOCKNUM), []interface{}{before}, func(string) bool { return true })
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, c.pruneKeyHistory(ctx, ch, fmt.Sprintf("%s < ?", BLOCKNUM), []interface{}{before}, func(string) bool { return true })
+}
+
+func (c *Cassandra) PruneChaincodes(ctx context.Context, ch string, keep []string, from, to uint64) (int64, error) {
+	kept := map[string]bool{"": true}
+	for _, chaincode := range keep {
+		kept[chaincode] = true
+	}
+	prune := func(chaincode string) bool { return !kept[chaincode] }
+
+	cond := fmt.Sprintf("%s >= ? AND %s <= ?", BLOCKNUM, BLOCKNUM)
+	deleted, err := c.pruneTxs(ctx, ch, cond, []interface{}{from, to}, prune)
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, c.pruneKeyHistory(ctx, ch, cond, []interface{}{from, to}, prune)
+}
+
+func (c *Cassandra) LastBlockBefore(ctx context.Context, ch string, t time.Time) (uint64, bool, error) {
+	var blocknum *int64
+	err := c.Session.Query(fmt.Sprintf("SELECT max(%s) FROM %s WHERE %s < ? ALLOW FILTERING", BLOCKNUM, fmt.Sprintf("%s_%s", ch, c.Columnfamily), TIME),
+		t.Unix()).WithContext(ctx).Scan(&blocknum)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	if blocknum == nil {
+		return 0, false, nil
+	}
+
+	return uint64(*blocknum), true, nil
+}
+
+func (c *Cassandra) pruneTxs(ctx context.Context, ch, cond string, values []interface{}, prune func(chaincode string) bool) (int64, error) {
+	table := fmt.Sprintf("%s_%s", ch, c.Columnfamily)
+	sc := c.Session.Query(fmt.Sprintf("SELECT ID, %s, %s FROM %s WHERE %s ALLOW FILTERING", BLOCKNUM, CHAINCODE, table, cond), values...).
+		WithContext(ctx).Iter().Scanner()
+
+	var deleted int64
+	for sc.Next() {
+		var (
+			id        gocql.UUID
+			blocknum  int64
+			chaincode string
+		)
+		if err := sc.Scan(&id, &blocknum, &chaincode); err != nil {
+			return deleted, errors.WithStack(err)
+		}
+		if !prune(chaincode) {
+			continue
+		}
+		err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE ID = ? AND %s = ?", table, BLOCKNUM), id, blocknum).WithContext(ctx).Exec()
+		if err != nil {
+			return deleted, errors.WithStack(err)
+		}
+		if err = c.deleteOrdered(ctx, ch, uint64(blocknum), id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, errors.WithStack(sc.Err())
+}
+
+func (c *Cassandra) pruneKeyHistory(ctx context.Context, ch, cond string, values []interface{}, prune func(namespace string) bool) error {
+	sc := c.Session.Query(fmt.Sprintf("SELECT %s, %s, %s, %s FROM %s WHERE %s ALLOW FILTERING", NAMESPACE, KEY, BLOCKNUM, TXNUM, c.historyTable(ch), cond), values...).
+		WithContext(ctx).Iter().Scanner()
+
+	for sc.Next() {
+		var (
+			namespace, key  string
+			blocknum, txnum int64
+		)
+		if err := sc.Scan(&namespace, &key, &blocknum, &txnum); err != nil {
+			return errors.WithStack(err)
+		}
+		if !prune(namespace) {
+			continue
+		}
+		err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s = ? AND %s = ? AND %s = ?", c.historyTable(ch), NAMESPACE, KEY, BLOCKNUM, TXNUM),
+			namespace, key, blocknum, txnum).WithContext(ctx).Exec()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(sc.Err())
+}
+
+func (c *Cassandra) PruneRawBlocks(ctx context.Context, ch string, before uint64) error {
+	sc := c.Session.Query(fmt.Sprintf("SELECT %s FROM %s WHERE %s < ? ALLOW FILTERING", BLOCKNUM, c.blocksTable(ch), BLOCKNUM), before).
+		WithContext(ctx).Iter().Scanner()
+
+	for sc.Next() {
+		var blocknum int64
+		if err := sc.Scan(&blocknum); err != nil {
+			return errors.WithStack(err)
+		}
+		err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", c.blocksTable(ch), BLOCKNUM), blocknum).WithContext(ctx).Exec()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(sc.Err())
+}
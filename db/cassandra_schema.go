@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+func (c *Cassandra) SchemaVersion(ctx context.Context, ch string) (int, error) {
+	var version int
+	err := c.Session.Query("SELECT version FROM schema_versions WHERE channel = ?", ch).WithContext(ctx).Scan(&version)
+	if err == gocql.ErrNotFound {
+		return 0, nil
+	}
+
+	return version, errors.WithStack(err)
+}
+
+func (c *Cassandra) SetSchemaVersion(ctx context.Context, ch string, version int) error {
+	return errors.WithStack(c.Session.Query("UPDATE schema_versions SET version = ? WHERE channel = ?", version, ch).WithContext(ctx).Exec())
+}
+
+func (c *Cassandra) Migrations() []Migration {
+	return []Migration{
+		{Version: 1, Description: "tx attributes, documents and composite key columns", Up: c.addColumns},
+		{Version: 2, Description: "composite key fields of key history", Up: c.splitCompositeKeys},
+		{Version: 3, Description: "txs ordered by block", Up: c.copyOrdered},
+		{Version: 4, Description: "positions of txs in blocks", Up: c.addTxNum},
+	}
+}
+
+// addColumns adds columns to tables created before the columns were introduced, CREATE TABLE IF NOT EXISTS
+// doesn't change existing tables. Object type index is created here, because old tables don't have the column before.
+func (c *Cassandra) addColumns(ctx context.Context, ch string) error {
+	columns := map[string][]string{
+		fmt.Sprintf("%s_%s", ch, c.Columnfamily): {CHAINCODE + " text", CREATOR_MSP + " text", TX_TYPE + " text", DOCUMENTS + " text"},
+		c.historyTable(ch):                       {OBJECT_TYPE + " text", ATTRIBUTES + " list<text>"},
+	}
+	for table, defs := range columns {
+		for _, def := range defs {
+			err := c.Session.Query(fmt.Sprintf("ALTER TABLE %s ADD %s", table, def)).WithContext(ctx).Exec()
+			if err != nil && !strings.Contains(err.Error(), "conflicts with an existing column") {
+				return errors.Wrapf(err, "failed to add column %s to %s", def, table)
+			}
+		}
+	}
+
+	indexObjectType := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS ON %s(%s);`, c.historyTable(ch), OBJECT_TYPE)
+	return errors.Wrapf(c.Session.Query(indexObjectType).WithContext(ctx).Exec(), "failed to create index: %s", c.historyTable(ch))
+}
+
+// copyOrdered copies txs stored before the ordered table was created. Columns added by later migrations
+// aren't copied, the tables may not have them yet.
+func (c *Cassandra) copyOrdered(ctx context.Context, ch string) error {
+	columns := []string{CHANNEL_ID, TXID, HASH, PREVIOUS_HASH, BLOCKNUM, PAYLOAD, VALIDATION_CODE, TIME, CHAINCODE, CREATOR_MSP,
+		TX_TYPE, PAYLOADKEYS, DOCUMENTS}
+	sc := c.Session.Query(fmt.Sprintf("SELECT ID, %s FROM %s_%s", strings.Join(columns, ", "), ch, c.Columnfamily)).
+		WithContext(ctx).Iter().Scanner()
+	insert := fmt.Sprintf("INSERT INTO %s (Bucket, ID, %s) VALUES (?, ?%s)", c.orderedTable(ch), strings.Join(columns, ", "),
+		strings.Repeat(", ?", len(columns)))
+
+	for sc.Next() {
+		var (
+			id          gocql.UUID
+			tx          Tx
+			payloadkeys []string
+			documents   string
+		)
+		if err := sc.Scan(&id, &tx.ChannelId, &tx.Txid, &tx.Hash, &tx.PreviousHash, &tx.Blocknum, &tx.Payload, &tx.ValidationCode,
+			&tx.Time, &tx.Chaincode, &tx.CreatorMSP, &tx.TxType, &payloadkeys, &documents); err != nil {
+			return errors.WithStack(err)
+		}
+		err := c.Session.Query(insert, blockBucket(tx.Blocknum), id, tx.ChannelId, tx.Txid, tx.Hash, tx.PreviousHash, tx.Blocknum,
+			tx.Payload, tx.ValidationCode, tx.Time, tx.Chaincode, tx.CreatorMSP, tx.TxType, payloadkeys, documents).WithContext(ctx).Exec()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(sc.Err())
+}
+
+// addTxNum adds the position of txs to tables created before it was stored, it's 0 for stored txs
+func (c *Cassandra) addTxNum(ctx context.Context, ch string) error {
+	for _, table := range []string{fmt.Sprintf("%s_%s", ch, c.Columnfamily), c.orderedTable(ch)} {
+		err := c.Session.Query(fmt.Sprintf("ALTER TABLE %s ADD %s bigint", table, TXNUM)).WithContext(ctx).Exec()
+		if err != nil && !strings.Contains(err.Error(), "conflicts with an existing column") {
+			return errors.Wrapf(err, "failed to add column %s to %s", TXNUM, table)
+		}
+	}
+	return nil
+}
+
+// splitCompositeKeys fills object type and attributes of composite keys stored before they were split
+func (c *Cassandra) splitCompositeKeys(ctx context.Context, ch string) error {
+	iter := c.Session.Query(fmt.Sprintf("SELECT %s, %s, %s, %s, %s FROM %s", NAMESPACE, KEY, BLOCKNUM, TXNUM, OBJECT_TYPE, c.historyTable(ch))).
+		WithContext(ctx).Iter()
+	update := fmt.Sprintf("UPDATE %s SET %s = ?, %s = ? WHERE %s = ? AND %s = ? AND %s = ? AND %s = ?",
+		c.historyTable(ch), OBJECT_TYPE, ATTRIBUTES, NAMESPACE, KEY, BLOCKNUM, TXNUM)
+
+	var (
+		mod        KeyModification
+		objectType string
+	)
+	for iter.Scan(&mod.Namespace, &mod.Key, &mod.Blocknum, &mod.TxNum, &objectType) {
+		if objectType != "" {
+			continue
+		}
+		ot, attributes, ok := SplitCompositeKey(mod.Key)
+		if !ok {
+			continue
+		}
+		if err := c.Session.Query(update, ot, attributes, mod.Namespace, mod.Key, mod.Blocknum, mod.TxNum).WithContext(ctx).Exec(); err != nil {
+			iter.Close()
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(iter.Close())
+}
+
+// ReplaceBlock inserts txs of the block before deleting replaced rows, so the block stays readable.
+// Rows have generated IDs, so all previous rows of the block are replaced.
+func (c *Cassandra) ReplaceBlock(ctx context.Context, ch string, blocknum uint64, txs []Tx, history []KeyModification) error {
+	table := fmt.Sprintf("%s_%s", ch, c.Columnfamily)
+	inserted := make(map[gocql.UUID]bool, len(txs))
+	var last gocql.UUID
+	for _, tx := range txs {
+		id, err := c.insertTx(ctx, ch, tx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store tx %s", tx.Txid)
+		}
+		inserted[id], last = true, id
+	}
+
+	sc := c.Session.Query(fmt.Sprintf("SELECT ID FROM %s WHERE %s = ? ALLOW FILTERING", table, BLOCKNUM), blocknum).WithContext(ctx).Iter().Scanner()
+	for sc.Next() {
+		var id gocql.UUID
+		if err := sc.Scan(&id); err != nil {
+			return errors.WithStack(err)
+		}
+		if inserted[id] {
+			continue
+		}
+		if err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE ID = ? AND %s = ?", table, BLOCKNUM), id, blocknum).WithContext(ctx).Exec(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	sc = c.Session.Query(fmt.Sprintf("SELECT ID FROM %s WHERE Bucket = ? AND %s = ?", c.orderedTable(ch), BLOCKNUM), blockBucket(blocknum), blocknum).
+		WithContext(ctx).Iter().Scanner()
+	for sc.Next() {
+		var id gocql.UUID
+		if err := sc.Scan(&id); err != nil {
+			return errors.WithStack(err)
+		}
+		if inserted[id] {
+			continue
+		}
+		if err := c.deleteOrdered(ctx, ch, blocknum, id); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the last entry points to a deleted row if the last block is replaced
+	var max int64
+	err := c.Session.Query(fmt.Sprintf("SELECT blocknum FROM MAX_%s WHERE fortable = ?", ch), table).WithContext(ctx).Scan(&max)
+	if err != nil && err != gocql.ErrNotFound {
+		return errors.WithStack(err)
+	}
+	if err == nil && uint64(max) == blocknum && len(txs) != 0 {
+		if err = c.UpdateMax(ctx, ch, last, blocknum, txs[len(txs)-1].Hash); err != nil {
+			return err
+		}
+	}
+
+	if err = c.InsertKeyHistory(ctx, ch, history); err != nil {
+		return errors.Wrap(err, "failed to store key history")
+	}
+	type version struct {
+		namespace, key string
+		txnum          int64
+	}
+	kept := make(map[version]bool, len(history))
+	for _, mod := range history {
+		kept[version{mod.Namespace, mod.Key, int64(mod.TxNum)}] = true
+	}
+	return c.pruneKeyHistoryVersions(ctx, ch, blocknum, func(namespace, key string, txnum int64) bool {
+		return !kept[version{namespace, key, txnum}]
+	})
+}
+
+// pruneKeyHistoryVersions deletes key versions of the block selected by prune
+func (c *Cassandra) pruneKeyHistoryVersions(ctx context.Context, ch string, blocknum uint64, prune func(namespace, key string, txnum int64) bool) error {
+	sc := c.Session.Query(fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE %s = ? ALLOW FILTERING", NAMESPACE, KEY, TXNUM, c.historyTable(ch), BLOCKNUM), blocknum).
+		WithContext(ctx).Iter().Scanner()
+
+	for sc.Next() {
+		var (
+			namespace, key string
+			txnum          int64
+		)
+		if err := sc.Scan(&namespace, &key, &txnum); err != nil {
+			return errors.WithStack(err)
+		}
+		if !prune(namespace, key, txnum) {
+			continue
+		}
+		err := c.Session.Query(fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s = ? AND %s = ? AND %s = ?", c.historyTable(ch), NAMESPACE, KEY, BLOCKNUM, TXNUM),
+			namespace, key, blocknum, txnum).WithContext(ctx).Exec()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(sc.Err())
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillPage(t *testing.T) {
+	// rows 0..9, odd rows match, paging state is the position of the next row
+	fetch := func(state []byte, size int) ([]Tx, []byte, error) {
+		pos := 0
+		if len(state) != 0 {
+			pos = int(state[0])
+		}
+		var txs []Tx
+		end := pos + size
+		if end > 10 {
+			end = 10
+		}
+		for ; pos < end; pos++ {
+			if pos%2 == 1 {
+				txs = append(txs, Tx{Blocknum: uint64(pos)})
+			}
+		}
+		if pos == 10 {
+			return txs, nil, nil
+		}
+		return txs, []byte{byte(pos)}, nil
+	}
+
+	txs, cursor, err := fillPage(3, nil, fetch)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+	assert.Equal(t, []uint64{1, 3, 5}, []uint64{txs[0].Blocknum, txs[1].Blocknum, txs[2].Blocknum})
+
+	state, err := base64.RawURLEncoding.DecodeString(cursor)
+	require.NoError(t, err)
+	txs, cursor, err = fillPage(3, state, fetch)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	assert.Equal(t, []uint64{7, 9}, []uint64{txs[0].Blocknum, txs[1].Blocknum})
+	assert.Empty(t, cursor)
+}
+
+func TestFillPageCompositeKeyHistory(t *testing.T) {
+	// rows 0..9 have the row number modulo 6 as the attribute, rows 2 and 8 match, paging state is the position of the next row
+	fetch := func(state []byte, size int) ([]KeyModification, []byte, error) {
+		pos := 0
+		if len(state) != 0 {
+			pos = int(state[0])
+		}
+		var mods []KeyModification
+		for end := pos + size; pos < end && pos < 10; pos++ {
+			if matchAttributes([]string{strconv.Itoa(pos % 6)}, []string{"2"}) {
+				mods = append(mods, KeyModification{Blocknum: uint64(pos)})
+			}
+		}
+		if pos == 10 {
+			return mods, nil, nil
+		}
+		return mods, []byte{byte(pos)}, nil
+	}
+
+	mods, cursor, err := fillPage(1, nil, fetch)
+	require.NoError(t, err)
+	require.Len(t, mods, 1)
+	assert.EqualValues(t, 2, mods[0].Blocknum)
+	require.NotEmpty(t, cursor)
+
+	state, err := base64.RawURLEncoding.DecodeString(cursor)
+	require.NoError(t, err)
+	mods, cursor, err = fillPage(2, state, fetch)
+	require.NoError(t, err)
+	require.Len(t, mods, 1)
+	assert.EqualValues(t, 8, mods[0].Blocknum)
+	assert.Empty(t, cursor)
+}
+
+func TestBlockPosition(t *testing.T) {
+	id := gocql.TimeUUID()
+	for _, pos := range []blockPosition{{blocknum: 7}, {blocknum: 7, id: &id}} {
+		parsed, err := parseBlockPosition(pos.state())
+		require.NoError(t, err)
+		assert.Equal(t, pos, parsed)
+	}
+	_, err := parseBlockPosition([]byte("7/abc"))
+	assert.Error(t, err)
+
+	// buckets are read in the order of the query until the range ends
+	next, ok := nextBucket(0, Ascending, 0, 25000)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10000), next.blocknum)
+	_, ok = nextBucket(2, Ascending, 0, 25000)
+	assert.False(t, ok)
+	next, ok = nextBucket(2, Descending, 5000, 25000)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(19999), next.blocknum)
+	_, ok = nextBucket(0, Descending, 0, 25000)
+	assert.False(t, ok)
+	_, ok = nextBucket(1, Descending, 10000, 25000)
+	assert.False(t, ok)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+// subscription filters are stored as JSON text, dead letters are partitioned by subscription
+
+func (c *Cassandra) initWebhooks(ctx context.Context) error {
+	subscriptions := "CREATE TABLE IF NOT EXISTS subscriptions (id text PRIMARY KEY, channel text, url text, secret text, filter text, created bigint);"
+	if err := c.Session.Query(subscriptions).WithContext(ctx).Exec(); err != nil {
+		return errors.Wrap(err, "failed to create column family: subscriptions")
+	}
+
+	deadLetters := fmt.Sprintf("CREATE TABLE IF NOT EXISTS deadletters (subscription text, id text, channel text, %s bigint, payload blob, error text, attempts int, %s bigint, PRIMARY KEY(subscription, id));",
+		BLOCKNUM, TIME)
+	if err := c.Session.Query(deadLetters).WithContext(ctx).Exec(); err != nil {
+		return errors.Wrap(err, "failed to create column family: deadletters")
+	}
+	return nil
+}
+
+func (c *Cassandra) PutSubscription(ctx context.Context, sub Subscription) error {
+	filter, err := json.Marshal(sub.Filter)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal subscription filter")
+	}
+	err = c.Session.Query("INSERT INTO subscriptions (id, channel, url, secret, filter, created) VALUES (?, ?, ?, ?, ?, ?)",
+		sub.ID, sub.Channel, sub.URL, sub.Secret, string(filter), sub.Created).WithContext(ctx).Exec()
+	return errors.WithStack(err)
+}
+
+func (c *Cassandra) GetSubscription(ctx context.Context, id string) (Subscription, error) {
+	subs, err := c.querySubscriptions(c.Session.Query("SELECT id, channel, url, secret, filter, created FROM subscriptions WHERE id = ?", id).WithContext(ctx))
+	if err != nil {
+		return Subscription{}, err
+	}
+	if len(subs) == 0 {
+		return Subscription{}, errors.New(NOT_FOUND_ERR)
+	}
+	return subs[0], nil
+}
+
+func (c *Cassandra) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subs, err := c.querySubscriptions(c.Session.Query("SELECT id, channel, url, secret, filter, created FROM subscriptions").WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].Created != subs[j].Created {
+			return subs[i].Created < subs[j].Created
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs, nil
+}
+
+func (c *Cassandra) querySubscriptions(query *gocql.Query) ([]Subscription, error) {
+	subs := []Subscription{}
+	sc := query.Iter().Scanner()
+	for sc.Next() {
+		var (
+			sub    Subscription
+			filter string
+		)
+		if err := sc.Scan(&sub.ID, &sub.Channel, &sub.URL, &sub.Secret, &filter, &sub.Created); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := json.Unmarshal([]byte(filter), &sub.Filter); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal filter of subscription %s", sub.ID)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, errors.WithStack(sc.Err())
+}
+
+func (c *Cassandra) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := c.GetSubscription(ctx, id); err != nil {
+		return err
+	}
+	if err := c.Session.Query("DELETE FROM subscriptions WHERE id = ?", id).WithContext(ctx).Exec(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(c.Session.Query("DELETE FROM deadletters WHERE subscription = ?", id).WithContext(ctx).Exec())
+}
+
+func (c *Cassandra) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	err := c.Session.Query(fmt.Sprintf("INSERT INTO deadletters (subscription, id, channel, %s, payload, error, attempts, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", BLOCKNUM, TIME),
+		letter.SubscriptionID, letter.ID, letter.Channel, letter.Blocknum, letter.Payload, letter.Error, letter.Attempts, letter.Time).WithContext(ctx).Exec()
+	return errors.WithStack(err)
+}
+
+func (c *Cassandra) ListDeadLetters(ctx context.Context, subscriptionID string) ([]DeadLetter, error) {
+	return c.queryDeadLetters(c.Session.Query(fmt.Sprintf("SELECT subscription, id, channel, %s, payload, error, attempts, %s FROM deadletters WHERE subscription = ?", BLOCKNUM, TIME),
+		subscriptionID).WithContext(ctx))
+}
+
+func (c *Cassandra) queryDeadLetters(query *gocql.Query) ([]DeadLetter, error) {
+	letters := []DeadLetter{}
+	sc := query.Iter().Scanner()
+	for sc.Next() {
+		var letter DeadLetter
+		if err := sc.Scan(&letter.SubscriptionID, &letter.ID, &letter.Channel, &letter.Blocknum, &letter.Payload, &letter.Error, &letter.Attempts, &letter.Time); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sort.SliceStable(letters, func(i, j int) bool { return letters[i].Time < letters[j].Time })
+	return letters, nil
+}
+
+func (c *Cassandra) DeleteDeadLetter(ctx context.Context, subscriptionID, id string) error {
+	letters, err := c.queryDeadLetters(c.Session.Query(fmt.Sprintf("SELECT subscription, id, channel, %s, payload, error, attempts, %s FROM deadletters WHERE subscription = ? AND id = ?", BLOCKNUM, TIME),
+		subscriptionID, id).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if len(letters) == 0 {
+		return errors.New(NOT_FOUND_ERR)
+	}
+	return errors.WithStack(c.Session.Query("DELETE FROM deadletters WHERE subscription = ? AND id = ?", subscriptionID, id).WithContext(ctx).Exec())
+}
@@ -0,0 +1,33 @@
+package db
+
+import "strings"
+
+// composite keys created by CreateCompositeKey (shim or helpers) start with the namespace,
+// every component of the key is terminated by the separator
+const (
+	compositeKeyNamespace = "\x00"
+	compositeKeySeparator = "\x00"
+)
+
+// SplitCompositeKey splits composite key into object type and attributes, ok is false for simple keys
+func SplitCompositeKey(key string) (objectType string, attributes []string, ok bool) {
+	if len(key) < 2 || !strings.HasPrefix(key, compositeKeyNamespace) || !strings.HasSuffix(key, compositeKeySeparator) {
+		return "", nil, false
+	}
+
+	components := strings.Split(key[len(compositeKeyNamespace):len(key)-len(compositeKeySeparator)], compositeKeySeparator)
+	return components[0], components[1:], true
+}
+
+// matchAttributes checks whether attributes start with the partial attributes
+func matchAttributes(attributes, partial []string) bool {
+	if len(partial) > len(attributes) {
+		return false
+	}
+	for i, attr := range partial {
+		if attributes[i] != attr {
+			return false
+		}
+	}
+	return true
+}
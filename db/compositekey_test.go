@@ -0,0 +1,33 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSplitCompositeKey(t *testing.T) {
+	objectType, attributes, ok := SplitCompositeKey("\x00owner~car\x00Tomoko\x00CAR1\x00")
+	assert.True(t, ok)
+	assert.Equal(t, "owner~car", objectType)
+	assert.Equal(t, []string{"Tomoko", "CAR1"}, attributes)
+
+	objectType, attributes, ok = SplitCompositeKey("\x00owner~car\x00")
+	assert.True(t, ok)
+	assert.Equal(t, "owner~car", objectType)
+	assert.Empty(t, attributes)
+
+	for _, key := range []string{"CAR1", "", "\x00", "\x00owner~car"} {
+		_, _, ok = SplitCompositeKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestCompositeKeyFilter(t *testing.T) {
+	assert.Equal(t, bson.M{"Namespace": "fabcar", "ObjectType": "owner~car", "Attributes.0": "Tomoko"},
+		compositeKeyFilter("fabcar", "owner~car", []string{"Tomoko"}))
+	assert.True(t, matchAttributes([]string{"Tomoko", "CAR1"}, []string{"Tomoko"}))
+	assert.False(t, matchAttributes([]string{"Tomoko"}, []string{"Tomoko", "CAR1"}))
+	assert.False(t, matchAttributes([]string{"Brad", "CAR1"}, []string{"Tomoko"}))
+}
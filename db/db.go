@@ -17,35 +17,194 @@
 // Package db provides database interface for storing and retrieving blocks and transactions
 package db
 
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
 const NOT_FOUND_ERR = "not found"
 
-// Storage db interface
+// Storage db interface. All methods honour cancellation and deadlines of the passed context.
 type Storage interface {
-	Connect() error
-	Init(channel string) error
-	Insert(channel string, tx Tx) error
-	QueryBlockByHash(channel, hash string) ([]Tx, error)
-	GetByTxId(channel, txid string) ([]Tx, error)
-	GetByBlocknum(channel string, blocknum uint64) ([]Tx, error)
-	GetBlockInfoByPayload(channel, payload string) ([]Tx, error)
-	QueryAll(channel string) ([]Tx, error)
-	GetLastEntry(channel string) (Tx, error)
+	Connect(ctx context.Context) error
+	Init(ctx context.Context, channel string) error
+	Insert(ctx context.Context, channel string, tx Tx) error
+	QueryBlockByHash(ctx context.Context, channel, hash string) ([]Tx, error)
+	GetByTxId(ctx context.Context, channel, txid string) ([]Tx, error)
+	GetByBlocknum(ctx context.Context, channel string, blocknum uint64) ([]Tx, error)
+	GetBlockInfoByPayload(ctx context.Context, channel, payload string) ([]Tx, error)
+	QueryAll(ctx context.Context, channel string) ([]Tx, error)
+	GetLastEntry(ctx context.Context, channel string) (Tx, error)
+	// QueryAllPage returns a page of channel txs and a cursor of the next page (empty if there are no more txs)
+	QueryAllPage(ctx context.Context, channel string, page Page) ([]Tx, string, error)
+	// GetBlockInfoByPayloadPage is a paginated version of GetBlockInfoByPayload
+	GetBlockInfoByPayloadPage(ctx context.Context, channel, payload string, page Page) ([]Tx, string, error)
+	// IterateAll streams channel txs starting from page cursor, page limit is ignored
+	IterateAll(ctx context.Context, channel string, page Page) (TxIterator, error)
+	// Query returns a page of txs matching the filter and a cursor of the next page
+	Query(ctx context.Context, channel string, filter Filter) ([]Tx, string, error)
+	// InsertKeyHistory adds key modifications to the key history index
+	InsertKeyHistory(ctx context.Context, channel string, mods []KeyModification) error
+	// GetKeyHistory returns a page of key modifications ordered by block and tx number
+	GetKeyHistory(ctx context.Context, channel, namespace, key string, page Page) ([]KeyModification, string, error)
+	// GetCompositeKeyHistory returns a page of modifications of composite keys with the object type and leading attributes,
+	// like GetStateByPartialCompositeKey but over key history
+	GetCompositeKeyHistory(ctx context.Context, channel, namespace, objectType string, attributes []string, page Page) ([]KeyModification, string, error)
+	// GetCheckpoint returns the last block processed by the consumer, ok is false if consumer has not processed any block
+	GetCheckpoint(ctx context.Context, channel, consumer string) (blocknum uint64, ok bool, err error)
+	// SetCheckpoint saves the last block processed by the consumer
+	SetCheckpoint(ctx context.Context, channel, consumer string, blocknum uint64) error
+	SubscriptionStore
+	// Close releases database connections, waiting for in-flight operations until ctx is done
+	Close(ctx context.Context) error
+}
+
+const (
+	// DefaultPageLimit is used for paginated queries without explicit limit
+	DefaultPageLimit = 100
+	// MaxPageLimit is the biggest number of txs returned by a single paginated query
+	MaxPageLimit = 1000
+)
+
+// SortOrder defines order of txs in query results
+type SortOrder int
+
+const (
+	// Ascending order sorts txs from the oldest block to the newest
+	Ascending SortOrder = iota
+	// Descending order sorts txs from the newest block to the oldest
+	Descending
+)
+
+// Page describes which part of query results should be returned
+type Page struct {
+	// Limit is the max number of txs in the page, DefaultPageLimit is used if not positive
+	Limit int64
+	// Cursor is an opaque position returned with the previous page, empty cursor means the first page
+	Cursor string
+	Order  SortOrder
+}
+
+// invalidCursor marks errors of cursors which can't be decoded
+type invalidCursor struct {
+	error
+}
+
+// InvalidCursor wraps the error of decoding a cursor, IsInvalidCursor reports such errors
+func InvalidCursor(err error) error {
+	return invalidCursor{errors.Wrap(err, "invalid cursor")}
+}
+
+// IsInvalidCursor checks if the error is caused by a cursor which can't be decoded
+func IsInvalidCursor(err error) bool {
+	var target invalidCursor
+	return errors.As(err, &target)
+}
+
+// PageLimit returns page limit adjusted to [1, MaxPageLimit] range
+func (p Page) PageLimit() int64 {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageLimit
+	case p.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return p.Limit
+	}
+}
+
+// TxIterator iterates over query results without loading all of them into memory
+type TxIterator interface {
+	// Next prepares the next tx for reading with Tx method, it returns false when iteration is finished or failed
+	Next(ctx context.Context) bool
+	Tx() Tx
+	Err() error
+	Close(ctx context.Context) error
 }
 
 // Tx stores info about block and tx payload
 type Tx struct {
-	ChannelId      string `json:"channelid" bson:"ChannelId"`
-	Txid           string `json:"txid" bson:"Txid"`
-	Hash           string `json:"hash" bson:"Hash"`
-	PreviousHash   string `json:"previoushash" bson:"PreviousHash"`
-	Blocknum       uint64 `json:"blocknum" bson:"Blocknum"`
+	ChannelId    string `json:"channelid" bson:"ChannelId"`
+	Txid         string `json:"txid" bson:"Txid"`
+	Hash         string `json:"hash" bson:"Hash"`
+	PreviousHash string `json:"previoushash" bson:"PreviousHash"`
+	Blocknum     uint64 `json:"blocknum" bson:"Blocknum"`
+	// TxNum is the position of the tx in the block
+	TxNum          uint64 `json:"txnum" bson:"TxNum"`
+	Payload        []byte `json:"payload" bson:"Payload"`
+	ValidationCode int32  `json:"validationcode" bson:"ValidationCode"`
+	Time           int64  `json:"time" bson:"Time"`
+	// Chaincode is the namespace of the write set, empty for config txs
+	Chaincode string `json:"chaincode" bson:"Chaincode"`
+	// CreatorMSP is the MSP ID of the tx creator
+	CreatorMSP string `json:"creatormsp" bson:"CreatorMSP"`
+	// TxType is the name of the channel header type, e.g. ENDORSER_TRANSACTION or CONFIG
+	TxType string `json:"txtype" bson:"TxType"`
+	// Keys are keys of the write set
+	Keys []string `json:"keys" bson:"Keys"`
+	// Documents are write values decoded from JSON, they are stored natively to be queried by fields
+	Documents []Document `json:"documents,omitempty" bson:"Documents,omitempty"`
+}
+
+// ChaincodeEvent is the event set by the chaincode of the tx
+type ChaincodeEvent struct {
+	ChannelId string `json:"channelid" bson:"ChannelId"`
+	Txid      string `json:"txid" bson:"Txid"`
+	Blocknum  uint64 `json:"blocknum" bson:"Blocknum"`
+	// TxNum is the position of the tx in the block
+	TxNum          uint64 `json:"txnum" bson:"TxNum"`
+	Chaincode      string `json:"chaincode" bson:"Chaincode"`
+	Name           string `json:"name" bson:"Name"`
 	Payload        []byte `json:"payload" bson:"Payload"`
 	ValidationCode int32  `json:"validationcode" bson:"ValidationCode"`
 	Time           int64  `json:"time" bson:"Time"`
 }
 
+// StateStore keeps world state materialized from valid writes, it's implemented by backends supporting state queries
+type StateStore interface {
+	// ApplyState puts written values to the state and removes deleted keys, writes must be ordered
+	ApplyState(ctx context.Context, channel string, writes []KeyModification) error
+	// GetState returns the current version of the key, NOT_FOUND_ERR is returned for missing and deleted keys
+	GetState(ctx context.Context, channel, namespace, key string) (KeyModification, error)
+	// GetStateAt returns the version of the key as of the block, NOT_FOUND_ERR is returned for missing and deleted keys
+	GetStateAt(ctx context.Context, channel, namespace, key string, blocknum uint64) (KeyModification, error)
+	// ScanState returns a page of keys ordered by key and a cursor of the next page
+	ScanState(ctx context.Context, channel, namespace string, scan StateScan) ([]KeyModification, string, error)
+}
+
+// StateScan selects keys of the namespace, all specified conditions are combined
+type StateScan struct {
+	// StartKey is the first key of the range (inclusive)
+	StartKey string
+	// EndKey is the end of the range (exclusive), empty means no upper bound
+	EndKey string
+	Prefix string
+	// AsOfBlock selects state as of the block instead of the current state if it's not nil
+	AsOfBlock *uint64
+	Page
+}
+
+// KeyModification stores a single version of world state key written by a valid tx
+type KeyModification struct {
+	ChannelId string `json:"channelid" bson:"ChannelId"`
+	// Namespace is the chaincode name
+	Namespace string `json:"namespace" bson:"Namespace"`
+	Key       string `json:"key" bson:"Key"`
+	Blocknum  uint64 `json:"blocknum" bson:"Blocknum"`
+	// TxNum is the position of the tx in the block
+	TxNum    uint64 `json:"txnum" bson:"TxNum"`
+	Txid     string `json:"txid" bson:"Txid"`
+	Value    []byte `json:"value" bson:"Value"`
+	IsDelete bool   `json:"isdelete" bson:"IsDelete"`
+	Time     int64  `json:"time" bson:"Time"`
+	// ObjectType and Attributes are components of the composite key, they are empty for simple keys
+	ObjectType string   `json:"objecttype,omitempty" bson:"ObjectType,omitempty"`
+	Attributes []string `json:"attributes,omitempty" bson:"Attributes,omitempty"`
+}
+
 // RW stores key and value of chaincode payload
 type RW struct {
-	Key   string
-	Value string
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
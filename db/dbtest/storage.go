@@ -0,0 +1,543 @@
+// Package dbtest provides in-memory storage for tests of packages using db.Storage
+package dbtest
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+)
+
+// Storage keeps txs, key history, world state, checkpoints, raw blocks and webhook subscriptions in memory.
+// It implements db.Storage, db.StateStore, db.RawBlockArchive, db.Pruner and db.BlockReplacer, results are ordered
+// the same way as databases order them and cursors are offsets of the next page. It's safe for concurrent use.
+type Storage struct {
+	mu          sync.Mutex
+	txs         map[string][]db.Tx
+	history     map[string][]db.KeyModification
+	state       map[string]map[string]db.KeyModification
+	checkpoints map[string]uint64
+	raw         map[string]map[uint64][]byte
+	subs        map[string]db.Subscription
+	letters     []db.DeadLetter
+}
+
+func New() *Storage {
+	return &Storage{
+		txs:         make(map[string][]db.Tx),
+		history:     make(map[string][]db.KeyModification),
+		state:       make(map[string]map[string]db.KeyModification),
+		checkpoints: make(map[string]uint64),
+		raw:         make(map[string]map[uint64][]byte),
+		subs:        make(map[string]db.Subscription),
+	}
+}
+
+// Add stores txs of the channel, it panics if payload of a tx isn't valid
+func (s *Storage) Add(ch string, txs ...db.Tx) {
+	for _, tx := range txs {
+		if err := s.Insert(context.Background(), ch, tx); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s *Storage) Connect(_ context.Context) error {
+	return nil
+}
+
+func (s *Storage) Init(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *Storage) Close(_ context.Context) error {
+	return nil
+}
+
+// Insert keeps txs ordered by block and tx number, keys of the write set are restored from payload like databases do
+func (s *Storage) Insert(_ context.Context, ch string, tx db.Tx) error {
+	if tx.Keys == nil && len(tx.Payload) != 0 {
+		var rws []db.RW
+		if err := json.Unmarshal(tx.Payload, &rws); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal payload of tx %s", tx.Txid)
+		}
+		for _, rw := range rws {
+			tx.Keys = append(tx.Keys, rw.Key)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs := s.txs[ch]
+	i := sort.Search(len(txs), func(i int) bool {
+		return txs[i].Blocknum > tx.Blocknum || (txs[i].Blocknum == tx.Blocknum && txs[i].TxNum > tx.TxNum)
+	})
+	s.txs[ch] = append(txs[:i], append([]db.Tx{tx}, txs[i:]...)...)
+	return nil
+}
+
+// find returns txs of the channel matching the condition in storage order
+func (s *Storage) find(ch string, match func(db.Tx) bool) []db.Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var txs []db.Tx
+	for _, tx := range s.txs[ch] {
+		if match(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+func (s *Storage) QueryBlockByHash(_ context.Context, ch, hash string) ([]db.Tx, error) {
+	return s.find(ch, func(tx db.Tx) bool { return tx.Hash == hash }), nil
+}
+
+func (s *Storage) GetByTxId(_ context.Context, ch, txid string) ([]db.Tx, error) {
+	return s.find(ch, func(tx db.Tx) bool { return tx.Txid == txid }), nil
+}
+
+func (s *Storage) GetByBlocknum(_ context.Context, ch string, blocknum uint64) ([]db.Tx, error) {
+	return s.find(ch, func(tx db.Tx) bool { return tx.Blocknum == blocknum }), nil
+}
+
+func (s *Storage) payloadMatch(payload string) (func(db.Tx) bool, error) {
+	re, err := regexp.Compile("(?i)" + payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid payload pattern")
+	}
+	return func(tx db.Tx) bool { return re.Match(tx.Payload) }, nil
+}
+
+func (s *Storage) GetBlockInfoByPayload(_ context.Context, ch, payload string) ([]db.Tx, error) {
+	match, err := s.payloadMatch(payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ch, match), nil
+}
+
+func (s *Storage) QueryAll(_ context.Context, ch string) ([]db.Tx, error) {
+	return s.find(ch, func(db.Tx) bool { return true }), nil
+}
+
+func (s *Storage) GetLastEntry(_ context.Context, ch string) (db.Tx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs := s.txs[ch]
+	if len(txs) == 0 {
+		return db.Tx{}, errors.New(db.NOT_FOUND_ERR)
+	}
+	return txs[len(txs)-1], nil
+}
+
+func (s *Storage) QueryAllPage(ctx context.Context, ch string, page db.Page) ([]db.Tx, string, error) {
+	return s.Query(ctx, ch, db.Filter{Page: page})
+}
+
+func (s *Storage) GetBlockInfoByPayloadPage(_ context.Context, ch, payload string, page db.Page) ([]db.Tx, string, error) {
+	match, err := s.payloadMatch(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return paginate(s.find(ch, match), page)
+}
+
+func (s *Storage) Query(_ context.Context, ch string, filter db.Filter) ([]db.Tx, string, error) {
+	return paginate(s.find(ch, filter.Match), filter.Page)
+}
+
+// paginate returns the page of items in storage order, the cursor is the offset of the next page
+func paginate[T any](items []T, page db.Page) ([]T, string, error) {
+	offset, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if page.Order == db.Descending {
+		reversed := make([]T, len(items))
+		for i, item := range items {
+			reversed[len(items)-1-i] = item
+		}
+		items = reversed
+	}
+	if offset >= len(items) {
+		return nil, "", nil
+	}
+
+	end := offset + int(page.PageLimit())
+	if end >= len(items) {
+		return items[offset:], "", nil
+	}
+	return items[offset:end], strconv.Itoa(end), nil
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(cursor)
+	if err != nil || offset < 0 {
+		return 0, db.InvalidCursor(errors.Errorf("bad offset %q", cursor))
+	}
+	return offset, nil
+}
+
+func (s *Storage) IterateAll(_ context.Context, ch string, page db.Page) (db.TxIterator, error) {
+	offset, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	txs := s.find(ch, func(db.Tx) bool { return true })
+	if offset > len(txs) {
+		offset = len(txs)
+	}
+	return &iterator{txs: txs[offset:], pos: -1}, nil
+}
+
+type iterator struct {
+	txs []db.Tx
+	pos int
+}
+
+func (it *iterator) Next(ctx context.Context) bool {
+	if ctx.Err() != nil || it.pos+1 >= len(it.txs) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *iterator) Tx() db.Tx {
+	return it.txs[it.pos]
+}
+
+func (it *iterator) Err() error {
+	return nil
+}
+
+func (it *iterator) Close(_ context.Context) error {
+	return nil
+}
+
+// InsertKeyHistory keeps key history ordered by block and tx number, versions are replaced like in databases
+func (s *Storage) InsertKeyHistory(_ context.Context, ch string, mods []db.KeyModification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, mod := range mods {
+		history := s.history[ch]
+		i := sort.Search(len(history), func(i int) bool {
+			h := history[i]
+			return h.Blocknum > mod.Blocknum || (h.Blocknum == mod.Blocknum && h.TxNum >= mod.TxNum)
+		})
+		for ; i < len(history) && history[i].Blocknum == mod.Blocknum && history[i].TxNum == mod.TxNum; i++ {
+			if history[i].Namespace == mod.Namespace && history[i].Key == mod.Key {
+				break
+			}
+		}
+		if i < len(history) && history[i].Blocknum == mod.Blocknum && history[i].TxNum == mod.TxNum &&
+			history[i].Namespace == mod.Namespace && history[i].Key == mod.Key {
+			history[i] = mod
+			continue
+		}
+		s.history[ch] = append(history[:i], append([]db.KeyModification{mod}, history[i:]...)...)
+	}
+	return nil
+}
+
+func (s *Storage) findHistory(ch string, match func(db.KeyModification) bool) []db.KeyModification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var mods []db.KeyModification
+	for _, mod := range s.history[ch] {
+		if match(mod) {
+			mods = append(mods, mod)
+		}
+	}
+	return mods
+}
+
+func (s *Storage) GetKeyHistory(_ context.Context, ch, namespace, key string, page db.Page) ([]db.KeyModification, string, error) {
+	return paginate(s.findHistory(ch, func(mod db.KeyModification) bool {
+		return mod.Namespace == namespace && mod.Key == key
+	}), page)
+}
+
+func (s *Storage) GetCompositeKeyHistory(_ context.Context, ch, namespace, objectType string, attributes []string, page db.Page) ([]db.KeyModification, string, error) {
+	return paginate(s.findHistory(ch, func(mod db.KeyModification) bool {
+		if mod.Namespace != namespace || mod.ObjectType != objectType || len(mod.Attributes) < len(attributes) {
+			return false
+		}
+		for i, attr := range attributes {
+			if mod.Attributes[i] != attr {
+				return false
+			}
+		}
+		return true
+	}), page)
+}
+
+func (s *Storage) GetCheckpoint(_ context.Context, ch, consumer string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocknum, ok := s.checkpoints[ch+"/"+consumer]
+	return blocknum, ok, nil
+}
+
+func (s *Storage) SetCheckpoint(_ context.Context, ch, consumer string, blocknum uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[ch+"/"+consumer] = blocknum
+	return nil
+}
+
+func (s *Storage) ApplyState(_ context.Context, ch string, writes []db.KeyModification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.state[ch]
+	if !ok {
+		state = make(map[string]db.KeyModification)
+		s.state[ch] = state
+	}
+	for _, w := range writes {
+		if w.IsDelete {
+			delete(state, stateKey(w.Namespace, w.Key))
+			continue
+		}
+		state[stateKey(w.Namespace, w.Key)] = w
+	}
+	return nil
+}
+
+func stateKey(namespace, key string) string {
+	return namespace + "\x00" + key
+}
+
+func (s *Storage) GetState(_ context.Context, ch, namespace, key string) (db.KeyModification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mod, ok := s.state[ch][stateKey(namespace, key)]
+	if !ok {
+		return db.KeyModification{}, errors.New(db.NOT_FOUND_ERR)
+	}
+	return mod, nil
+}
+
+func (s *Storage) GetStateAt(_ context.Context, ch, namespace, key string, blocknum uint64) (db.KeyModification, error) {
+	mods := s.findHistory(ch, func(mod db.KeyModification) bool {
+		return mod.Namespace == namespace && mod.Key == key && mod.Blocknum <= blocknum
+	})
+	if len(mods) == 0 || mods[len(mods)-1].IsDelete {
+		return db.KeyModification{}, errors.New(db.NOT_FOUND_ERR)
+	}
+	return mods[len(mods)-1], nil
+}
+
+// ScanState takes keys from the state or, as of the block, from key history. The cursor is the offset of the next page.
+func (s *Storage) ScanState(_ context.Context, ch, namespace string, scan db.StateScan) ([]db.KeyModification, string, error) {
+	inRange := func(mod db.KeyModification) bool {
+		return mod.Namespace == namespace && mod.Key >= scan.StartKey && (scan.EndKey == "" || mod.Key < scan.EndKey) &&
+			strings.HasPrefix(mod.Key, scan.Prefix)
+	}
+
+	latest := make(map[string]db.KeyModification)
+	if scan.AsOfBlock == nil {
+		s.mu.Lock()
+		for _, mod := range s.state[ch] {
+			if inRange(mod) {
+				latest[mod.Key] = mod
+			}
+		}
+		s.mu.Unlock()
+	} else {
+		for _, mod := range s.findHistory(ch, func(mod db.KeyModification) bool { return inRange(mod) && mod.Blocknum <= *scan.AsOfBlock }) {
+			latest[mod.Key] = mod
+		}
+	}
+
+	mods := make([]db.KeyModification, 0, len(latest))
+	for _, mod := range latest {
+		if !mod.IsDelete {
+			mods = append(mods, mod)
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Key < mods[j].Key })
+	return paginate(mods, scan.Page)
+}
+
+func (s *Storage) PutRawBlock(_ context.Context, ch string, blocknum uint64, raw []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocks, ok := s.raw[ch]
+	if !ok {
+		blocks = make(map[uint64][]byte)
+		s.raw[ch] = blocks
+	}
+	blocks[blocknum] = raw
+	return nil
+}
+
+func (s *Storage) GetRawBlock(_ context.Context, ch string, blocknum uint64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.raw[ch][blocknum]
+	if !ok {
+		return nil, errors.New(db.NOT_FOUND_ERR)
+	}
+	return raw, nil
+}
+
+// prune deletes txs and key history selected by the conditions, it returns the number of deleted txs
+func (s *Storage) prune(ch string, tx func(db.Tx) bool, mod func(db.KeyModification) bool) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		kept    []db.Tx
+		deleted int64
+	)
+	for _, t := range s.txs[ch] {
+		if tx(t) {
+			deleted++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	s.txs[ch] = kept
+
+	var history []db.KeyModification
+	for _, m := range s.history[ch] {
+		if !mod(m) {
+			history = append(history, m)
+		}
+	}
+	s.history[ch] = history
+	return deleted
+}
+
+func (s *Storage) PruneBlocks(_ context.Context, ch string, before uint64) (int64, error) {
+	return s.prune(ch, func(tx db.Tx) bool { return tx.Blocknum < before },
+		func(mod db.KeyModification) bool { return mod.Blocknum < before }), nil
+}
+
+func (s *Storage) PruneChaincodes(_ context.Context, ch string, keep []string, from, to uint64) (int64, error) {
+	kept := map[string]bool{"": true}
+	for _, chaincode := range keep {
+		kept[chaincode] = true
+	}
+	return s.prune(ch, func(tx db.Tx) bool { return tx.Blocknum >= from && tx.Blocknum <= to && !kept[tx.Chaincode] },
+		func(mod db.KeyModification) bool {
+			return mod.Blocknum >= from && mod.Blocknum <= to && !kept[mod.Namespace]
+		}), nil
+}
+
+func (s *Storage) LastBlockBefore(_ context.Context, ch string, t time.Time) (uint64, bool, error) {
+	var (
+		last  uint64
+		found bool
+	)
+	for _, tx := range s.find(ch, func(tx db.Tx) bool { return tx.Time < t.Unix() }) {
+		last, found = tx.Blocknum, true
+	}
+	return last, found, nil
+}
+
+func (s *Storage) ReplaceBlock(ctx context.Context, ch string, blocknum uint64, txs []db.Tx, history []db.KeyModification) error {
+	s.prune(ch, func(tx db.Tx) bool { return tx.Blocknum == blocknum }, func(mod db.KeyModification) bool { return mod.Blocknum == blocknum })
+	s.Add(ch, txs...)
+	return s.InsertKeyHistory(ctx, ch, history)
+}
+
+func (s *Storage) PutSubscription(_ context.Context, sub db.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[sub.ID] = sub
+	return nil
+}
+
+func (s *Storage) GetSubscription(_ context.Context, id string) (db.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok {
+		return sub, errors.New(db.NOT_FOUND_ERR)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns subscriptions ordered by ID
+func (s *Storage) ListSubscriptions(_ context.Context) ([]db.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]db.Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+func (s *Storage) DeleteSubscription(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[id]; !ok {
+		return errors.New(db.NOT_FOUND_ERR)
+	}
+	delete(s.subs, id)
+
+	var letters []db.DeadLetter
+	for _, letter := range s.letters {
+		if letter.SubscriptionID != id {
+			letters = append(letters, letter)
+		}
+	}
+	s.letters = letters
+	return nil
+}
+
+func (s *Storage) AddDeadLetter(_ context.Context, letter db.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if letter.ID == "" {
+		letter.ID = strconv.Itoa(len(s.letters) + 1)
+	}
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *Storage) ListDeadLetters(_ context.Context, subscriptionID string) ([]db.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := []db.DeadLetter{}
+	for _, letter := range s.letters {
+		if letter.SubscriptionID == subscriptionID {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+func (s *Storage) DeleteDeadLetter(_ context.Context, subscriptionID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, letter := range s.letters {
+		if letter.SubscriptionID == subscriptionID && letter.ID == id {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(db.NOT_FOUND_ERR)
+}
+
+var (
+	_ db.Storage         = (*Storage)(nil)
+	_ db.StateStore      = (*Storage)(nil)
+	_ db.RawBlockArchive = (*Storage)(nil)
+	_ db.Pruner          = (*Storage)(nil)
+	_ db.BlockReplacer   = (*Storage)(nil)
+)
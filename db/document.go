@@ -0,0 +1,93 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Document is a write value decoded into a structured form, so its fields can be queried
+type Document struct {
+	Key   string                 `json:"key" bson:"Key"`
+	Value map[string]interface{} `json:"value" bson:"Value"`
+}
+
+// DecodeJSON decodes the write value if it is a JSON object. Values of other kinds (arrays, scalars, binary data)
+// are not documents and are kept only in the payload.
+func DecodeJSON(value []byte) (map[string]interface{}, bool) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '{' {
+		return nil, false
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return nil, false
+	}
+
+	return doc, true
+}
+
+// FieldFilter selects txs that write a document with the value at the dot-separated path, e.g. owner or address.city
+type FieldFilter struct {
+	Path  string
+	Value interface{}
+}
+
+// ParseFieldFilter creates field filter from string params. JSON literals (numbers, booleans, null) in value
+// are decoded, anything else is treated as a string.
+func ParseFieldFilter(path, value string) (FieldFilter, error) {
+	for _, name := range strings.Split(path, ".") {
+		if name == "" || strings.HasPrefix(name, "$") {
+			return FieldFilter{}, errors.Errorf("invalid field path: %s", path)
+		}
+	}
+
+	return FieldFilter{Path: path, Value: parseFieldValue(value)}, nil
+}
+
+func parseFieldValue(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		// only scalar values are compared
+		return s
+	}
+
+	return v
+}
+
+// Match checks whether the document has the field value, arrays match if any of their elements is equal to the value
+func (f FieldFilter) Match(doc map[string]interface{}) bool {
+	var v interface{} = doc
+	for _, name := range strings.Split(f.Path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if v, ok = m[name]; !ok {
+			return false
+		}
+	}
+
+	if reflect.DeepEqual(v, f.Value) {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return false
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if reflect.DeepEqual(rv.Index(i).Interface(), f.Value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// Filter selects txs matching all specified conditions, zero-valued conditions are ignored
+type Filter struct {
+	// FromBlock is the first block of the range
+	FromBlock uint64
+	// ToBlock is the last block of the range (inclusive), 0 means no upper bound
+	ToBlock uint64
+	// FromTime and ToTime limit tx time (inclusive)
+	FromTime time.Time
+	ToTime   time.Time
+	// ValidationCode selects txs with given validation code, nil means any code
+	ValidationCode *int32
+	Chaincode      string
+	// Key selects txs that write the key
+	Key string
+	// KeyPrefix selects txs that write at least one key with the prefix
+	KeyPrefix  string
+	CreatorMSP string
+	// TxType is the channel header type name, e.g. ENDORSER_TRANSACTION
+	TxType string
+	// Fields select txs that write a document matching all field filters
+	Fields []FieldFilter
+	Page
+}
+
+// Match checks tx against the filter, page is not taken into account
+func (f Filter) Match(tx Tx) bool {
+	switch {
+	case tx.Blocknum < f.FromBlock:
+		return false
+	case f.ToBlock != 0 && tx.Blocknum > f.ToBlock:
+		return false
+	case !f.FromTime.IsZero() && tx.Time < f.FromTime.Unix():
+		return false
+	case !f.ToTime.IsZero() && tx.Time > f.ToTime.Unix():
+		return false
+	case f.ValidationCode != nil && tx.ValidationCode != *f.ValidationCode:
+		return false
+	case f.Chaincode != "" && tx.Chaincode != f.Chaincode:
+		return false
+	case f.CreatorMSP != "" && tx.CreatorMSP != f.CreatorMSP:
+		return false
+	case f.TxType != "" && tx.TxType != f.TxType:
+		return false
+	}
+
+	for _, field := range f.Fields {
+		if !matchDocuments(tx.Documents, field) {
+			return false
+		}
+	}
+
+	if f.Key == "" && f.KeyPrefix == "" {
+		return true
+	}
+	keyFound, prefixFound := f.Key == "", f.KeyPrefix == ""
+	for _, key := range tx.Keys {
+		keyFound = keyFound || key == f.Key
+		prefixFound = prefixFound || strings.HasPrefix(key, f.KeyPrefix)
+	}
+
+	return keyFound && prefixFound
+}
+
+func matchDocuments(docs []Document, field FieldFilter) bool {
+	for _, doc := range docs {
+		if field.Match(doc.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMatch(t *testing.T) {
+	valid := int32(0)
+	tx := Tx{Blocknum: 10, Time: 1000, ValidationCode: 0, Chaincode: "fabcar", CreatorMSP: "Org2MSP",
+		TxType: "ENDORSER_TRANSACTION", Keys: []string{"CAR1", "CAR10"},
+		Documents: []Document{{Key: "CAR1", Value: map[string]interface{}{"owner": "Tomoko", "year": float64(2015),
+			"tags": []interface{}{"red"}, "address": map[string]interface{}{"city": "Tokyo"}}}}}
+
+	for name, tc := range map[string]struct {
+		filter Filter
+		match  bool
+	}{
+		"empty":            {Filter{}, true},
+		"block range":      {Filter{FromBlock: 10, ToBlock: 10}, true},
+		"after range":      {Filter{FromBlock: 11}, false},
+		"before range":     {Filter{ToBlock: 9}, false},
+		"time range":       {Filter{FromTime: time.Unix(900, 0), ToTime: time.Unix(1000, 0)}, true},
+		"outdated":         {Filter{ToTime: time.Unix(999, 0)}, false},
+		"valid":            {Filter{ValidationCode: &valid}, true},
+		"chaincode":        {Filter{Chaincode: "fabcar"}, true},
+		"other chaincode":  {Filter{Chaincode: "lscc"}, false},
+		"creator":          {Filter{CreatorMSP: "Org1MSP"}, false},
+		"tx type":          {Filter{TxType: "CONFIG"}, false},
+		"key":              {Filter{Key: "CAR1"}, true},
+		"missing key":      {Filter{Key: "CAR"}, false},
+		"key prefix":       {Filter{KeyPrefix: "CAR"}, true},
+		"key and prefix":   {Filter{Key: "CAR1", KeyPrefix: "CAR10"}, true},
+		"missing prefix":   {Filter{KeyPrefix: "BIKE"}, false},
+		"compound matched": {Filter{FromBlock: 5, Chaincode: "fabcar", KeyPrefix: "CAR", CreatorMSP: "Org2MSP", ValidationCode: &valid}, true},
+		"field":            {Filter{Fields: []FieldFilter{{Path: "owner", Value: "Tomoko"}}}, true},
+		"other field":      {Filter{Fields: []FieldFilter{{Path: "owner", Value: "Brad"}}}, false},
+		"number field":     {Filter{Fields: []FieldFilter{{Path: "year", Value: float64(2015)}}}, true},
+		"nested field":     {Filter{Fields: []FieldFilter{{Path: "address.city", Value: "Tokyo"}}}, true},
+		"array field":      {Filter{Fields: []FieldFilter{{Path: "tags", Value: "red"}}}, true},
+		"missing field":    {Filter{Fields: []FieldFilter{{Path: "owner.name", Value: "Tomoko"}}}, false},
+	} {
+		assert.Equal(t, tc.match, tc.filter.Match(tx), name)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	doc, ok := DecodeJSON([]byte(` {"owner":"Tomoko","year":2015}`))
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"owner": "Tomoko", "year": float64(2015)}, doc)
+
+	for _, value := range []string{"", "Tomoko", "42", `["a"]`, "{broken"} {
+		_, ok = DecodeJSON([]byte(value))
+		assert.False(t, ok, value)
+	}
+}
+
+func TestParseFieldFilter(t *testing.T) {
+	for value, expected := range map[string]interface{}{"Tomoko": "Tomoko", "42": float64(42), "true": true, `{"a":1}`: `{"a":1}`} {
+		field, err := ParseFieldFilter("owner", value)
+		assert.NoError(t, err)
+		assert.Equal(t, FieldFilter{Path: "owner", Value: expected}, field)
+	}
+
+	for _, path := range []string{"", "owner.", "$where", "a.$gt"} {
+		_, err := ParseFieldFilter(path, "x")
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Migration upgrades stored data of the channel to Version. Steps run online, while the API serves the channel,
+// so they must keep data readable at every point and be idempotent: an interrupted step is repeated on the next start.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, ch string) error
+}
+
+// Migrator is implemented by backends with versioned schema
+type Migrator interface {
+	// SchemaVersion returns the version of the channel data, 0 means data stored before versioning
+	SchemaVersion(ctx context.Context, ch string) (int, error)
+	SetSchemaVersion(ctx context.Context, ch string, version int) error
+	// Migrations returns steps of the backend ordered by version
+	Migrations() []Migration
+}
+
+// Migrate runs pending migrations of the channel in order. The version is stored after every step,
+// so a failed migration continues from the failed step.
+func Migrate(ctx context.Context, m Migrator, ch string) error {
+	current, err := m.SchemaVersion(ctx, ch)
+	if err != nil {
+		return errors.Wrap(err, "failed to get schema version")
+	}
+
+	for _, migration := range m.Migrations() {
+		if migration.Version <= current {
+			continue
+		}
+		if err = migration.Up(ctx, ch); err != nil {
+			return errors.Wrapf(err, "migration %d (%s) failed", migration.Version, migration.Description)
+		}
+		if err = m.SetSchemaVersion(ctx, ch, migration.Version); err != nil {
+			return errors.Wrapf(err, "failed to set schema version %d", migration.Version)
+		}
+		current = migration.Version
+	}
+
+	return nil
+}
+
+// DeriveFunc parses marshaled block into txs and key history, e.g. with blockhandler.HandleBlock
+type DeriveFunc func(raw []byte) ([]Tx, []KeyModification, error)
+
+// BlockReplacer is implemented by backends supporting re-deriving of stored blocks
+type BlockReplacer interface {
+	// ReplaceBlock stores txs and key history of the block and deletes previously stored txs and key history
+	// of the block which aren't replaced
+	ReplaceBlock(ctx context.Context, channel string, blocknum uint64, txs []Tx, history []KeyModification) error
+}
+
+// Rederive parses stored raw blocks of the range again and replaces txs and key history derived from them,
+// so fields added by newer parsers are backfilled without requesting blocks from the peer. Blocks missing
+// in the store are skipped, the number of re-derived blocks is returned.
+func Rederive(ctx context.Context, storage BlockReplacer, blocks RawBlockStore, derive DeriveFunc, ch string, from, to uint64) (uint64, error) {
+	var done uint64
+	for num := from; num <= to; num++ {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+
+		raw, err := blocks.GetRawBlock(ctx, ch, num)
+		if err != nil {
+			if err.Error() == NOT_FOUND_ERR {
+				continue
+			}
+			return done, errors.Wrapf(err, "failed to get raw block %d", num)
+		}
+
+		txs, history, err := derive(raw)
+		if err != nil {
+			return done, errors.Wrapf(err, "failed to parse raw block %d", num)
+		}
+		if err = storage.ReplaceBlock(ctx, ch, num, txs, history); err != nil {
+			return done, errors.Wrapf(err, "failed to replace block %d", num)
+		}
+		done++
+	}
+
+	return done, nil
+}
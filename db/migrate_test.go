@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMigrator struct {
+	version    int
+	applied    []int
+	migrations []Migration
+}
+
+func (m *testMigrator) SchemaVersion(_ context.Context, _ string) (int, error) {
+	return m.version, nil
+}
+
+func (m *testMigrator) SetSchemaVersion(_ context.Context, _ string, version int) error {
+	m.version = version
+	return nil
+}
+
+func (m *testMigrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *testMigrator) step(version int, err error) Migration {
+	return Migration{Version: version, Up: func(_ context.Context, _ string) error {
+		if err != nil {
+			return err
+		}
+		m.applied = append(m.applied, version)
+		return nil
+	}}
+}
+
+func TestMigrate(t *testing.T) {
+	m := &testMigrator{version: 1}
+	m.migrations = []Migration{m.step(1, nil), m.step(2, nil), m.step(3, errors.New("failed")), m.step(4, nil)}
+
+	assert.Error(t, Migrate(context.Background(), m, "mychannel"))
+	assert.Equal(t, []int{2}, m.applied, "applied migrations must be skipped and failed one must stop migration")
+	assert.Equal(t, 2, m.version, "version of the last successful step must be stored")
+
+	m.migrations[2] = m.step(3, nil)
+	assert.NoError(t, Migrate(context.Background(), m, "mychannel"))
+	assert.Equal(t, []int{2, 3, 4}, m.applied)
+	assert.Equal(t, 4, m.version)
+}
+
+// testBlocks keeps raw blocks and replaced blocks by number
+type testBlocks struct {
+	raw      map[uint64][]byte
+	replaced map[uint64][]Tx
+}
+
+func (b *testBlocks) GetRawBlock(_ context.Context, _ string, blocknum uint64) ([]byte, error) {
+	raw, ok := b.raw[blocknum]
+	if !ok {
+		return nil, errors.New(NOT_FOUND_ERR)
+	}
+	return raw, nil
+}
+
+func (b *testBlocks) ReplaceBlock(_ context.Context, _ string, blocknum uint64, txs []Tx, _ []KeyModification) error {
+	b.replaced[blocknum] = txs
+	return nil
+}
+
+func TestRederive(t *testing.T) {
+	blocks := &testBlocks{raw: map[uint64][]byte{1: []byte("tx1"), 3: []byte("tx3"), 4: []byte("bad")}, replaced: map[uint64][]Tx{}}
+	derive := func(raw []byte) ([]Tx, []KeyModification, error) {
+		if string(raw) == "bad" {
+			return nil, nil, errors.New("invalid block")
+		}
+		return []Tx{{Txid: string(raw), Chaincode: "fabcar"}}, nil, nil
+	}
+
+	done, err := Rederive(context.Background(), blocks, blocks, derive, "mychannel", 0, 3)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, done, "missing blocks must be skipped")
+	assert.Equal(t, map[uint64][]Tx{1: {{Txid: "tx1", Chaincode: "fabcar"}}, 3: {{Txid: "tx3", Chaincode: "fabcar"}}}, blocks.replaced)
+
+	_, err = Rederive(context.Background(), blocks, blocks, derive, "mychannel", 3, 4)
+	assert.Error(t, err)
+}
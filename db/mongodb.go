@@ -18,9 +18,16 @@ package db
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
@@ -43,45 +50,123 @@ type DBmongo struct {
 const ERR_NO_DOCUMENTS = "mongo: no documents in result"
 
 func CreateDBConfMongo(host string, port int, user, password, dbname, collection string) *DBmongo {
-	client, err := mongo.NewClient(options.Client().ApplyURI(fmt.Sprintf("mongodb://%s:%s@%s:%d", user, password, host, port)))
+	db, err := NewMongo(MongoOptions{Host: host, Port: port, User: user, Password: password, DBname: dbname, Collection: collection})
 	if err != nil {
 		log.Fatalf("Mongodb client creation failed: %s", err)
 	}
-	return &DBmongo{host, port, user, password, dbname, collection, client}
+	return db
 }
 
-func (db *DBmongo) Connect() error {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second) //nolint:govet
-	err := db.Instance.Connect(ctx)
+// MongoOptions configures connection to mongo
+type MongoOptions struct {
+	// URI is a connection string, e.g. mongodb+srv://cluster0.example.com or mongodb://h1:27017,h2:27017/?replicaSet=rs0,
+	// Host and Port are used if it's empty
+	URI  string
+	Host string
+	Port int
+	// User and Password override credentials of the URI
+	User     string
+	Password string
+	// AuthSource is the database of the user, admin by default
+	AuthSource string
+	TLS        bool
+	// TLSCAFile is a path to PEM encoded CA certificates, system CAs are used if it's empty
+	TLSCAFile   string
+	TLSInsecure bool
+	DBname      string
+	Collection  string
+}
+
+func NewMongo(opts MongoOptions) (*DBmongo, error) {
+	uri := opts.URI
+	if uri == "" {
+		uri = fmt.Sprintf("mongodb://%s", net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port)))
+	}
+	clientOpts := options.Client().ApplyURI(uri)
+
+	if opts.User != "" || opts.AuthSource != "" {
+		cred := options.Credential{}
+		if clientOpts.Auth != nil {
+			cred = *clientOpts.Auth
+		}
+		if opts.User != "" {
+			cred.Username, cred.Password = opts.User, opts.Password
+		}
+		if opts.AuthSource != "" {
+			cred.AuthSource = opts.AuthSource
+		}
+		clientOpts.SetAuth(cred)
+	}
+
+	if opts.TLS || opts.TLSCAFile != "" {
+		tlsConf := &tls.Config{InsecureSkipVerify: opts.TLSInsecure}
+		if opts.TLSCAFile != "" {
+			pem, err := ioutil.ReadFile(opts.TLSCAFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read CA file")
+			}
+			tlsConf.RootCAs = x509.NewCertPool()
+			if !tlsConf.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.Errorf("no certificates found in %s", opts.TLSCAFile)
+			}
+		}
+		clientOpts.SetTLSConfig(tlsConf)
+	}
+
+	client, err := mongo.NewClient(clientOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &DBmongo{opts.Host, opts.Port, opts.User, opts.Password, opts.DBname, opts.Collection, client}, nil
+}
+
+func (db *DBmongo) Connect(ctx context.Context) error {
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err := db.Instance.Connect(connectCtx)
 	if err != nil {
 		return err
 	}
-	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second) //nolint:govet
-	err = db.Instance.Ping(ctx, readpref.Primary())
-	return err
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return db.Instance.Ping(pingCtx, readpref.Primary())
 }
 
-func (db *DBmongo) Init(_ string) error {
-	return nil
+func (db *DBmongo) Close(ctx context.Context) error {
+	return db.Instance.Disconnect(ctx)
+}
+
+func (db *DBmongo) Init(ctx context.Context, ch string) error {
+	if err := db.initIndexes(ctx, ch); err != nil {
+		return err
+	}
+	if err := Migrate(ctx, db, ch); err != nil {
+		return err
+	}
+
+	return db.initState(ctx, ch)
 }
 
-func (db *DBmongo) Insert(ch string, tx Tx) error {
-	collection := db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s", db.Collection, ch))
-	ctx := context.Background()
+func (db *DBmongo) historyCollection(ch string) *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s_history", db.Collection, ch))
+}
 
-	_, err := collection.InsertOne(ctx, bson.M{"ChannelId": tx.ChannelId, "Txid": tx.Txid, "Hash": tx.Hash, "PreviousHash": tx.PreviousHash, "Blocknum": tx.Blocknum, "Payload": string(tx.Payload), "ValidationCode": tx.ValidationCode, "Time": tx.Time})
+func (db *DBmongo) Insert(ctx context.Context, ch string, tx Tx) error {
+	doc, err := newMongoTx(tx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// replacing makes repeated inserts of the block after restart idempotent
+	_, err = db.txCollection(ch).ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc, options.Replace().SetUpsert(true))
+	return err
 }
 
-func (db *DBmongo) getByFilter(ch string, filterValue interface{}) ([]Tx, error) {
-	collection := db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s", db.Collection, ch))
-	filter := filterValue
-	ctx := context.Background()
-	cur, err := collection.Find(ctx, filter)
+func (db *DBmongo) getByFilter(ctx context.Context, ch string, filterValue interface{}) ([]Tx, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "Blocknum", Value: 1}, {Key: "TxNum", Value: 1}, {Key: "_id", Value: 1}})
+	cur, err := db.txCollection(ch).Find(ctx, filterValue, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -90,8 +175,7 @@ func (db *DBmongo) getByFilter(ch string, filterValue interface{}) ([]Tx, error)
 
 	var results []Tx
 	for cur.Next(ctx) {
-		var result Tx
-		err = cur.Decode(&result)
+		result, err := decodeMongoTx(cur.Current)
 		if err != nil {
 			return nil, err
 		}
@@ -104,39 +188,371 @@ func (db *DBmongo) getByFilter(ch string, filterValue interface{}) ([]Tx, error)
 	return results, nil
 }
 
-func (db *DBmongo) QueryBlockByHash(ch string, hash string) ([]Tx, error) {
-	return db.getByFilter(ch, bson.M{"Hash": hash})
+func (db *DBmongo) QueryBlockByHash(ctx context.Context, ch string, hash string) ([]Tx, error) {
+	return db.getByFilter(ctx, ch, bson.M{"Hash": hash})
+}
+
+func (db *DBmongo) GetByTxId(ctx context.Context, ch string, txID string) ([]Tx, error) {
+	return db.getByFilter(ctx, ch, bson.M{"Txid": txID})
+}
+
+func (db *DBmongo) GetByBlocknum(ctx context.Context, ch string, blocknum uint64) ([]Tx, error) {
+	return db.getByFilter(ctx, ch, bson.M{"Blocknum": blocknum})
+}
+
+// GetBlockInfoByPayload finds txs which payload (JSON of written keys and base64-encoded values) matches the pattern
+func (db *DBmongo) GetBlockInfoByPayload(ctx context.Context, ch string, payload string) ([]Tx, error) {
+	return db.getByFilter(ctx, ch, payloadFilter(payload))
+}
+
+func payloadFilter(payload string) bson.M {
+	return bson.M{"Payload": primitive.Regex{Pattern: payload, Options: "i"}}
+}
+
+func (db *DBmongo) QueryAll(ctx context.Context, ch string) ([]Tx, error) {
+	return db.getByFilter(ctx, ch, bson.D{})
+}
+
+func (db *DBmongo) GetLastEntry(ctx context.Context, ch string) (Tx, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "Blocknum", Value: -1}, {Key: "TxNum", Value: -1}, {Key: "_id", Value: -1}})
+
+	raw, err := db.txCollection(ch).FindOne(ctx, bson.D{}, opts).DecodeBytes()
+	if err == mongo.ErrNoDocuments {
+		return Tx{}, errors.New(NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return Tx{}, err
+	}
+
+	return decodeMongoTx(raw)
+}
+
+// mongoCursor is a position of the last document of the page, tx number and document ID are used as tiebreakers
+// for documents from the same block
+type mongoCursor struct {
+	Blocknum uint64      `bson:"Blocknum"`
+	TxNum    uint64      `bson:"TxNum"`
+	ID       interface{} `bson:"_id"`
+}
+
+func encodeMongoCursor(c mongoCursor) (string, error) {
+	raw, err := bson.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeMongoCursor(cursor string) (mongoCursor, error) {
+	var c mongoCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, InvalidCursor(err)
+	}
+	if err = bson.Unmarshal(raw, &c); err != nil {
+		return c, InvalidCursor(err)
+	}
+	return c, nil
+}
+
+// pageQuery adds cursor condition to filter and returns options for sorting documents in the page order
+func pageQuery(filter bson.M, page Page) (bson.M, *options.FindOptions, error) {
+	direction, cmp := 1, "$gt"
+	if page.Order == Descending {
+		direction, cmp = -1, "$lt"
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "Blocknum", Value: direction}, {Key: "TxNum", Value: direction}, {Key: "_id", Value: direction}})
+
+	if page.Cursor == "" {
+		return filter, opts, nil
+	}
+
+	c, err := decodeMongoCursor(page.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	after := bson.M{"$or": bson.A{
+		bson.M{"Blocknum": bson.M{cmp: c.Blocknum}},
+		bson.M{"Blocknum": c.Blocknum, "TxNum": bson.M{cmp: c.TxNum}},
+		bson.M{"Blocknum": c.Blocknum, "TxNum": c.TxNum, "_id": bson.M{cmp: c.ID}},
+	}}
+	if len(filter) == 0 {
+		return after, opts, nil
+	}
+	return bson.M{"$and": bson.A{filter, after}}, opts, nil
+}
+
+// findPage returns a page of documents ordered by block number and a cursor of the next page
+func findPage(ctx context.Context, collection *mongo.Collection, filter bson.M, page Page) ([]bson.Raw, string, error) {
+	filter, opts, err := pageQuery(filter, page)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := page.PageLimit()
+	// request one extra document to find out whether the next page exists
+	opts.SetLimit(limit + 1)
+
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var docs []bson.Raw
+	for cur.Next(ctx) {
+		docs = append(docs, cur.Current)
+	}
+	if err = cur.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if int64(len(docs)) > limit {
+		docs = docs[:limit]
+		var last mongoCursor
+		if err = bson.Unmarshal(docs[len(docs)-1], &last); err != nil {
+			return nil, "", err
+		}
+		if next, err = encodeMongoCursor(last); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return docs, next, nil
+}
+
+func (db *DBmongo) getPageByFilter(ctx context.Context, ch string, filter bson.M, page Page) ([]Tx, string, error) {
+	docs, next, err := findPage(ctx, db.txCollection(ch), filter, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	txs := make([]Tx, len(docs))
+	for i, doc := range docs {
+		if txs[i], err = decodeMongoTx(doc); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return txs, next, nil
+}
+
+func (db *DBmongo) QueryAllPage(ctx context.Context, ch string, page Page) ([]Tx, string, error) {
+	return db.getPageByFilter(ctx, ch, bson.M{}, page)
+}
+
+func (db *DBmongo) GetBlockInfoByPayloadPage(ctx context.Context, ch string, payload string, page Page) ([]Tx, string, error) {
+	return db.getPageByFilter(ctx, ch, payloadFilter(payload), page)
+}
+
+func (db *DBmongo) Query(ctx context.Context, ch string, filter Filter) ([]Tx, string, error) {
+	return db.getPageByFilter(ctx, ch, mongoFilter(filter), filter.Page)
+}
+
+// prefixRange selects strings with the prefix. Regular expressions can't be used, because composite keys contain
+// null bytes which are not allowed in mongo regular expressions.
+func prefixRange(prefix string) bson.M {
+	return bson.M{"$gte": prefix, "$lt": prefix + string(utf8.MaxRune)}
+}
+
+// mongoFilter converts Filter to mongo query
+func mongoFilter(f Filter) bson.M {
+	var conds bson.A
+
+	blocks := bson.M{}
+	if f.FromBlock != 0 {
+		blocks["$gte"] = f.FromBlock
+	}
+	if f.ToBlock != 0 {
+		blocks["$lte"] = f.ToBlock
+	}
+	if len(blocks) != 0 {
+		conds = append(conds, bson.M{"Blocknum": blocks})
+	}
+
+	times := bson.M{}
+	if !f.FromTime.IsZero() {
+		times["$gte"] = f.FromTime.Unix()
+	}
+	if !f.ToTime.IsZero() {
+		times["$lte"] = f.ToTime.Unix()
+	}
+	if len(times) != 0 {
+		conds = append(conds, bson.M{"Time": times})
+	}
+
+	if f.ValidationCode != nil {
+		conds = append(conds, bson.M{"ValidationCode": *f.ValidationCode})
+	}
+	if f.Chaincode != "" {
+		conds = append(conds, bson.M{"Chaincode": f.Chaincode})
+	}
+	if f.CreatorMSP != "" {
+		conds = append(conds, bson.M{"CreatorMSP": f.CreatorMSP})
+	}
+	if f.TxType != "" {
+		conds = append(conds, bson.M{"TxType": f.TxType})
+	}
+	if f.Key != "" {
+		conds = append(conds, bson.M{"Writes.Key": f.Key})
+	}
+	if f.KeyPrefix != "" {
+		// both bounds must match the same key
+		conds = append(conds, bson.M{"Writes": bson.M{"$elemMatch": bson.M{"Key": prefixRange(f.KeyPrefix)}}})
+	}
+
+	for _, field := range f.Fields {
+		conds = append(conds, bson.M{"Documents": bson.M{"$elemMatch": bson.M{"Value." + field.Path: field.Value}}})
+	}
+
+	if len(conds) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": conds}
+}
+
+func (db *DBmongo) IterateAll(ctx context.Context, ch string, page Page) (TxIterator, error) {
+	filter, opts, err := pageQuery(bson.M{}, page)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := db.txCollection(ch).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongoIterator{cur: cur}, nil
+}
+
+type mongoIterator struct {
+	cur *mongo.Cursor
+	tx  Tx
+	err error
+}
+
+func (it *mongoIterator) Next(ctx context.Context) bool {
+	if it.err != nil || !it.cur.Next(ctx) {
+		return false
+	}
+	it.tx, it.err = decodeMongoTx(it.cur.Current)
+	return it.err == nil
+}
+
+func (it *mongoIterator) Tx() Tx {
+	return it.tx
+}
+
+func (it *mongoIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.cur.Err()
+}
+
+func (it *mongoIterator) Close(ctx context.Context) error {
+	return it.cur.Close(ctx)
+}
+
+func (db *DBmongo) InsertKeyHistory(ctx context.Context, ch string, mods []KeyModification) error {
+	if len(mods) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(mods))
+	for _, mod := range mods {
+		doc := mongoKeyModification{ID: keyModificationDocID(mod), KeyModification: mod}
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": doc.ID}).SetReplacement(doc).SetUpsert(true))
+	}
+	_, err := db.historyCollection(ch).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (db *DBmongo) GetKeyHistory(ctx context.Context, ch, namespace, key string, page Page) ([]KeyModification, string, error) {
+	return db.getKeyHistory(ctx, ch, bson.M{"Namespace": namespace, "Key": key}, page)
+}
+
+func (db *DBmongo) GetCompositeKeyHistory(ctx context.Context, ch, namespace, objectType string, attributes []string, page Page) ([]KeyModification, string, error) {
+	return db.getKeyHistory(ctx, ch, compositeKeyFilter(namespace, objectType, attributes), page)
+}
+
+// compositeKeyFilter selects composite keys by object type and leading attributes
+func compositeKeyFilter(namespace, objectType string, attributes []string) bson.M {
+	filter := bson.M{"Namespace": namespace, "ObjectType": objectType}
+	for i, attr := range attributes {
+		filter[fmt.Sprintf("Attributes.%d", i)] = attr
+	}
+	return filter
+}
+
+func (db *DBmongo) getKeyHistory(ctx context.Context, ch string, filter bson.M, page Page) ([]KeyModification, string, error) {
+	docs, next, err := findPage(ctx, db.historyCollection(ch), filter, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mods := make([]KeyModification, len(docs))
+	for i, doc := range docs {
+		if err = bson.Unmarshal(doc, &mods[i]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return mods, next, nil
 }
 
-func (db *DBmongo) GetByTxId(ch string, txID string) ([]Tx, error) {
-	return db.getByFilter(ch, bson.M{"Txid": txID})
+func (db *DBmongo) checkpointsCollection() *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_checkpoints", db.Collection))
 }
 
-func (db *DBmongo) GetByBlocknum(ch string, blocknum uint64) ([]Tx, error) {
-	return db.getByFilter(ch, bson.M{"Blocknum": blocknum})
+func (db *DBmongo) GetCheckpoint(ctx context.Context, ch, consumer string) (uint64, bool, error) {
+	var checkpoint struct {
+		Blocknum uint64 `bson:"Blocknum"`
+	}
+	err := db.checkpointsCollection().FindOne(ctx, bson.M{"_id": ch + "/" + consumer}).Decode(&checkpoint)
+	if err == mongo.ErrNoDocuments {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return checkpoint.Blocknum, true, nil
 }
 
-func (db *DBmongo) GetBlockInfoByPayload(ch string, payload string) ([]Tx, error) {
-	return db.getByFilter(ch, bson.M{"Payload": primitive.Regex{Pattern: payload, Options: "i"}})
+func (db *DBmongo) SetCheckpoint(ctx context.Context, ch, consumer string, blocknum uint64) error {
+	_, err := db.checkpointsCollection().UpdateOne(ctx, bson.M{"_id": ch + "/" + consumer},
+		bson.M{"$set": bson.M{"ChannelId": ch, "Consumer": consumer, "Blocknum": blocknum}}, options.Update().SetUpsert(true))
+	return err
 }
 
-func (db *DBmongo) QueryAll(ch string) ([]Tx, error) {
-	return db.getByFilter(ch, bson.D{})
+func (db *DBmongo) blocksCollection(ch string) *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s_blocks", db.Collection, ch))
 }
 
-func (db *DBmongo) GetLastEntry(ch string) (Tx, error) {
-	collection := db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s", db.Collection, ch))
+// PutRawBlock stores compressed block keyed by its number
+func (db *DBmongo) PutRawBlock(ctx context.Context, ch string, blocknum uint64, raw []byte) error {
+	compressed, err := compressBlock(raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to compress block")
+	}
 
-	ctx := context.Background()
-	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	_, err = db.blocksCollection(ch).ReplaceOne(ctx, bson.M{"_id": int64(blocknum)}, bson.M{"_id": int64(blocknum), "Data": compressed},
+		options.Replace().SetUpsert(true))
+	return err
+}
 
-	var tx Tx
-	err := collection.FindOne(ctx, bson.D{}, opts).Decode(&tx)
-	if err != nil && err.Error() != ERR_NO_DOCUMENTS {
-		return tx, err
-	} else if err != nil && err.Error() == ERR_NO_DOCUMENTS {
-		return tx, errors.New(NOT_FOUND_ERR)
+func (db *DBmongo) GetRawBlock(ctx context.Context, ch string, blocknum uint64) ([]byte, error) {
+	var block struct {
+		Data []byte `bson:"Data"`
+	}
+	err := db.blocksCollection(ch).FindOne(ctx, bson.M{"_id": int64(blocknum)}).Decode(&block)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New(NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return tx, nil
+	return decompressBlock(block.Data)
 }
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (db *DBmongo) PruneBlocks(ctx context.Context, ch string, before uint64) (int64, error) {
+	filter := bson.M{"Blocknum": bson.M{"$lt": before}}
+	res, err := db.txCollection(ch).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = db.historyCollection(ch).DeleteMany(ctx, filter); err != nil {
+		return res.DeletedCount, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (db *DBmongo) PruneChaincodes(ctx context.Context, ch string, keep []string, from, to uint64) (int64, error) {
+	blocks := bson.M{"$gte": from, "$lte": to}
+	// config txs have no chaincode
+	res, err := db.txCollection(ch).DeleteMany(ctx, bson.M{"Blocknum": blocks, "Chaincode": bson.M{"$nin": append([]string{""}, keep...)}})
+	if err != nil {
+		return 0, err
+	}
+	if _, err = db.historyCollection(ch).DeleteMany(ctx, bson.M{"Blocknum": blocks, "Namespace": bson.M{"$nin": append([]string{}, keep...)}}); err != nil {
+		return res.DeletedCount, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (db *DBmongo) LastBlockBefore(ctx context.Context, ch string, t time.Time) (uint64, bool, error) {
+	var tx struct {
+		Blocknum uint64 `bson:"Blocknum"`
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "Blocknum", Value: -1}}).SetProjection(bson.M{"Blocknum": 1})
+	err := db.txCollection(ch).FindOne(ctx, bson.M{"Time": bson.M{"$lt": t.Unix()}}, opts).Decode(&tx)
+	if err == mongo.ErrNoDocuments {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return tx.Blocknum, true, nil
+}
+
+func (db *DBmongo) PruneRawBlocks(ctx context.Context, ch string, before uint64) error {
+	_, err := db.blocksCollection(ch).DeleteMany(ctx, bson.M{"_id": bson.M{"$lt": int64(before)}})
+	return err
+}
@@ -0,0 +1,370 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoTx is the layout of tx documents. _id is derived from tx ID and position of the tx, so repeated inserts
+// of the same block are idempotent, txs reusing the ID of a committed tx (DUPLICATE_TXID) don't replace it and
+// collections can be sharded by hashed _id. Payload is JSON of writes with base64-encoded values kept for
+// payload search.
+type mongoTx struct {
+	ID             string       `bson:"_id"`
+	ChannelId      string       `bson:"ChannelId"`
+	Txid           string       `bson:"Txid"`
+	Hash           string       `bson:"Hash"`
+	PreviousHash   string       `bson:"PreviousHash"`
+	Blocknum       uint64       `bson:"Blocknum"`
+	TxNum          uint64       `bson:"TxNum"`
+	ValidationCode int32        `bson:"ValidationCode"`
+	Time           int64        `bson:"Time"`
+	Chaincode      string       `bson:"Chaincode"`
+	CreatorMSP     string       `bson:"CreatorMSP"`
+	TxType         string       `bson:"TxType"`
+	Payload        string       `bson:"Payload"`
+	Writes         []mongoWrite `bson:"Writes"`
+	Documents      []Document   `bson:"Documents,omitempty"`
+}
+
+type mongoWrite struct {
+	Key   string `bson:"Key"`
+	Value []byte `bson:"Value"`
+}
+
+// mongoKeyModification is the layout of key history documents
+type mongoKeyModification struct {
+	ID              string `bson:"_id"`
+	KeyModification `bson:",inline"`
+}
+
+func txDocID(tx Tx) string {
+	return fmt.Sprintf("%s/%d/%d/%s", tx.Txid, tx.Blocknum, tx.TxNum, tx.Chaincode)
+}
+
+// legacyTxDocID matches IDs of txs stored before the position of the tx was added to IDs
+var legacyTxDocID = primitive.Regex{Pattern: "^[^/]*/[^/]*$"}
+
+func keyModificationDocID(mod KeyModification) string {
+	return fmt.Sprintf("%s/%s/%s", mod.Txid, mod.Namespace, mod.Key)
+}
+
+func newMongoTx(tx Tx) (mongoTx, error) {
+	var payload []RW
+	if err := json.Unmarshal(tx.Payload, &payload); err != nil {
+		return mongoTx{}, errors.Wrapf(err, "failed to unmarshal payload of tx %s", tx.Txid)
+	}
+
+	writes := make([]mongoWrite, 0, len(payload))
+	for _, rw := range payload {
+		value, err := base64.StdEncoding.DecodeString(rw.Value)
+		if err != nil {
+			return mongoTx{}, errors.Wrapf(err, "failed to decode value of key %s in tx %s", rw.Key, tx.Txid)
+		}
+		writes = append(writes, mongoWrite{Key: rw.Key, Value: value})
+	}
+
+	return mongoTx{
+		ID:             txDocID(tx),
+		ChannelId:      tx.ChannelId,
+		Txid:           tx.Txid,
+		Hash:           tx.Hash,
+		PreviousHash:   tx.PreviousHash,
+		Blocknum:       tx.Blocknum,
+		TxNum:          tx.TxNum,
+		ValidationCode: tx.ValidationCode,
+		Time:           tx.Time,
+		Chaincode:      tx.Chaincode,
+		CreatorMSP:     tx.CreatorMSP,
+		TxType:         tx.TxType,
+		Payload:        string(tx.Payload),
+		Writes:         writes,
+		Documents:      tx.Documents,
+	}, nil
+}
+
+// tx restores Tx, payload is encoded the same way as blockhandler does
+func (doc mongoTx) tx() (Tx, error) {
+	payload := make([]RW, 0, len(doc.Writes))
+	keys := make([]string, 0, len(doc.Writes))
+	for _, w := range doc.Writes {
+		payload = append(payload, RW{Key: w.Key, Value: base64.StdEncoding.EncodeToString(w.Value)})
+		keys = append(keys, w.Key)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Tx{}, err
+	}
+
+	return Tx{
+		ChannelId:      doc.ChannelId,
+		Txid:           doc.Txid,
+		Hash:           doc.Hash,
+		PreviousHash:   doc.PreviousHash,
+		Blocknum:       doc.Blocknum,
+		TxNum:          doc.TxNum,
+		Payload:        raw,
+		ValidationCode: doc.ValidationCode,
+		Time:           doc.Time,
+		Chaincode:      doc.Chaincode,
+		CreatorMSP:     doc.CreatorMSP,
+		TxType:         doc.TxType,
+		Keys:           keys,
+		Documents:      doc.Documents,
+	}, nil
+}
+
+func decodeMongoTx(raw bson.Raw) (Tx, error) {
+	var doc mongoTx
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return Tx{}, err
+	}
+	return doc.tx()
+}
+
+func (db *DBmongo) txCollection(ch string) *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s", db.Collection, ch))
+}
+
+// initIndexes creates indexes used by queries, existing indexes are kept
+func (db *DBmongo) initIndexes(ctx context.Context, ch string) error {
+	_, err := db.txCollection(ch).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "Blocknum", Value: 1}, {Key: "TxNum", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "Txid", Value: 1}}},
+		{Keys: bson.D{{Key: "Hash", Value: 1}}},
+		{Keys: bson.D{{Key: "Writes.Key", Value: 1}}},
+		{Keys: bson.D{{Key: "Chaincode", Value: 1}, {Key: "Blocknum", Value: 1}}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create tx indexes")
+	}
+
+	_, err = db.historyCollection(ch).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "Namespace", Value: 1}, {Key: "Key", Value: 1}, {Key: "Blocknum", Value: 1}, {Key: "TxNum", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "Namespace", Value: 1}, {Key: "ObjectType", Value: 1}, {Key: "Blocknum", Value: 1}, {Key: "TxNum", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	return errors.Wrap(err, "failed to create key history indexes")
+}
+
+// legacyIndexes were replaced by indexes with tx number
+var legacyIndexes = []string{"Namespace_1_Key_1_Blocknum_1__id_1", "Namespace_1_ObjectType_1_Blocknum_1__id_1"}
+
+// migrationBatchSize limits number of documents replaced by a single bulk write during migration
+const migrationBatchSize = 500
+
+// migrateLayout converts documents stored before schema versioning. Txs with generated IDs and payload string get
+// deterministic IDs and writes, their tx numbers are restored from the insertion order. Key history gets deterministic IDs.
+func (db *DBmongo) migrateLayout(ctx context.Context, ch string) error {
+	var (
+		block  uint64
+		txNums = make(map[string]uint64)
+	)
+	err := replaceLegacy(ctx, db.txCollection(ch), generatedID, func(raw bson.Raw) (string, interface{}, error) {
+		var tx Tx
+		if err := bson.Unmarshal(raw, &tx); err != nil {
+			return "", nil, err
+		}
+
+		if tx.Blocknum != block {
+			block, txNums = tx.Blocknum, make(map[string]uint64)
+		}
+		txNum, ok := txNums[tx.Txid]
+		if !ok {
+			txNum = uint64(len(txNums))
+			txNums[tx.Txid] = txNum
+		}
+		tx.TxNum = txNum
+
+		doc, err := newMongoTx(tx)
+		return doc.ID, doc, err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate txs")
+	}
+
+	err = replaceLegacy(ctx, db.historyCollection(ch), generatedID, func(raw bson.Raw) (string, interface{}, error) {
+		var mod KeyModification
+		if err := bson.Unmarshal(raw, &mod); err != nil {
+			return "", nil, err
+		}
+		doc := mongoKeyModification{ID: keyModificationDocID(mod), KeyModification: mod}
+		return doc.ID, doc, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate key history")
+	}
+
+	for _, name := range legacyIndexes {
+		// the index is missing if the collection was created by this version
+		_, _ = db.historyCollection(ch).Indexes().DropOne(ctx, name)
+	}
+
+	return nil
+}
+
+// generatedID selects documents with IDs generated by mongo
+var generatedID = bson.M{"_id": bson.M{"$type": "objectId"}}
+
+// migrateTxIDs adds the position of the tx to IDs of txs and stores payload text of txs migrated by migrateLayout
+func (db *DBmongo) migrateTxIDs(ctx context.Context, ch string) error {
+	return replaceLegacy(ctx, db.txCollection(ch), bson.M{"_id": legacyTxDocID}, func(raw bson.Raw) (string, interface{}, error) {
+		tx, err := decodeMongoTx(raw)
+		if err != nil {
+			return "", nil, err
+		}
+		doc, err := newMongoTx(tx)
+		return doc.ID, doc, err
+	})
+}
+
+// replaceLegacy replaces documents matching the filter by documents returned by convert in block order
+func replaceLegacy(ctx context.Context, collection *mongo.Collection, filter bson.M, convert func(raw bson.Raw) (string, interface{}, error)) error {
+	opts := options.Find().SetSort(bson.D{{Key: "Blocknum", Value: 1}, {Key: "_id", Value: 1}}).SetAllowDiskUse(true)
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := collection.BulkWrite(ctx, batch)
+		batch = nil
+		return err
+	}
+
+	for cur.Next(ctx) {
+		id, doc, err := convert(cur.Current)
+		if err != nil {
+			return err
+		}
+		batch = append(batch,
+			mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true),
+			mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": cur.Current.Lookup("_id")}))
+		if len(batch) >= migrationBatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = cur.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+func (db *DBmongo) schemaCollection() *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_schema", db.Collection))
+}
+
+func (db *DBmongo) SchemaVersion(ctx context.Context, ch string) (int, error) {
+	var schema struct {
+		Version int `bson:"Version"`
+	}
+	err := db.schemaCollection().FindOne(ctx, bson.M{"_id": ch}).Decode(&schema)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+
+	return schema.Version, err
+}
+
+func (db *DBmongo) SetSchemaVersion(ctx context.Context, ch string, version int) error {
+	_, err := db.schemaCollection().UpdateOne(ctx, bson.M{"_id": ch}, bson.M{"$set": bson.M{"Version": version}}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (db *DBmongo) Migrations() []Migration {
+	return []Migration{
+		{Version: 1, Description: "deterministic ids and writes of txs", Up: db.migrateLayout},
+		{Version: 2, Description: "composite key fields of key history and state", Up: db.splitCompositeKeys},
+		{Version: 3, Description: "tx positions in ids and payload text of txs", Up: db.migrateTxIDs},
+	}
+}
+
+// splitCompositeKeys fills object type and attributes of composite keys stored before they were split
+func (db *DBmongo) splitCompositeKeys(ctx context.Context, ch string) error {
+	filter := bson.M{"Key": prefixRange(compositeKeyNamespace), "ObjectType": bson.M{"$exists": false}}
+	for _, collection := range []*mongo.Collection{db.historyCollection(ch), db.stateCollection(ch)} {
+		cur, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"Key": 1}))
+		if err != nil {
+			return err
+		}
+
+		var batch []mongo.WriteModel
+		for cur.Next(ctx) {
+			var doc struct {
+				ID  interface{} `bson:"_id"`
+				Key string      `bson:"Key"`
+			}
+			if err = cur.Decode(&doc); err != nil {
+				break
+			}
+			objectType, attributes, ok := SplitCompositeKey(doc.Key)
+			if !ok {
+				continue
+			}
+			batch = append(batch, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": doc.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"ObjectType": objectType, "Attributes": attributes}}))
+			if len(batch) >= migrationBatchSize {
+				if _, err = collection.BulkWrite(ctx, batch); err != nil {
+					break
+				}
+				batch = nil
+			}
+		}
+		if err == nil {
+			err = cur.Err()
+		}
+		cur.Close(ctx)
+		if err != nil {
+			return err
+		}
+		if len(batch) != 0 {
+			if _, err = collection.BulkWrite(ctx, batch); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (db *DBmongo) ReplaceBlock(ctx context.Context, ch string, blocknum uint64, txs []Tx, history []KeyModification) error {
+	txIDs := make(bson.A, 0, len(txs))
+	for _, tx := range txs {
+		if err := db.Insert(ctx, ch, tx); err != nil {
+			return errors.Wrapf(err, "failed to store tx %s", tx.Txid)
+		}
+		txIDs = append(txIDs, txDocID(tx))
+	}
+	if err := db.InsertKeyHistory(ctx, ch, history); err != nil {
+		return errors.Wrap(err, "failed to store key history")
+	}
+
+	// documents of the block stored by older versions can have other IDs
+	if _, err := db.txCollection(ch).DeleteMany(ctx, bson.M{"Blocknum": blocknum, "_id": bson.M{"$nin": txIDs}}); err != nil {
+		return errors.Wrap(err, "failed to delete replaced txs")
+	}
+	historyIDs := make(bson.A, 0, len(history))
+	for _, mod := range history {
+		historyIDs = append(historyIDs, keyModificationDocID(mod))
+	}
+	_, err := db.historyCollection(ch).DeleteMany(ctx, bson.M{"Blocknum": blocknum, "_id": bson.M{"$nin": historyIDs}})
+	return errors.Wrap(err, "failed to delete replaced key history")
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (db *DBmongo) stateCollection(ch string) *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_%s_state", db.Collection, ch))
+}
+
+func (db *DBmongo) initState(ctx context.Context, ch string) error {
+	_, err := db.stateCollection(ch).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "Namespace", Value: 1}, {Key: "Key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return errors.Wrap(err, "failed to create state index")
+}
+
+func (db *DBmongo) ApplyState(ctx context.Context, ch string, writes []KeyModification) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(writes))
+	for _, w := range writes {
+		filter := bson.M{"Namespace": w.Namespace, "Key": w.Key}
+		if w.IsDelete {
+			models = append(models, mongo.NewDeleteOneModel().SetFilter(filter))
+			continue
+		}
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(w).SetUpsert(true))
+	}
+
+	// writes must be applied in order, because the same key can be changed by several txs of the block
+	_, err := db.stateCollection(ch).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	return err
+}
+
+func (db *DBmongo) GetState(ctx context.Context, ch, namespace, key string) (KeyModification, error) {
+	var mod KeyModification
+	err := db.stateCollection(ch).FindOne(ctx, bson.M{"Namespace": namespace, "Key": key}).Decode(&mod)
+	if err == mongo.ErrNoDocuments {
+		return mod, errors.New(NOT_FOUND_ERR)
+	}
+
+	return mod, err
+}
+
+func (db *DBmongo) GetStateAt(ctx context.Context, ch, namespace, key string, blocknum uint64) (KeyModification, error) {
+	var mod KeyModification
+	opts := options.FindOne().SetSort(bson.D{{Key: "Blocknum", Value: -1}, {Key: "TxNum", Value: -1}})
+	err := db.historyCollection(ch).FindOne(ctx, bson.M{"Namespace": namespace, "Key": key, "Blocknum": bson.M{"$lte": blocknum}}, opts).Decode(&mod)
+	if err == mongo.ErrNoDocuments || (err == nil && mod.IsDelete) {
+		return KeyModification{}, errors.New(NOT_FOUND_ERR)
+	}
+
+	return mod, err
+}
+
+// stateKeyFilter converts key conditions of the scan to mongo query
+func stateKeyFilter(namespace string, scan StateScan) (bson.M, error) {
+	cmp := "$gt"
+	if scan.Order == Descending {
+		cmp = "$lt"
+	}
+
+	conds := bson.A{bson.M{"Namespace": namespace}}
+	if scan.StartKey != "" {
+		conds = append(conds, bson.M{"Key": bson.M{"$gte": scan.StartKey}})
+	}
+	if scan.EndKey != "" {
+		conds = append(conds, bson.M{"Key": bson.M{"$lt": scan.EndKey}})
+	}
+	if scan.Prefix != "" {
+		conds = append(conds, bson.M{"Key": prefixRange(scan.Prefix)})
+	}
+	if scan.Cursor != "" {
+		last, err := base64.RawURLEncoding.DecodeString(scan.Cursor)
+		if err != nil {
+			return nil, InvalidCursor(err)
+		}
+		conds = append(conds, bson.M{"Key": bson.M{cmp: string(last)}})
+	}
+
+	return bson.M{"$and": conds}, nil
+}
+
+func (db *DBmongo) ScanState(ctx context.Context, ch, namespace string, scan StateScan) ([]KeyModification, string, error) {
+	filter, err := stateKeyFilter(namespace, scan)
+	if err != nil {
+		return nil, "", err
+	}
+	direction := 1
+	if scan.Order == Descending {
+		direction = -1
+	}
+	limit := scan.PageLimit()
+
+	var cur *mongo.Cursor
+	if scan.AsOfBlock == nil {
+		// request one extra key to find out whether the next page exists
+		opts := options.Find().SetSort(bson.D{{Key: "Key", Value: direction}}).SetLimit(limit + 1)
+		cur, err = db.stateCollection(ch).Find(ctx, filter, opts)
+	} else {
+		// the latest version of every key written before the block is taken from key history
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"$and": bson.A{filter, bson.M{"Blocknum": bson.M{"$lte": *scan.AsOfBlock}}}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "Key", Value: 1}, {Key: "Blocknum", Value: -1}, {Key: "TxNum", Value: -1}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$Key", "doc": bson.M{"$first": "$$ROOT"}}}},
+			{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+			{{Key: "$match", Value: bson.M{"IsDelete": false}}},
+			{{Key: "$sort", Value: bson.D{{Key: "Key", Value: direction}}}},
+			{{Key: "$limit", Value: limit + 1}},
+		}
+		cur, err = db.historyCollection(ch).Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var mods []KeyModification
+	if err = cur.All(ctx, &mods); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if int64(len(mods)) > limit {
+		mods = mods[:limit]
+		next = base64.RawURLEncoding.EncodeToString([]byte(mods[len(mods)-1].Key))
+	}
+
+	return mods, next, nil
+}
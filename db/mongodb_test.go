@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPageQuery(t *testing.T) {
+	filter, opts, err := pageQuery(bson.M{"Txid": "tx1"}, Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"Txid": "tx1"}, filter, "first page must not change filter")
+	assert.Equal(t, bson.D{{Key: "Blocknum", Value: 1}, {Key: "TxNum", Value: 1}, {Key: "_id", Value: 1}}, opts.Sort)
+
+	last := mongoCursor{ID: "tx1/fabcar", Blocknum: 7, TxNum: 2}
+	cursor, err := encodeMongoCursor(last)
+	assert.NoError(t, err)
+
+	filter, opts, err = pageQuery(bson.M{"Txid": "tx1"}, Page{Cursor: cursor, Order: Descending})
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "Blocknum", Value: -1}, {Key: "TxNum", Value: -1}, {Key: "_id", Value: -1}}, opts.Sort)
+	assert.Equal(t, bson.M{"$and": bson.A{
+		bson.M{"Txid": "tx1"},
+		bson.M{"$or": bson.A{
+			bson.M{"Blocknum": bson.M{"$lt": uint64(7)}},
+			bson.M{"Blocknum": uint64(7), "TxNum": bson.M{"$lt": uint64(2)}},
+			bson.M{"Blocknum": uint64(7), "TxNum": uint64(2), "_id": bson.M{"$lt": "tx1/fabcar"}},
+		}},
+	}}, filter)
+
+	_, _, err = pageQuery(bson.M{}, Page{Cursor: "not a cursor"})
+	assert.Error(t, err)
+}
+
+func TestPageLimit(t *testing.T) {
+	assert.EqualValues(t, DefaultPageLimit, Page{}.PageLimit())
+	assert.EqualValues(t, 5, Page{Limit: 5}.PageLimit())
+	assert.EqualValues(t, MaxPageLimit, Page{Limit: MaxPageLimit + 1}.PageLimit())
+}
+
+func TestMongoFilter(t *testing.T) {
+	assert.Equal(t, bson.M{}, mongoFilter(Filter{}))
+
+	code := int32(0)
+	assert.Equal(t, bson.M{"$and": bson.A{
+		bson.M{"Blocknum": bson.M{"$gte": uint64(2), "$lte": uint64(5)}},
+		bson.M{"ValidationCode": int32(0)},
+		bson.M{"Chaincode": "fabcar"},
+		bson.M{"Writes": bson.M{"$elemMatch": bson.M{"Key": bson.M{"$gte": "CAR.", "$lt": "CAR.\U0010ffff"}}}},
+		bson.M{"Documents": bson.M{"$elemMatch": bson.M{"Value.owner": "Tomoko"}}},
+	}}, mongoFilter(Filter{FromBlock: 2, ToBlock: 5, ValidationCode: &code, Chaincode: "fabcar", KeyPrefix: "CAR.",
+		Fields: []FieldFilter{{Path: "owner", Value: "Tomoko"}}}))
+}
+
+func TestMongoTx(t *testing.T) {
+	tx := Tx{ChannelId: "mychannel", Txid: "tx1", Blocknum: 3, TxNum: 1, Chaincode: "fabcar", Keys: []string{"CAR1"},
+		Payload: []byte(`[{"key":"CAR1","value":"eyJvd25lciI6IlRvbW9rbyJ9"}]`)}
+
+	doc, err := newMongoTx(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "tx1/3/1/fabcar", doc.ID)
+	assert.Equal(t, string(tx.Payload), doc.Payload)
+	assert.Equal(t, []mongoWrite{{Key: "CAR1", Value: []byte(`{"owner":"Tomoko"}`)}}, doc.Writes)
+
+	raw, err := bson.Marshal(doc)
+	assert.NoError(t, err)
+	restored, err := decodeMongoTx(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, tx, restored)
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (db *DBmongo) subscriptionsCollection() *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_subscriptions", db.Collection))
+}
+
+func (db *DBmongo) deadLettersCollection() *mongo.Collection {
+	return db.Instance.Database(db.DBname).Collection(fmt.Sprintf("%s_deadletters", db.Collection))
+}
+
+func (db *DBmongo) PutSubscription(ctx context.Context, sub Subscription) error {
+	_, err := db.subscriptionsCollection().ReplaceOne(ctx, bson.M{"_id": sub.ID}, sub, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (db *DBmongo) GetSubscription(ctx context.Context, id string) (Subscription, error) {
+	var sub Subscription
+	err := db.subscriptionsCollection().FindOne(ctx, bson.M{"_id": id}).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return sub, errors.New(NOT_FOUND_ERR)
+	}
+	return sub, err
+}
+
+func (db *DBmongo) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	cur, err := db.subscriptionsCollection().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "Created", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	subs := []Subscription{}
+	if err = cur.All(ctx, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (db *DBmongo) DeleteSubscription(ctx context.Context, id string) error {
+	res, err := db.subscriptionsCollection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New(NOT_FOUND_ERR)
+	}
+	_, err = db.deadLettersCollection().DeleteMany(ctx, bson.M{"SubscriptionID": id})
+	return err
+}
+
+func (db *DBmongo) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	_, err := db.deadLettersCollection().ReplaceOne(ctx, bson.M{"_id": letter.ID}, letter, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (db *DBmongo) ListDeadLetters(ctx context.Context, subscriptionID string) ([]DeadLetter, error) {
+	cur, err := db.deadLettersCollection().Find(ctx, bson.M{"SubscriptionID": subscriptionID},
+		options.Find().SetSort(bson.D{{Key: "Time", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	letters := []DeadLetter{}
+	if err = cur.All(ctx, &letters); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+func (db *DBmongo) DeleteDeadLetter(ctx context.Context, subscriptionID, id string) error {
+	res, err := db.deadLettersCollection().DeleteOne(ctx, bson.M{"_id": id, "SubscriptionID": subscriptionID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New(NOT_FOUND_ERR)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Pruner is implemented by backends supporting retention. World state isn't pruned, so state as of pruned blocks
+// is not available.
+type Pruner interface {
+	// PruneBlocks deletes txs and key history of blocks before the block, it returns the number of deleted txs
+	PruneBlocks(ctx context.Context, channel string, before uint64) (int64, error)
+	// PruneChaincodes deletes txs and key history of other chaincodes in blocks [from, to], config txs are kept.
+	// It returns the number of deleted txs.
+	PruneChaincodes(ctx context.Context, channel string, keep []string, from, to uint64) (int64, error)
+	// LastBlockBefore returns the last block having txs older than t, ok is false if there is no such block
+	LastBlockBefore(ctx context.Context, channel string, t time.Time) (blocknum uint64, ok bool, err error)
+}
+
+// RawBlockPruner is implemented by block archives supporting retention
+type RawBlockPruner interface {
+	// PruneRawBlocks deletes archived blocks before the block
+	PruneRawBlocks(ctx context.Context, channel string, before uint64) error
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RawBlockStore keeps marshaled blocks of channels
+type RawBlockStore interface {
+	// GetRawBlock returns marshaled common.Block, NOT_FOUND_ERR if the block isn't stored
+	GetRawBlock(ctx context.Context, ch string, blocknum uint64) ([]byte, error)
+}
+
+// RawBlockArchive stores marshaled blocks, storing the same block again replaces it
+type RawBlockArchive interface {
+	RawBlockStore
+	PutRawBlock(ctx context.Context, ch string, blocknum uint64, raw []byte) error
+}
+
+func compressBlock(raw []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressBlock(compressed []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress block")
+	}
+	defer r.Close()
+
+	raw, err := ioutil.ReadAll(r)
+	return raw, errors.Wrap(err, "failed to decompress block")
+}
+
+// FSArchive keeps compressed blocks in files <dir>/<channel>/<blocknum>.block.gz, dir can be a mounted object store
+type FSArchive struct {
+	dir string
+}
+
+func NewFSArchive(dir string) (*FSArchive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create archive directory")
+	}
+	return &FSArchive{dir: dir}, nil
+}
+
+func (a *FSArchive) path(ch string, blocknum uint64) string {
+	// zero padding keeps files in block order
+	return filepath.Join(a.dir, ch, fmt.Sprintf("%020d.block.gz", blocknum))
+}
+
+// PutRawBlock writes the block into a temporary file and renames it, so readers never get a partial block
+func (a *FSArchive) PutRawBlock(_ context.Context, ch string, blocknum uint64, raw []byte) error {
+	compressed, err := compressBlock(raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to compress block")
+	}
+
+	path := a.path(ch, blocknum)
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create channel directory")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".block-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create block file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(compressed); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write block file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write block file")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), path), "failed to write block file")
+}
+
+func (a *FSArchive) GetRawBlock(_ context.Context, ch string, blocknum uint64) ([]byte, error) {
+	compressed, err := ioutil.ReadFile(a.path(ch, blocknum))
+	if os.IsNotExist(err) {
+		return nil, errors.New(NOT_FOUND_ERR)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read block file")
+	}
+
+	return decompressBlock(compressed)
+}
+
+func (a *FSArchive) PruneRawBlocks(_ context.Context, ch string, before uint64) error {
+	files, err := ioutil.ReadDir(filepath.Join(a.dir, ch))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to list block files")
+	}
+
+	for _, file := range files {
+		blocknum, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), ".block.gz"), 10, 64)
+		if err != nil || blocknum >= before {
+			continue
+		}
+		if err = os.Remove(filepath.Join(a.dir, ch, file.Name())); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to remove block file")
+		}
+	}
+
+	return nil
+}
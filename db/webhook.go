@@ -0,0 +1,71 @@
+package db
+
+import "context"
+
+// SubscriptionStore keeps webhook subscriptions and their failed deliveries
+type SubscriptionStore interface {
+	// PutSubscription creates or replaces the subscription
+	PutSubscription(ctx context.Context, sub Subscription) error
+	// GetSubscription returns NOT_FOUND_ERR if the subscription doesn't exist
+	GetSubscription(ctx context.Context, id string) (Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+	// DeleteSubscription deletes the subscription with its dead letters, NOT_FOUND_ERR is returned if it doesn't exist
+	DeleteSubscription(ctx context.Context, id string) error
+	// AddDeadLetter stores the delivery which failed after all attempts
+	AddDeadLetter(ctx context.Context, letter DeadLetter) error
+	// ListDeadLetters returns dead letters of the subscription ordered by time
+	ListDeadLetters(ctx context.Context, subscriptionID string) ([]DeadLetter, error)
+	// DeleteDeadLetter returns NOT_FOUND_ERR if the dead letter doesn't exist
+	DeleteDeadLetter(ctx context.Context, subscriptionID, id string) error
+}
+
+// Subscription delivers txs of the channel matching the filter to the URL
+type Subscription struct {
+	ID      string `json:"id" bson:"_id"`
+	Channel string `json:"channel" bson:"Channel"`
+	URL     string `json:"url" bson:"URL"`
+	// Secret is the HMAC key signing payloads
+	Secret  string             `json:"secret,omitempty" bson:"Secret"`
+	Filter  SubscriptionFilter `json:"filter" bson:"Filter"`
+	Created int64              `json:"created" bson:"Created"`
+}
+
+// SubscriptionFilter selects txs like Filter, zero-valued conditions are ignored
+type SubscriptionFilter struct {
+	ValidationCode *int32 `json:"validationcode,omitempty" bson:"ValidationCode,omitempty"`
+	Chaincode      string `json:"chaincode,omitempty" bson:"Chaincode,omitempty"`
+	Key            string `json:"key,omitempty" bson:"Key,omitempty"`
+	KeyPrefix      string `json:"keyprefix,omitempty" bson:"KeyPrefix,omitempty"`
+	CreatorMSP     string `json:"creatormsp,omitempty" bson:"CreatorMSP,omitempty"`
+	TxType         string `json:"txtype,omitempty" bson:"TxType,omitempty"`
+}
+
+// Match checks tx against the filter
+func (f SubscriptionFilter) Match(tx Tx) bool {
+	return f.Filter().Match(tx)
+}
+
+// Filter returns the query filter selecting the same txs
+func (f SubscriptionFilter) Filter() Filter {
+	return Filter{
+		ValidationCode: f.ValidationCode,
+		Chaincode:      f.Chaincode,
+		Key:            f.Key,
+		KeyPrefix:      f.KeyPrefix,
+		CreatorMSP:     f.CreatorMSP,
+		TxType:         f.TxType,
+	}
+}
+
+// DeadLetter is the webhook delivery which failed after all attempts
+type DeadLetter struct {
+	ID             string `json:"id" bson:"_id"`
+	SubscriptionID string `json:"subscriptionid" bson:"SubscriptionID"`
+	Channel        string `json:"channel" bson:"Channel"`
+	Blocknum       uint64 `json:"blocknum" bson:"Blocknum"`
+	// Payload is the body of the delivery
+	Payload  []byte `json:"payload" bson:"Payload"`
+	Error    string `json:"error" bson:"Error"`
+	Attempts int    `json:"attempts" bson:"Attempts"`
+	Time     int64  `json:"time" bson:"Time"`
+}
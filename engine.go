@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/bus"
 	"github.com/hyperledger-labs/fabex/db"
 	"github.com/hyperledger-labs/fabex/helpers"
 	"github.com/hyperledger-labs/fabex/ledgerclient"
@@ -18,9 +20,12 @@ type Engine struct {
 	channelClient  *channel.Client
 	ledgerClient   *ledgerclient.CustomLedgerClient
 	channelContext fabctx.ChannelProvider
+	decoder        blockhandler.ValueDecoder
+	events         *bus.Bus
+	processors     []helpers.BlockProcessor
 }
 
-func engineCreator(sdk *fabsdk.FabricSDK, dbInstance db.Storage) func(ch, user, org string) (*Engine, error) {
+func engineCreator(sdk *fabsdk.FabricSDK, dbInstance db.Storage, decoder blockhandler.ValueDecoder, events *bus.Bus, processors ...helpers.BlockProcessor) func(ch, user, org string) (*Engine, error) {
 	return func(ch, user, org string) (*Engine, error) {
 		clientChannelContext := sdk.ChannelContext(ch, fabsdk.WithUser(user), fabsdk.WithOrg(org))
 		ledgerClient, err := ledger.New(clientChannelContext)
@@ -32,7 +37,8 @@ func engineCreator(sdk *fabsdk.FabricSDK, dbInstance db.Storage) func(ch, user,
 		if err != nil {
 			return nil, errors.WithStack(errors.Wrapf(err, "failed to create channel cient"))
 		}
-		return &Engine{db: dbInstance, channelClient: channelclient, ledgerClient: &ledgerclient.CustomLedgerClient{Client: ledgerClient}, channelContext: clientChannelContext}, nil
+		return &Engine{db: dbInstance, channelClient: channelclient, ledgerClient: &ledgerclient.CustomLedgerClient{Client: ledgerClient}, channelContext: clientChannelContext,
+			decoder: decoder, events: events, processors: processors}, nil
 	}
 }
 
@@ -42,9 +48,9 @@ func (e *Engine) Run(ctx context.Context) error {
 		return errors.WithStack(err)
 	}
 
-	if err := e.db.Init(ch.ChannelID()); err != nil {
+	if err := e.db.Init(ctx, ch.ChannelID()); err != nil {
 		return err
 	}
 
-	return helpers.Explore(ctx, e.channelContext, e.db, e.ledgerClient)
+	return helpers.Explore(ctx, e.channelContext, e.db, e.ledgerClient, e.decoder, e.events, e.processors...)
 }
@@ -24,23 +24,37 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/hyperledger-labs/fabex/log"
 
 	"github.com/hyperledger-labs/fabex/api/rest"
 
+	"github.com/hyperledger-labs/fabex/api/gateway"
+	"github.com/hyperledger-labs/fabex/api/graphql"
 	"github.com/hyperledger-labs/fabex/api/grpc"
 
 	"go.uber.org/zap"
 
 	fabconfig "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 
+	"github.com/hyperledger-labs/fabex/archive"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/bus"
 	"github.com/hyperledger-labs/fabex/config"
 	"github.com/hyperledger-labs/fabex/db"
 	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/retention"
+	"github.com/hyperledger-labs/fabex/sink"
+	"github.com/hyperledger-labs/fabex/state"
+	"github.com/hyperledger-labs/fabex/webhook"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/pkg/errors"
 )
 
+// shutdownTimeout limits time for closing database connections on exit
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// init configs
 	bootConf, err := config.GetBootConfig()
@@ -75,25 +89,158 @@ func main() {
 		}
 	}
 
-	// choose database
-	var dbInstance db.Storage
-	switch bootConf.Database {
-	case "mongo":
-		dbInstance = db.CreateDBConfMongo(conf.Mongo.Host, conf.Mongo.Port, conf.Mongo.Dbuser, conf.Mongo.Dbsecret, conf.Mongo.Dbname, conf.Mongo.Collection)
-		// here can be other storage options
+	// choose database serving APIs
+	read := conf.Fanout.Read
+	if read == "" {
+		read = bootConf.Database
+	}
+	dbInstance, err := newStorage(read, conf)
+	if err != nil {
+		l.Panic("failed to create database client", zap.Error(err))
 	}
 
-	err = dbInstance.Connect()
+	err = dbInstance.Connect(ctx)
 	if err != nil {
 		l.Panic("DB connection failed", zap.Error(err))
 	}
 	l.Info("Connected to database successfully")
 
+	// other storages, blocks are written into them through fan-out
+	var (
+		sinks    []sink.FanoutSink
+		storages []db.Storage
+	)
+	for _, name := range conf.Fanout.Storages {
+		storage, err := newStorage(name, conf)
+		if err != nil {
+			l.Panic("failed to create database client", zap.Error(err), zap.String("storage", name))
+		}
+		if err = storage.Connect(ctx); err != nil {
+			l.Panic("DB connection failed", zap.Error(err), zap.String("storage", name))
+		}
+		storages = append(storages, storage)
+		sinks = append(sinks, sink.FanoutSink{Name: name, Sink: sink.NewStorageSink(storage)})
+	}
+
+	// decoding of protobuf values
+	var decoder blockhandler.ValueDecoder
+	if len(conf.Protobuf) != 0 {
+		rules := make([]blockhandler.ProtoRule, 0, len(conf.Protobuf))
+		for _, p := range conf.Protobuf {
+			rules = append(rules, blockhandler.ProtoRule{Chaincode: p.Chaincode, KeyPattern: p.Keypattern, DescriptorSet: p.Descriptorset, Message: p.Message})
+		}
+		protoDecoder, err := blockhandler.NewProtoDecoder(rules)
+		if err != nil {
+			l.Panic("failed to load protobuf descriptors", zap.Error(err))
+		}
+		decoder = protoDecoder
+	}
+
+	// world state materialization
+	var (
+		processors []helpers.BlockProcessor
+		// consumers are checkpoint names of processors, their blocks are not pruned until processed
+		consumers []string
+	)
+	if conf.State.Enabled {
+		materializer, err := state.NewMaterializer(dbInstance)
+		if err != nil {
+			l.Error("world state is disabled", zap.Error(err))
+		} else {
+			processors = append(processors, materializer)
+			consumers = append(consumers, state.CheckpointName)
+		}
+	}
+
+	// archive of original blocks, it goes first, so archived blocks are available to other processors
+	var blocks db.RawBlockStore
+	if conf.Archive.Enabled {
+		var blockArchive db.RawBlockArchive
+		switch conf.Archive.Backend {
+		case "database":
+			var ok bool
+			if blockArchive, ok = dbInstance.(db.RawBlockArchive); !ok {
+				l.Panic("block archive is not supported by the database")
+			}
+		case "fs":
+			if blockArchive, err = db.NewFSArchive(conf.Archive.Path); err != nil {
+				l.Panic("failed to create block archive", zap.Error(err))
+			}
+		default:
+			l.Panic("unknown block archive backend", zap.String("backend", conf.Archive.Backend))
+		}
+		blocks = blockArchive
+		processors = append([]helpers.BlockProcessor{archive.NewProcessor(blockArchive)}, processors...)
+	}
+
+	// one-off re-deriving of stored blocks from the archive, see rederive
+	if len(os.Args) > 1 && os.Args[1] == "rederive" {
+		if err := rederive(ctx, os.Args[2:], append([]db.Storage{dbInstance}, storages...), blocks, decoder); err != nil {
+			l.Panic("re-deriving failed", zap.Error(err))
+		}
+		return
+	}
+
+	// export into object storage
+	if conf.S3.Enabled {
+		store, err := sink.NewS3Store(ctx, sink.S3Options{Endpoint: conf.S3.Endpoint, AccessKey: conf.S3.Accesskey, SecretKey: conf.S3.Secretkey,
+			Region: conf.S3.Region, UseSSL: conf.S3.Usessl, Bucket: conf.S3.Bucket})
+		if err != nil {
+			l.Panic("failed to connect to object storage", zap.Error(err))
+		}
+		objectSink, err := sink.NewObjectSink(dbInstance, blocks, store, sink.ObjectOptions{Name: "s3", Prefix: conf.S3.Prefix, Format: conf.S3.Format,
+			SegmentSize: conf.S3.Segmentsize})
+		if err != nil {
+			l.Panic("failed to create object storage sink", zap.Error(err))
+		}
+		sinks = append(sinks, sink.FanoutSink{Name: "s3", Sink: objectSink})
+	}
+
+	// publishing into kafka
+	if conf.Kafka.Enabled {
+		producer, err := sink.NewKafkaProducer(conf.Kafka.Brokers, conf.Kafka.Transactionalid)
+		if err != nil {
+			l.Panic("failed to connect to kafka", zap.Error(err))
+		}
+		defer producer.Close()
+		kafkaSink, err := sink.NewKafkaSink(producer, sink.KafkaOptions{Encoding: conf.Kafka.Encoding, Topics: sink.KafkaTopics{
+			Blocks: conf.Kafka.Blockstopic, Transactions: conf.Kafka.Txstopic, Writes: conf.Kafka.Writestopic, Events: conf.Kafka.Eventstopic}, Archived: blocks != nil})
+		if err != nil {
+			l.Panic("failed to create kafka sink", zap.Error(err))
+		}
+		sinks = append(sinks, sink.FanoutSink{Name: "kafka", Sink: kafkaSink})
+	}
+
+	// webhooks, subscriptions are managed by REST API only if they are enabled
+	var webhooks rest.Webhooks
+	if conf.Webhooks.Enabled {
+		dispatcher, err := webhook.NewDispatcher(dbInstance, webhook.Options{Attempts: conf.Webhooks.Attempts, RetryMin: conf.Webhooks.Retrymin,
+			RetryMax: conf.Webhooks.Retrymax, Timeout: conf.Webhooks.Timeout, QueueSize: conf.Webhooks.Queuesize,
+			AllowedNetworks: conf.Webhooks.Allowednetworks})
+		if err != nil {
+			l.Panic("failed to create webhook dispatcher", zap.Error(err))
+		}
+		webhooks = dispatcher
+		sinks = append(sinks, sink.FanoutSink{Name: "webhooks", Sink: dispatcher})
+	}
+
+	var fanout *sink.Fanout
+	if len(sinks) != 0 {
+		fanout = sink.NewFanout(dbInstance, blocks, sinks, sink.FanoutOptions{QueueSize: conf.Fanout.Queuesize})
+		processors = append(processors, fanout)
+		for _, s := range sinks {
+			consumers = append(consumers, sink.CheckpointName(s.Name))
+		}
+	}
+
+	// block committed events for gRPC subscriptions and the REST live feed
+	events := bus.New()
+
 	// engines for channels
-	ecr := engineCreator(sdk, dbInstance)
+	ecr := engineCreator(sdk, dbInstance, decoder, events, processors...)
 	var wg sync.WaitGroup
 	for _, ch := range conf.Fabric.Channels {
-		if err := dbInstance.Init(ch); err != nil {
+		if err := dbInstance.Init(ctx, ch); err != nil {
 			l.Error("engine error", zap.Error(err), zap.String("channel", ch))
 			continue
 		}
@@ -112,17 +259,79 @@ func main() {
 		}(ch, &wg)
 	}
 
+	if fanout != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fanout.Run(ctx)
+		}()
+	}
+
+	// pruning of old data
+	if len(conf.Retention.Channels) != 0 {
+		policies := make([]retention.Policy, 0, len(conf.Retention.Channels))
+		for _, p := range conf.Retention.Channels {
+			policies = append(policies, retention.Policy{Channel: p.Channel, KeepBlocks: p.Keepblocks,
+				KeepFor: time.Duration(p.Keepdays) * 24 * time.Hour, Chaincodes: p.Chaincodes})
+		}
+		pruner, err := retention.New(dbInstance, blocks, policies, consumers)
+		if err != nil {
+			l.Error("retention is disabled", zap.Error(err))
+		} else {
+			interval := conf.Retention.Interval
+			if interval <= 0 {
+				interval = time.Hour
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pruner.Run(ctx, interval)
+			}()
+		}
+	}
+
+	// JSON transcoding gateway of the gRPC service, the connection is established lazily when the server is up
+	grpcHost := conf.GRPCServer.Host
+	if grpcHost == "" || grpcHost == "0.0.0.0" {
+		grpcHost = "localhost"
+	}
+	gw, err := gateway.Connect(ctx, net.JoinHostPort(grpcHost, conf.GRPCServer.Port))
+	if err != nil {
+		l.Error("gateway is disabled", zap.Error(err))
+	}
+
+	graphQL, err := graphql.NewHandler(dbInstance, blocks, conf.Fabric.Channels)
+	if err != nil {
+		l.Panic("failed to create GraphQL handler", zap.Error(err))
+	}
+
 	l.Info("start REST server")
+	wg.Add(1)
 	go func() {
-		l.Panic("REST server error", zap.Error(rest.Run(dbInstance, conf.UI.Host, conf.UI.Port, bootConf.UI)))
+		defer wg.Done()
+		var health rest.HealthReporter
+		if fanout != nil {
+			health = fanout
+		}
+		if err := rest.Run(ctx, dbInstance, conf.Fabric.Channels, blocks, health, events, webhooks, gw, graphQL, conf.UI.Host, conf.UI.Port, bootConf.UI); err != nil {
+			l.Panic("REST server error", zap.Error(err))
+		}
 	}()
 	l.Info(fmt.Sprintf("REST server started on %s", net.JoinHostPort(conf.UI.Host, conf.UI.Port)))
 
 	// grpc server
 	l.Info("start GRPC server")
+	wg.Add(1)
 	go func() {
-		serv := grpc.NewFabexServer(conf.GRPCServer.Host, conf.GRPCServer.Port, dbInstance)
-		l.Panic("GRPC server error", zap.Error(grpc.StartGrpcServ(ctx, serv)))
+		defer wg.Done()
+		serv := grpc.NewFabexServer(conf.GRPCServer.Host, conf.GRPCServer.Port, dbInstance, blocks, events, grpc.Options{
+			Channels:      conf.Fabric.Channels,
+			MaxRange:      conf.GRPCServer.Maxrange,
+			StreamTimeout: conf.GRPCServer.Streamtimeout,
+		})
+		if err := grpc.StartGrpcServ(ctx, serv); err != nil {
+			l.Panic("GRPC server error", zap.Error(err))
+		}
 	}()
 	l.Info(fmt.Sprintf("GRPC server started on %s", net.JoinHostPort(conf.GRPCServer.Host, conf.GRPCServer.Port)))
 
@@ -132,4 +341,25 @@ func main() {
 	l.Info("os signal received, shutdown", zap.String("signal", s.String()))
 	cancel()
 	wg.Wait()
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer closeCancel()
+	for _, storage := range append(storages, dbInstance) {
+		if err := storage.Close(closeCtx); err != nil {
+			l.Error("failed to close database connection", zap.Error(err))
+		}
+	}
+}
+
+// newStorage creates client of the database, name is mongo or cassandra
+func newStorage(name string, conf *config.Config) (db.Storage, error) {
+	switch name {
+	case "mongo":
+		return db.NewMongo(db.MongoOptions{URI: conf.Mongo.Uri, Host: conf.Mongo.Host, Port: conf.Mongo.Port, User: conf.Mongo.Dbuser, Password: conf.Mongo.Dbsecret,
+			AuthSource: conf.Mongo.Authsource, TLS: conf.Mongo.Tls, TLSCAFile: conf.Mongo.Tlscafile, TLSInsecure: conf.Mongo.Tlsinsecure, DBname: conf.Mongo.Dbname, Collection: conf.Mongo.Collection})
+	case "cassandra":
+		return db.NewCassandraClient(conf.Cassandra.Host, conf.Cassandra.Dbuser, conf.Cassandra.Dbsecret, conf.Cassandra.Keyspace, conf.Cassandra.Columnfamily), nil
+	default:
+		return nil, errors.Errorf("unknown database %s", name)
+	}
 }
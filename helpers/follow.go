@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/bus"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+)
+
+// followPollInterval is the period of checking new blocks by followers without the event bus
+const followPollInterval = time.Second
+
+// StoredCheckpoint is the checkpoint of the last block whose txs and key history are completely stored
+const StoredCheckpoint = "stored"
+
+// Follow calls send with matching txs of stored blocks starting from the block, then with txs of new blocks.
+// Only complete blocks are sent, blocks without matching txs are skipped. It returns when ctx is done or send fails.
+// Storage is polled for new blocks if events is nil.
+func Follow(ctx context.Context, storage db.Storage, events *bus.Bus, ch string, filter db.Filter, from uint64,
+	send func(blocknum uint64, txs []db.Tx) error) error {
+	var committed <-chan bus.Event
+	if events != nil {
+		// a single buffered event is enough to wake up, the last block is taken from the bus
+		sub := events.Subscribe(bus.Options{Channel: ch, Buffer: 1, Policy: bus.DropOldest})
+		defer sub.Close()
+		committed = sub.Events()
+	}
+
+	next := from
+	for {
+		last, ok, err := lastStored(ctx, storage, events, ch)
+		if err != nil {
+			return err
+		}
+		if ok && last >= next {
+			if err = QueryBlocks(ctx, storage, ch, filter, next, last, send); err != nil {
+				return err
+			}
+			next = last + 1
+		}
+
+		wait := committed
+		if wait == nil {
+			poll := make(chan bus.Event)
+			time.AfterFunc(followPollInterval, func() { close(poll) })
+			wait = poll
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wait:
+		}
+	}
+}
+
+// lastStored returns the last complete block of the channel
+func lastStored(ctx context.Context, storage db.Storage, events *bus.Bus, ch string) (uint64, bool, error) {
+	if events != nil {
+		if last, ok := events.Last(ch); ok {
+			return last, true, nil
+		}
+	}
+
+	last, ok, err := storage.GetCheckpoint(ctx, ch, StoredCheckpoint)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to get the last stored block")
+	}
+	if ok {
+		return last, true, nil
+	}
+
+	// txs of the last block may be still being inserted by an explorer without the checkpoint
+	tx, err := storage.GetLastEntry(ctx, ch)
+	if err != nil {
+		if err.Error() == db.NOT_FOUND_ERR {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "failed to get last block")
+	}
+	if tx.Blocknum == 0 {
+		return 0, false, nil
+	}
+	return tx.Blocknum - 1, true, nil
+}
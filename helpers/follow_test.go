@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastStored(t *testing.T) {
+	ctx := context.Background()
+	storage := dbtest.New()
+	_, ok, err := lastStored(ctx, storage, nil, "mychannel")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// the last block may be partly stored without the checkpoint
+	storage.Add("mychannel", db.Tx{Txid: "tx0", Blocknum: 0})
+	_, ok, err = lastStored(ctx, storage, nil, "mychannel")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	storage.Add("mychannel", db.Tx{Txid: "tx1", Blocknum: 1})
+	last, ok, err := lastStored(ctx, storage, nil, "mychannel")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), last)
+
+	require.NoError(t, storage.SetCheckpoint(ctx, "mychannel", StoredCheckpoint, 1))
+	last, _, err = lastStored(ctx, storage, nil, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), last)
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 	"unicode/utf8"
 
 	"go.uber.org/zap"
@@ -28,6 +29,7 @@ import (
 	fabctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 
 	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/bus"
 	"github.com/hyperledger-labs/fabex/db"
 	"github.com/hyperledger-labs/fabex/models"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
@@ -42,7 +44,17 @@ import (
 
 const NOT_FOUND_ERR = "not found"
 
-func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db.Storage, lClient blockhandler.LedgerClient) error {
+// blockWriteTimeout limits time of storing all txs of a single block
+const blockWriteTimeout = 30 * time.Second
+
+// BlockProcessor handles every block after its txs are stored
+type BlockProcessor interface {
+	ProcessBlock(ctx context.Context, channel string, block *blockhandler.CustomBlock) error
+}
+
+// Explore stores blocks of the channel starting from the last stored one, decoder is used to decode write values
+// into documents and can be nil, block committed events are published to events after processors if it isn't nil
+func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db.Storage, lClient blockhandler.LedgerClient, decoder blockhandler.ValueDecoder, events *bus.Bus, processors ...BlockProcessor) error {
 	l, ok := ctx.Value("log").(*zap.Logger)
 	if !ok {
 		return errors.WithStack(errors.New("failed to get logger from context"))
@@ -62,7 +74,7 @@ func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db
 		return errors.WithStack(err)
 	}
 
-	txs, err := database.QueryBlockByHash(chclient.ChannelID(), currentHash)
+	txs, err := database.QueryBlockByHash(ctx, chclient.ChannelID(), currentHash)
 	if err != nil {
 		if err.Error() != "not found" && err.Error() != "sql: no rows in result set" && err.Error() != "mongo: no documents in result" {
 			return err
@@ -71,7 +83,7 @@ func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db
 
 	if txs == nil {
 		// find latest tx in db
-		lastTx, err := database.GetLastEntry(chclient.ChannelID())
+		lastTx, err := database.GetLastEntry(ctx, chclient.ChannelID())
 		if err != nil && err.Error() != NOT_FOUND_ERR {
 			return errors.Wrap(err, "Can't to get last block")
 		}
@@ -104,13 +116,24 @@ func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db
 		}()
 
 		// insert missing blocks/txs into db
-		for ctx.Err() == nil {
-			blockEvent, ok := <-notifier
-			if !ok {
-				break
+	loop:
+		for {
+			var blockEvent *fab.BlockEvent
+			select {
+			case <-ctx.Done():
+				break loop
+			case ev, ok := <-notifier:
+				if !ok {
+					break loop
+				}
+				blockEvent = ev
 			}
 
-			customBlock, err := blockhandler.HandleBlock(blockEvent.Block)
+			var decoders []blockhandler.ValueDecoder
+			if decoder != nil {
+				decoders = append(decoders, decoder)
+			}
+			customBlock, err := blockhandler.HandleBlock(blockEvent.Block, decoders...)
 			if err != nil {
 				return errors.Wrap(err, "GetBlock error")
 			}
@@ -119,13 +142,40 @@ func Explore(ctx context.Context, chprovider fabctx.ChannelProvider, database db
 				break
 			}
 
+			// a block that is already being written must not be cut in half by shutdown,
+			// otherwise its remaining txs would be skipped after restart
+			writeCtx, cancel := context.WithTimeout(context.Background(), blockWriteTimeout)
 			for _, tx := range customBlock.Txs {
-				err = database.Insert(chclient.ChannelID(), tx)
+				err = database.Insert(writeCtx, chclient.ChannelID(), tx)
 				if err != nil {
+					cancel()
 					return err
 				}
 				l.Debug("add tx", zap.String("channel", chclient.ChannelID()), zap.Uint64("block number", blockEvent.Block.Header.Number), zap.String("tx ID", tx.Txid))
 			}
+			err = database.InsertKeyHistory(writeCtx, chclient.ChannelID(), customBlock.KeyHistory)
+			if err != nil {
+				cancel()
+				return errors.Wrap(err, "failed to update key history")
+			}
+			if err = database.SetCheckpoint(writeCtx, chclient.ChannelID(), StoredCheckpoint, customBlock.Number); err != nil {
+				cancel()
+				return errors.Wrap(err, "failed to mark the block stored")
+			}
+			for _, p := range processors {
+				if err = p.ProcessBlock(writeCtx, chclient.ChannelID(), customBlock); err != nil {
+					cancel()
+					return errors.Wrapf(err, "failed to process block %d", customBlock.Number)
+				}
+			}
+			cancel()
+
+			// publishing fails only on shutdown while waiting for a blocking subscriber
+			if events != nil {
+				if err = events.Publish(ctx, bus.Event{Channel: chclient.ChannelID(), Block: customBlock}); err != nil {
+					break loop
+				}
+			}
 		}
 		l.Info("stop expoler", zap.String("channel", chclient.ChannelID()))
 	}
@@ -189,8 +239,13 @@ func PackTxsToBlocks(blocks []db.Tx) ([]models.Block, error) {
 			return nil, err
 		}
 
+		documents := make(map[string]map[string]interface{}, len(in.Documents))
+		for _, doc := range in.Documents {
+			documents[doc.Key] = doc.Value
+		}
+
 		for _, item := range ccData {
-			tx.KV = append(tx.KV, models.WriteKV{item.Key, item.Value})
+			tx.KV = append(tx.KV, models.WriteKV{Key: item.Key, Value: item.Value, IsDelete: item.IsDelete, Document: documents[item.Key]})
 		}
 
 		block.Txs = append(block.Txs, tx)
@@ -211,12 +266,12 @@ func CreateCompositeKey(objectType string, attributes []string) (string, error)
 	if err := validateCompositeKeyAttribute(objectType); err != nil {
 		return "", err
 	}
-	ck := compositeKeyNamespace + objectType + string(minUnicodeRuneValue)
+	ck := compositeKeyNamespace + objectType + string(rune(minUnicodeRuneValue))
 	for _, att := range attributes {
 		if err := validateCompositeKeyAttribute(att); err != nil {
 			return "", err
 		}
-		ck += att + string(minUnicodeRuneValue)
+		ck += att + string(rune(minUnicodeRuneValue))
 	}
 	return ck, nil
 }
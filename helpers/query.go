@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+)
+
+// QueryBlocks calls send with matching txs of stored blocks [from, to] grouped by block in ascending order,
+// blocks without matching txs are skipped
+func QueryBlocks(ctx context.Context, storage db.Storage, ch string, filter db.Filter, from, to uint64,
+	send func(blocknum uint64, txs []db.Tx) error) error {
+	var (
+		block []db.Tx
+		err   error
+	)
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		err := send(block[0].Blocknum, block)
+		block = nil
+		return err
+	}
+	add := func(txs []db.Tx) error {
+		for _, tx := range txs {
+			if len(block) != 0 && block[0].Blocknum != tx.Blocknum {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			block = append(block, tx)
+		}
+		return nil
+	}
+
+	// zero ToBlock of the filter means no upper bound
+	if to == 0 {
+		txs, err := storage.GetByBlocknum(ctx, ch, 0)
+		if err != nil && err.Error() != db.NOT_FOUND_ERR {
+			return errors.Wrap(err, "failed to get txs of block 0")
+		}
+		for _, tx := range txs {
+			if filter.Match(tx) {
+				block = append(block, tx)
+			}
+		}
+		return flush()
+	}
+
+	filter.FromBlock, filter.ToBlock = from, to
+	filter.Page = db.Page{Limit: db.MaxPageLimit}
+	for {
+		var (
+			txs  []db.Tx
+			next string
+		)
+		if txs, next, err = storage.Query(ctx, ch, filter); err != nil {
+			return errors.Wrapf(err, "failed to query blocks %d-%d", from, to)
+		}
+		if err = add(txs); err != nil {
+			return err
+		}
+		if next == "" || len(txs) == 0 {
+			return flush()
+		}
+		filter.Cursor = next
+	}
+}
@@ -19,6 +19,16 @@ package models
 type WriteKV struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// IsDelete is set for writes deleting the key
+	IsDelete bool `json:"isdelete,omitempty"`
+	// Document is the value decoded from JSON, it's empty for values of other formats
+	Document map[string]interface{} `json:"document,omitempty"`
+}
+
+// Deleted reports if the write deletes the key. Writes stored before IsDelete was kept have no flag, Fabric doesn't
+// allow empty values, so their empty value means delete.
+func (kv WriteKV) Deleted() bool {
+	return kv.IsDelete || kv.Value == ""
 }
 
 type Block struct {
@@ -1,2 +1,3 @@
 #!/bin/bash
-protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ./fabex.proto
\ No newline at end of file
+protoc -I . -I third_party --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+  --grpc-gateway_out=. --grpc-gateway_opt=paths=source_relative ./fabex.proto ./v2/fabex.proto
@@ -0,0 +1,54 @@
+package proto
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger-labs/fabex/db"
+)
+
+// EntryFromTx converts the stored tx into the API entry
+func EntryFromTx(tx db.Tx) *Entry {
+	return &Entry{
+		Channelid:      tx.ChannelId,
+		Txid:           tx.Txid,
+		Hash:           tx.Hash,
+		Previoushash:   tx.PreviousHash,
+		Blocknum:       tx.Blocknum,
+		Txnum:          tx.TxNum,
+		Payload:        tx.Payload,
+		Time:           tx.Time,
+		Validationcode: tx.ValidationCode,
+		Chaincode:      tx.Chaincode,
+		Creatormsp:     tx.CreatorMSP,
+		Txtype:         tx.TxType,
+		Keys:           tx.Keys,
+		Documents:      documentsFromDB(tx.Documents),
+	}
+}
+
+func documentsFromDB(docs []db.Document) []*Document {
+	var out []*Document
+	for _, doc := range docs {
+		// documents are decoded from JSON, so they are always marshaled back
+		value, _ := json.Marshal(doc.Value)
+		out = append(out, &Document{Key: doc.Key, Value: value})
+	}
+	return out
+}
+
+// KeyModificationFromDB converts the stored key modification into the API message
+func KeyModificationFromDB(mod db.KeyModification) *KeyModification {
+	return &KeyModification{
+		Channelid:  mod.ChannelId,
+		Namespace:  mod.Namespace,
+		Key:        mod.Key,
+		Blocknum:   mod.Blocknum,
+		Txnum:      mod.TxNum,
+		Txid:       mod.Txid,
+		Value:      mod.Value,
+		Isdelete:   mod.IsDelete,
+		Time:       mod.Time,
+		Objecttype: mod.ObjectType,
+		Attributes: mod.Attributes,
+	}
+}
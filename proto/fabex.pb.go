@@ -10,8 +10,10 @@ import (
 	reflect "reflect"
 	sync "sync"
 
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 const (
@@ -21,18 +23,63 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type RequestRange struct {
+type SortOrder int32
+
+const (
+	SortOrder_ASC  SortOrder = 0
+	SortOrder_DESC SortOrder = 1
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "ASC",
+		1: "DESC",
+	}
+	SortOrder_value = map[string]int32{
+		"ASC":  0,
+		"DESC": 1,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_fabex_proto_enumTypes[0].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_fabex_proto_enumTypes[0]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{0}
+}
+
+type RequestTransaction struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Channelid  string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
-	Startblock int64  `protobuf:"varint,2,opt,name=startblock,proto3" json:"startblock,omitempty"`
-	Endblock   int64  `protobuf:"varint,3,opt,name=endblock,proto3" json:"endblock,omitempty"`
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Txid      string `protobuf:"bytes,2,opt,name=txid,proto3" json:"txid,omitempty"`
 }
 
-func (x *RequestRange) Reset() {
-	*x = RequestRange{}
+func (x *RequestTransaction) Reset() {
+	*x = RequestTransaction{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_fabex_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -40,13 +87,13 @@ func (x *RequestRange) Reset() {
 	}
 }
 
-func (x *RequestRange) String() string {
+func (x *RequestTransaction) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RequestRange) ProtoMessage() {}
+func (*RequestTransaction) ProtoMessage() {}
 
-func (x *RequestRange) ProtoReflect() protoreflect.Message {
+func (x *RequestTransaction) ProtoReflect() protoreflect.Message {
 	mi := &file_fabex_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -58,64 +105,107 @@ func (x *RequestRange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RequestRange.ProtoReflect.Descriptor instead.
-func (*RequestRange) Descriptor() ([]byte, []int) {
+// Deprecated: Use RequestTransaction.ProtoReflect.Descriptor instead.
+func (*RequestTransaction) Descriptor() ([]byte, []int) {
 	return file_fabex_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *RequestRange) GetChannelid() string {
+func (x *RequestTransaction) GetChannelid() string {
 	if x != nil {
 		return x.Channelid
 	}
 	return ""
 }
 
-func (x *RequestRange) GetStartblock() int64 {
+func (x *RequestTransaction) GetTxid() string {
 	if x != nil {
-		return x.Startblock
+		return x.Txid
 	}
-	return 0
+	return ""
 }
 
-func (x *RequestRange) GetEndblock() int64 {
+// Transaction lists entries of the tx, one entry per chaincode written by the tx
+type Transaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Txid    string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Entries []*Entry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Transaction) GetTxid() string {
 	if x != nil {
-		return x.Endblock
+		return x.Txid
 	}
-	return 0
+	return ""
 }
 
-type Entry struct {
+func (x *Transaction) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type RequestBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Channelid      string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
-	Txid           string `protobuf:"bytes,2,opt,name=txid,proto3" json:"txid,omitempty"`
-	Hash           string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
-	Previoushash   string `protobuf:"bytes,4,opt,name=previoushash,proto3" json:"previoushash,omitempty"`
-	Blocknum       uint64 `protobuf:"varint,5,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
-	Payload        []byte `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
-	Time           int64  `protobuf:"varint,7,opt,name=time,proto3" json:"time,omitempty"`
-	Validationcode int32  `protobuf:"varint,8,opt,name=validationcode,proto3" json:"validationcode,omitempty"`
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Blocknum  uint64 `protobuf:"varint,2,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
 }
 
-func (x *Entry) Reset() {
-	*x = Entry{}
+func (x *RequestBlock) Reset() {
+	*x = RequestBlock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fabex_proto_msgTypes[1]
+		mi := &file_fabex_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Entry) String() string {
+func (x *RequestBlock) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Entry) ProtoMessage() {}
+func (*RequestBlock) ProtoMessage() {}
 
-func (x *Entry) ProtoReflect() protoreflect.Message {
-	mi := &file_fabex_proto_msgTypes[1]
+func (x *RequestBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -126,141 +216,2171 @@ func (x *Entry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
-func (*Entry) Descriptor() ([]byte, []int) {
-	return file_fabex_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use RequestBlock.ProtoReflect.Descriptor instead.
+func (*RequestBlock) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Entry) GetChannelid() string {
+func (x *RequestBlock) GetChannelid() string {
 	if x != nil {
 		return x.Channelid
 	}
 	return ""
 }
 
-func (x *Entry) GetTxid() string {
+func (x *RequestBlock) GetBlocknum() uint64 {
 	if x != nil {
-		return x.Txid
+		return x.Blocknum
+	}
+	return 0
+}
+
+type Block struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid    string   `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Blocknum     uint64   `protobuf:"varint,2,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
+	Hash         string   `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Previoushash string   `protobuf:"bytes,4,opt,name=previoushash,proto3" json:"previoushash,omitempty"`
+	Entries      []*Entry `protobuf:"bytes,5,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Block) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
 	}
 	return ""
 }
 
-func (x *Entry) GetHash() string {
+func (x *Block) GetBlocknum() uint64 {
+	if x != nil {
+		return x.Blocknum
+	}
+	return 0
+}
+
+func (x *Block) GetHash() string {
 	if x != nil {
 		return x.Hash
 	}
 	return ""
 }
 
-func (x *Entry) GetPrevioushash() string {
+func (x *Block) GetPrevioushash() string {
 	if x != nil {
 		return x.Previoushash
 	}
 	return ""
 }
 
-func (x *Entry) GetBlocknum() uint64 {
+func (x *Block) GetEntries() []*Entry {
 	if x != nil {
-		return x.Blocknum
+		return x.Entries
 	}
-	return 0
+	return nil
 }
 
-func (x *Entry) GetPayload() []byte {
+type RequestChainInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+}
+
+func (x *RequestChainInfo) Reset() {
+	*x = RequestChainInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestChainInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestChainInfo) ProtoMessage() {}
+
+func (x *RequestChainInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestChainInfo.ProtoReflect.Descriptor instead.
+func (*RequestChainInfo) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RequestChainInfo) GetChannelid() string {
 	if x != nil {
-		return x.Payload
+		return x.Channelid
 	}
-	return nil
+	return ""
 }
 
-func (x *Entry) GetTime() int64 {
+// ChainInfo describes the stored part of the channel ledger
+type ChainInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	// number of the last stored block + 1, 0 if there are no blocks
+	Height            uint64 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Currentblockhash  string `protobuf:"bytes,3,opt,name=currentblockhash,proto3" json:"currentblockhash,omitempty"`
+	Previousblockhash string `protobuf:"bytes,4,opt,name=previousblockhash,proto3" json:"previousblockhash,omitempty"`
+}
+
+func (x *ChainInfo) Reset() {
+	*x = ChainInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChainInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainInfo) ProtoMessage() {}
+
+func (x *ChainInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainInfo.ProtoReflect.Descriptor instead.
+func (*ChainInfo) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ChainInfo) GetChannelid() string {
 	if x != nil {
-		return x.Time
+		return x.Channelid
 	}
-	return 0
+	return ""
 }
 
-func (x *Entry) GetValidationcode() int32 {
+func (x *ChainInfo) GetHeight() uint64 {
 	if x != nil {
-		return x.Validationcode
+		return x.Height
 	}
 	return 0
 }
 
-var File_fabex_proto protoreflect.FileDescriptor
-
-var file_fabex_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x66,
-	0x61, 0x62, 0x65, 0x78, 0x22, 0x68, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52,
-	0x61, 0x6e, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
-	0x69, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0xe3,
-	0x01, 0x0a, 0x05, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e,
-	0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61,
-	0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61,
-	0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x22,
-	0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x68, 0x61,
-	0x73, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x12, 0x18,
-	0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0e,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x08,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x63, 0x6f, 0x64, 0x65, 0x32, 0x5d, 0x0a, 0x05, 0x46, 0x61, 0x62, 0x65, 0x78, 0x12, 0x23, 0x0a,
-	0x03, 0x47, 0x65, 0x74, 0x12, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x1a, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x30, 0x01, 0x12, 0x2f, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x13,
-	0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x61,
-	0x6e, 0x67, 0x65, 0x1a, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x30, 0x01, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2d, 0x6c, 0x61,
-	0x62, 0x73, 0x2f, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *ChainInfo) GetCurrentblockhash() string {
+	if x != nil {
+		return x.Currentblockhash
+	}
+	return ""
 }
 
-var (
-	file_fabex_proto_rawDescOnce sync.Once
-	file_fabex_proto_rawDescData = file_fabex_proto_rawDesc
-)
+func (x *ChainInfo) GetPreviousblockhash() string {
+	if x != nil {
+		return x.Previousblockhash
+	}
+	return ""
+}
 
-func file_fabex_proto_rawDescGZIP() []byte {
-	file_fabex_proto_rawDescOnce.Do(func() {
-		file_fabex_proto_rawDescData = protoimpl.X.CompressGZIP(file_fabex_proto_rawDescData)
-	})
-	return file_fabex_proto_rawDescData
+type RequestChannels struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-var file_fabex_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_fabex_proto_goTypes = []interface{}{
-	(*RequestRange)(nil), // 0: fabex.RequestRange
-	(*Entry)(nil),        // 1: fabex.Entry
+func (x *RequestChannels) Reset() {
+	*x = RequestChannels{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_fabex_proto_depIdxs = []int32{
-	1, // 0: fabex.Fabex.Get:input_type -> fabex.Entry
-	0, // 1: fabex.Fabex.GetRange:input_type -> fabex.RequestRange
-	1, // 2: fabex.Fabex.Get:output_type -> fabex.Entry
-	1, // 3: fabex.Fabex.GetRange:output_type -> fabex.Entry
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *RequestChannels) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_fabex_proto_init() }
-func file_fabex_proto_init() {
-	if File_fabex_proto != nil {
-		return
+func (*RequestChannels) ProtoMessage() {}
+
+func (x *RequestChannels) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestChannels.ProtoReflect.Descriptor instead.
+func (*RequestChannels) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{6}
+}
+
+type Channels struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*ChainInfo `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *Channels) Reset() {
+	*x = Channels{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Channels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Channels) ProtoMessage() {}
+
+func (x *Channels) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Channels.ProtoReflect.Descriptor instead.
+func (*Channels) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Channels) GetChannels() []*ChainInfo {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type RequestRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid  string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Startblock int64  `protobuf:"varint,2,opt,name=startblock,proto3" json:"startblock,omitempty"`
+	Endblock   int64  `protobuf:"varint,3,opt,name=endblock,proto3" json:"endblock,omitempty"`
+}
+
+func (x *RequestRange) Reset() {
+	*x = RequestRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRange) ProtoMessage() {}
+
+func (x *RequestRange) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRange.ProtoReflect.Descriptor instead.
+func (*RequestRange) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RequestRange) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestRange) GetStartblock() int64 {
+	if x != nil {
+		return x.Startblock
+	}
+	return 0
+}
+
+func (x *RequestRange) GetEndblock() int64 {
+	if x != nil {
+		return x.Endblock
+	}
+	return 0
+}
+
+type Entry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid      string      `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Txid           string      `protobuf:"bytes,2,opt,name=txid,proto3" json:"txid,omitempty"`
+	Hash           string      `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Previoushash   string      `protobuf:"bytes,4,opt,name=previoushash,proto3" json:"previoushash,omitempty"`
+	Blocknum       uint64      `protobuf:"varint,5,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
+	Payload        []byte      `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	Time           int64       `protobuf:"varint,7,opt,name=time,proto3" json:"time,omitempty"`
+	Validationcode int32       `protobuf:"varint,8,opt,name=validationcode,proto3" json:"validationcode,omitempty"`
+	Chaincode      string      `protobuf:"bytes,9,opt,name=chaincode,proto3" json:"chaincode,omitempty"`
+	Creatormsp     string      `protobuf:"bytes,10,opt,name=creatormsp,proto3" json:"creatormsp,omitempty"`
+	Txtype         string      `protobuf:"bytes,11,opt,name=txtype,proto3" json:"txtype,omitempty"`
+	Keys           []string    `protobuf:"bytes,12,rep,name=keys,proto3" json:"keys,omitempty"`
+	Documents      []*Document `protobuf:"bytes,13,rep,name=documents,proto3" json:"documents,omitempty"`
+	// position of the tx in the block
+	Txnum uint64 `protobuf:"varint,14,opt,name=txnum,proto3" json:"txnum,omitempty"`
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Entry) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *Entry) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *Entry) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Entry) GetPrevioushash() string {
+	if x != nil {
+		return x.Previoushash
+	}
+	return ""
+}
+
+func (x *Entry) GetBlocknum() uint64 {
+	if x != nil {
+		return x.Blocknum
+	}
+	return 0
+}
+
+func (x *Entry) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Entry) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Entry) GetValidationcode() int32 {
+	if x != nil {
+		return x.Validationcode
+	}
+	return 0
+}
+
+func (x *Entry) GetChaincode() string {
+	if x != nil {
+		return x.Chaincode
+	}
+	return ""
+}
+
+func (x *Entry) GetCreatormsp() string {
+	if x != nil {
+		return x.Creatormsp
+	}
+	return ""
+}
+
+func (x *Entry) GetTxtype() string {
+	if x != nil {
+		return x.Txtype
+	}
+	return ""
+}
+
+func (x *Entry) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *Entry) GetDocuments() []*Document {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+func (x *Entry) GetTxnum() uint64 {
+	if x != nil {
+		return x.Txnum
+	}
+	return 0
+}
+
+// Document is a write value decoded from JSON
+type Document struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// JSON object
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Document) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Document) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// RequestPage requests a page of channel entries, entries are filtered by payload if it is specified
+type RequestPage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Payload   []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Pagesize  int64  `protobuf:"varint,3,opt,name=pagesize,proto3" json:"pagesize,omitempty"`
+	// token returned in Page.nextpagetoken of the previous response, empty for the first page
+	Pagetoken string    `protobuf:"bytes,4,opt,name=pagetoken,proto3" json:"pagetoken,omitempty"`
+	Order     SortOrder `protobuf:"varint,5,opt,name=order,proto3,enum=fabex.SortOrder" json:"order,omitempty"`
+}
+
+func (x *RequestPage) Reset() {
+	*x = RequestPage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestPage) ProtoMessage() {}
+
+func (x *RequestPage) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestPage.ProtoReflect.Descriptor instead.
+func (*RequestPage) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RequestPage) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestPage) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *RequestPage) GetPagesize() int64 {
+	if x != nil {
+		return x.Pagesize
+	}
+	return 0
+}
+
+func (x *RequestPage) GetPagetoken() string {
+	if x != nil {
+		return x.Pagetoken
+	}
+	return ""
+}
+
+func (x *RequestPage) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_ASC
+}
+
+type Page struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// empty if there are no more entries
+	Nextpagetoken string `protobuf:"bytes,2,opt,name=nextpagetoken,proto3" json:"nextpagetoken,omitempty"`
+}
+
+func (x *Page) Reset() {
+	*x = Page{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Page) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Page) ProtoMessage() {}
+
+func (x *Page) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Page.ProtoReflect.Descriptor instead.
+func (*Page) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Page) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *Page) GetNextpagetoken() string {
+	if x != nil {
+		return x.Nextpagetoken
+	}
+	return ""
+}
+
+// RequestQuery requests a page of channel entries matching all specified filters, empty filters are ignored
+type RequestQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Fromblock uint64 `protobuf:"varint,2,opt,name=fromblock,proto3" json:"fromblock,omitempty"`
+	// inclusive, 0 means no upper bound
+	Toblock uint64 `protobuf:"varint,3,opt,name=toblock,proto3" json:"toblock,omitempty"`
+	// unix time in seconds, inclusive
+	Fromtime       int64                  `protobuf:"varint,4,opt,name=fromtime,proto3" json:"fromtime,omitempty"`
+	Totime         int64                  `protobuf:"varint,5,opt,name=totime,proto3" json:"totime,omitempty"`
+	Validationcode *wrapperspb.Int32Value `protobuf:"bytes,6,opt,name=validationcode,proto3" json:"validationcode,omitempty"`
+	Chaincode      string                 `protobuf:"bytes,7,opt,name=chaincode,proto3" json:"chaincode,omitempty"`
+	Key            string                 `protobuf:"bytes,8,opt,name=key,proto3" json:"key,omitempty"`
+	Keyprefix      string                 `protobuf:"bytes,9,opt,name=keyprefix,proto3" json:"keyprefix,omitempty"`
+	Creatormsp     string                 `protobuf:"bytes,10,opt,name=creatormsp,proto3" json:"creatormsp,omitempty"`
+	Txtype         string                 `protobuf:"bytes,11,opt,name=txtype,proto3" json:"txtype,omitempty"`
+	Pagesize       int64                  `protobuf:"varint,12,opt,name=pagesize,proto3" json:"pagesize,omitempty"`
+	Pagetoken      string                 `protobuf:"bytes,13,opt,name=pagetoken,proto3" json:"pagetoken,omitempty"`
+	Order          SortOrder              `protobuf:"varint,14,opt,name=order,proto3,enum=fabex.SortOrder" json:"order,omitempty"`
+	// document field filters, dot-separated path to value, e.g. owner: Tomoko
+	Fields map[string]string `protobuf:"bytes,15,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *RequestQuery) Reset() {
+	*x = RequestQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestQuery) ProtoMessage() {}
+
+func (x *RequestQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestQuery.ProtoReflect.Descriptor instead.
+func (*RequestQuery) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RequestQuery) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetFromblock() uint64 {
+	if x != nil {
+		return x.Fromblock
+	}
+	return 0
+}
+
+func (x *RequestQuery) GetToblock() uint64 {
+	if x != nil {
+		return x.Toblock
+	}
+	return 0
+}
+
+func (x *RequestQuery) GetFromtime() int64 {
+	if x != nil {
+		return x.Fromtime
+	}
+	return 0
+}
+
+func (x *RequestQuery) GetTotime() int64 {
+	if x != nil {
+		return x.Totime
+	}
+	return 0
+}
+
+func (x *RequestQuery) GetValidationcode() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Validationcode
+	}
+	return nil
+}
+
+func (x *RequestQuery) GetChaincode() string {
+	if x != nil {
+		return x.Chaincode
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetKeyprefix() string {
+	if x != nil {
+		return x.Keyprefix
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetCreatormsp() string {
+	if x != nil {
+		return x.Creatormsp
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetTxtype() string {
+	if x != nil {
+		return x.Txtype
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetPagesize() int64 {
+	if x != nil {
+		return x.Pagesize
+	}
+	return 0
+}
+
+func (x *RequestQuery) GetPagetoken() string {
+	if x != nil {
+		return x.Pagetoken
+	}
+	return ""
+}
+
+func (x *RequestQuery) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_ASC
+}
+
+func (x *RequestQuery) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// RequestKeyHistory requests a page of versions of the world state key written by valid txs
+type RequestKeyHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	// chaincode name
+	Namespace string    `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Key       string    `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Pagesize  int64     `protobuf:"varint,4,opt,name=pagesize,proto3" json:"pagesize,omitempty"`
+	Pagetoken string    `protobuf:"bytes,5,opt,name=pagetoken,proto3" json:"pagetoken,omitempty"`
+	Order     SortOrder `protobuf:"varint,6,opt,name=order,proto3,enum=fabex.SortOrder" json:"order,omitempty"`
+}
+
+func (x *RequestKeyHistory) Reset() {
+	*x = RequestKeyHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestKeyHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestKeyHistory) ProtoMessage() {}
+
+func (x *RequestKeyHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestKeyHistory.ProtoReflect.Descriptor instead.
+func (*RequestKeyHistory) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RequestKeyHistory) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestKeyHistory) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RequestKeyHistory) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RequestKeyHistory) GetPagesize() int64 {
+	if x != nil {
+		return x.Pagesize
+	}
+	return 0
+}
+
+func (x *RequestKeyHistory) GetPagetoken() string {
+	if x != nil {
+		return x.Pagetoken
+	}
+	return ""
+}
+
+func (x *RequestKeyHistory) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_ASC
+}
+
+// RequestCompositeKeyHistory requests a page of versions of composite keys with the object type and leading attributes
+type RequestCompositeKeyHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid  string    `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Namespace  string    `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Objecttype string    `protobuf:"bytes,3,opt,name=objecttype,proto3" json:"objecttype,omitempty"`
+	Attributes []string  `protobuf:"bytes,4,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Pagesize   int64     `protobuf:"varint,5,opt,name=pagesize,proto3" json:"pagesize,omitempty"`
+	Pagetoken  string    `protobuf:"bytes,6,opt,name=pagetoken,proto3" json:"pagetoken,omitempty"`
+	Order      SortOrder `protobuf:"varint,7,opt,name=order,proto3,enum=fabex.SortOrder" json:"order,omitempty"`
+}
+
+func (x *RequestCompositeKeyHistory) Reset() {
+	*x = RequestCompositeKeyHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestCompositeKeyHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestCompositeKeyHistory) ProtoMessage() {}
+
+func (x *RequestCompositeKeyHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestCompositeKeyHistory.ProtoReflect.Descriptor instead.
+func (*RequestCompositeKeyHistory) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RequestCompositeKeyHistory) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestCompositeKeyHistory) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RequestCompositeKeyHistory) GetObjecttype() string {
+	if x != nil {
+		return x.Objecttype
+	}
+	return ""
+}
+
+func (x *RequestCompositeKeyHistory) GetAttributes() []string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *RequestCompositeKeyHistory) GetPagesize() int64 {
+	if x != nil {
+		return x.Pagesize
+	}
+	return 0
+}
+
+func (x *RequestCompositeKeyHistory) GetPagetoken() string {
+	if x != nil {
+		return x.Pagetoken
+	}
+	return ""
+}
+
+func (x *RequestCompositeKeyHistory) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_ASC
+}
+
+type KeyModification struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Key       string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Blocknum  uint64 `protobuf:"varint,4,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
+	Txnum     uint64 `protobuf:"varint,5,opt,name=txnum,proto3" json:"txnum,omitempty"`
+	Txid      string `protobuf:"bytes,6,opt,name=txid,proto3" json:"txid,omitempty"`
+	Value     []byte `protobuf:"bytes,7,opt,name=value,proto3" json:"value,omitempty"`
+	Isdelete  bool   `protobuf:"varint,8,opt,name=isdelete,proto3" json:"isdelete,omitempty"`
+	Time      int64  `protobuf:"varint,9,opt,name=time,proto3" json:"time,omitempty"`
+	// components of the composite key, empty for simple keys
+	Objecttype string   `protobuf:"bytes,10,opt,name=objecttype,proto3" json:"objecttype,omitempty"`
+	Attributes []string `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+func (x *KeyModification) Reset() {
+	*x = KeyModification{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyModification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyModification) ProtoMessage() {}
+
+func (x *KeyModification) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyModification.ProtoReflect.Descriptor instead.
+func (*KeyModification) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *KeyModification) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *KeyModification) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *KeyModification) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyModification) GetBlocknum() uint64 {
+	if x != nil {
+		return x.Blocknum
+	}
+	return 0
+}
+
+func (x *KeyModification) GetTxnum() uint64 {
+	if x != nil {
+		return x.Txnum
+	}
+	return 0
+}
+
+func (x *KeyModification) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *KeyModification) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *KeyModification) GetIsdelete() bool {
+	if x != nil {
+		return x.Isdelete
+	}
+	return false
+}
+
+func (x *KeyModification) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *KeyModification) GetObjecttype() string {
+	if x != nil {
+		return x.Objecttype
+	}
+	return ""
+}
+
+func (x *KeyModification) GetAttributes() []string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type KeyHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Modifications []*KeyModification `protobuf:"bytes,1,rep,name=modifications,proto3" json:"modifications,omitempty"`
+	// empty if there are no more modifications
+	Nextpagetoken string `protobuf:"bytes,2,opt,name=nextpagetoken,proto3" json:"nextpagetoken,omitempty"`
+}
+
+func (x *KeyHistory) Reset() {
+	*x = KeyHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyHistory) ProtoMessage() {}
+
+func (x *KeyHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyHistory.ProtoReflect.Descriptor instead.
+func (*KeyHistory) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *KeyHistory) GetModifications() []*KeyModification {
+	if x != nil {
+		return x.Modifications
+	}
+	return nil
+}
+
+func (x *KeyHistory) GetNextpagetoken() string {
+	if x != nil {
+		return x.Nextpagetoken
+	}
+	return ""
+}
+
+// RequestState requests the current value of the world state key or its value as of the block
+type RequestState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string                  `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Namespace string                  `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Key       string                  `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Block     *wrapperspb.UInt64Value `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (x *RequestState) Reset() {
+	*x = RequestState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestState) ProtoMessage() {}
+
+func (x *RequestState) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestState.ProtoReflect.Descriptor instead.
+func (*RequestState) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RequestState) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestState) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RequestState) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RequestState) GetBlock() *wrapperspb.UInt64Value {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+// RequestStateScan requests a page of world state keys of the namespace
+type RequestStateScan struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// inclusive
+	Startkey string `protobuf:"bytes,3,opt,name=startkey,proto3" json:"startkey,omitempty"`
+	// exclusive
+	Endkey    string                  `protobuf:"bytes,4,opt,name=endkey,proto3" json:"endkey,omitempty"`
+	Prefix    string                  `protobuf:"bytes,5,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Block     *wrapperspb.UInt64Value `protobuf:"bytes,6,opt,name=block,proto3" json:"block,omitempty"`
+	Pagesize  int64                   `protobuf:"varint,7,opt,name=pagesize,proto3" json:"pagesize,omitempty"`
+	Pagetoken string                  `protobuf:"bytes,8,opt,name=pagetoken,proto3" json:"pagetoken,omitempty"`
+	Order     SortOrder               `protobuf:"varint,9,opt,name=order,proto3,enum=fabex.SortOrder" json:"order,omitempty"`
+	// partial composite key, used instead of prefix if set
+	Objecttype string   `protobuf:"bytes,10,opt,name=objecttype,proto3" json:"objecttype,omitempty"`
+	Attributes []string `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+func (x *RequestStateScan) Reset() {
+	*x = RequestStateScan{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestStateScan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestStateScan) ProtoMessage() {}
+
+func (x *RequestStateScan) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestStateScan.ProtoReflect.Descriptor instead.
+func (*RequestStateScan) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RequestStateScan) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetStartkey() string {
+	if x != nil {
+		return x.Startkey
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetEndkey() string {
+	if x != nil {
+		return x.Endkey
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetBlock() *wrapperspb.UInt64Value {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *RequestStateScan) GetPagesize() int64 {
+	if x != nil {
+		return x.Pagesize
+	}
+	return 0
+}
+
+func (x *RequestStateScan) GetPagetoken() string {
+	if x != nil {
+		return x.Pagetoken
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_ASC
+}
+
+func (x *RequestStateScan) GetObjecttype() string {
+	if x != nil {
+		return x.Objecttype
+	}
+	return ""
+}
+
+func (x *RequestStateScan) GetAttributes() []string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type StatePage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*KeyModification `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// empty if there are no more keys
+	Nextpagetoken string `protobuf:"bytes,2,opt,name=nextpagetoken,proto3" json:"nextpagetoken,omitempty"`
+}
+
+func (x *StatePage) Reset() {
+	*x = StatePage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatePage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatePage) ProtoMessage() {}
+
+func (x *StatePage) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatePage.ProtoReflect.Descriptor instead.
+func (*StatePage) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *StatePage) GetEntries() []*KeyModification {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *StatePage) GetNextpagetoken() string {
+	if x != nil {
+		return x.Nextpagetoken
+	}
+	return ""
+}
+
+type RequestRawBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	Blocknum  uint64 `protobuf:"varint,2,opt,name=blocknum,proto3" json:"blocknum,omitempty"`
+}
+
+func (x *RequestRawBlock) Reset() {
+	*x = RequestRawBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestRawBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRawBlock) ProtoMessage() {}
+
+func (x *RequestRawBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRawBlock.ProtoReflect.Descriptor instead.
+func (*RequestRawBlock) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RequestRawBlock) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestRawBlock) GetBlocknum() uint64 {
+	if x != nil {
+		return x.Blocknum
+	}
+	return 0
+}
+
+// RawBlock is the archived block
+type RawBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// marshaled common.Block
+	Block []byte `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	// hash of the block header, equals previous hash of the next block
+	Headerhash []byte `protobuf:"bytes,2,opt,name=headerhash,proto3" json:"headerhash,omitempty"`
+}
+
+func (x *RawBlock) Reset() {
+	*x = RawBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawBlock) ProtoMessage() {}
+
+func (x *RawBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawBlock.ProtoReflect.Descriptor instead.
+func (*RawBlock) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RawBlock) GetBlock() []byte {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *RawBlock) GetHeaderhash() []byte {
+	if x != nil {
+		return x.Headerhash
+	}
+	return nil
+}
+
+// RequestSubscribe selects streamed txs, empty filters are ignored
+type RequestSubscribe struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channelid string `protobuf:"bytes,1,opt,name=channelid,proto3" json:"channelid,omitempty"`
+	// first streamed block
+	Fromblock uint64 `protobuf:"varint,2,opt,name=fromblock,proto3" json:"fromblock,omitempty"`
+	// last block processed by the client, streaming resumes from the next block and fromblock is ignored
+	Checkpoint     *wrapperspb.UInt64Value `protobuf:"bytes,3,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+	Chaincode      string                  `protobuf:"bytes,4,opt,name=chaincode,proto3" json:"chaincode,omitempty"`
+	Keyprefix      string                  `protobuf:"bytes,5,opt,name=keyprefix,proto3" json:"keyprefix,omitempty"`
+	Validationcode *wrapperspb.Int32Value  `protobuf:"bytes,6,opt,name=validationcode,proto3" json:"validationcode,omitempty"`
+}
+
+func (x *RequestSubscribe) Reset() {
+	*x = RequestSubscribe{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fabex_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestSubscribe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestSubscribe) ProtoMessage() {}
+
+func (x *RequestSubscribe) ProtoReflect() protoreflect.Message {
+	mi := &file_fabex_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestSubscribe.ProtoReflect.Descriptor instead.
+func (*RequestSubscribe) Descriptor() ([]byte, []int) {
+	return file_fabex_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RequestSubscribe) GetChannelid() string {
+	if x != nil {
+		return x.Channelid
+	}
+	return ""
+}
+
+func (x *RequestSubscribe) GetFromblock() uint64 {
+	if x != nil {
+		return x.Fromblock
+	}
+	return 0
+}
+
+func (x *RequestSubscribe) GetCheckpoint() *wrapperspb.UInt64Value {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
+func (x *RequestSubscribe) GetChaincode() string {
+	if x != nil {
+		return x.Chaincode
+	}
+	return ""
+}
+
+func (x *RequestSubscribe) GetKeyprefix() string {
+	if x != nil {
+		return x.Keyprefix
+	}
+	return ""
+}
+
+func (x *RequestSubscribe) GetValidationcode() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Validationcode
+	}
+	return nil
+}
+
+var File_fabex_proto protoreflect.FileDescriptor
+
+var file_fabex_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x66,
+	0x61, 0x62, 0x65, 0x78, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0x46, 0x0a, 0x12, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x22, 0x49, 0x0a, 0x0b, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x26, 0x0a,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x48, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x22,
+	0xa1, 0x01, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x6e, 0x75, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x68, 0x61, 0x73, 0x68, 0x12, 0x26, 0x0a, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x66,
+	0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x22, 0x30, 0x0a, 0x10, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x69, 0x64, 0x22, 0x9b, 0x01, 0x0a, 0x09, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2a, 0x0a, 0x10, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x2c, 0x0a, 0x11, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
+	0x73, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x11, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68,
+	0x61, 0x73, 0x68, 0x22, 0x11, 0x0a, 0x0f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x38, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x73, 0x12, 0x2c, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73,
+	0x22, 0x68, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x1e,
+	0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a,
+	0x0a, 0x08, 0x65, 0x6e, 0x64, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x65, 0x6e, 0x64, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x92, 0x03, 0x0a, 0x05, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72,
+	0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1a,
+	0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x6d, 0x73, 0x70, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x6d, 0x73, 0x70, 0x12, 0x16,
+	0x0a, 0x06, 0x74, 0x78, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x74, 0x78, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x0c,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x12, 0x2d, 0x0a, 0x09, 0x64, 0x6f,
+	0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09,
+	0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x78, 0x6e,
+	0x75, 0x6d, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x74, 0x78, 0x6e, 0x75, 0x6d, 0x22,
+	0x32, 0x0a, 0x08, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0xa7, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x50,
+	0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70,
+	0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x26, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x53, 0x6f, 0x72,
+	0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x22, 0x54, 0x0a,
+	0x04, 0x50, 0x61, 0x67, 0x65, 0x12, 0x26, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a,
+	0x0d, 0x6e, 0x65, 0x78, 0x74, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x22, 0xb9, 0x04, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x12, 0x18, 0x0a, 0x07, 0x74, 0x6f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x74, 0x6f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72,
+	0x6f, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66, 0x72,
+	0x6f, 0x6d, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x6f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x6f, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x43,
+	0x0a, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x6d, 0x73, 0x70, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x6d, 0x73,
+	0x70, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x78, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x74, 0x78, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67,
+	0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x67,
+	0x65, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x26, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f,
+	0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x37, 0x0a, 0x06, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x66, 0x61,
+	0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xc3, 0x01, 0x0a, 0x11, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x26, 0x0a,
+	0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x66,
+	0x61, 0x62, 0x65, 0x78, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x22, 0xfa, 0x01, 0x0a, 0x1a, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x26, 0x0a, 0x05, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x66, 0x61, 0x62, 0x65,
+	0x78, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x22, 0xab, 0x02, 0x0a, 0x0f, 0x4b, 0x65, 0x79, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x78, 0x6e, 0x75, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x74, 0x78, 0x6e, 0x75, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x69, 0x73, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0b,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x22, 0x70, 0x0a, 0x0a, 0x4b, 0x65, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x3c,
+	0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x4b, 0x65,
+	0x79, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x6d,
+	0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x24, 0x0a, 0x0d,
+	0x6e, 0x65, 0x78, 0x74, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x22, 0x90, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x32, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x55, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0xf0, 0x02, 0x0a, 0x10, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x63, 0x61, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x6b,
+	0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x6b,
+	0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6e, 0x64, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x65, 0x6e, 0x64, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x32, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x55, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x26, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x22, 0x63, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x50, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x4b,
+	0x65, 0x79, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07,
+	0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x70,
+	0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x6e, 0x65, 0x78, 0x74, 0x70, 0x61, 0x67, 0x65, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x4b, 0x0a,
+	0x0f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x61, 0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x22, 0x40, 0x0a, 0x08, 0x52, 0x61,
+	0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1e, 0x0a, 0x0a,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0a, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x68, 0x61, 0x73, 0x68, 0x22, 0x8d, 0x02, 0x0a,
+	0x10, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x3c, 0x0a,
+	0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x55, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63,
+	0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6b, 0x65, 0x79,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65,
+	0x79, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x43, 0x0a, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x49, 0x6e, 0x74, 0x33, 0x32, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0e, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x2a, 0x1e, 0x0a, 0x09,
+	0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x53, 0x43,
+	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x45, 0x53, 0x43, 0x10, 0x01, 0x32, 0x98, 0x0b, 0x0a,
+	0x05, 0x46, 0x61, 0x62, 0x65, 0x78, 0x12, 0x4d, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0c, 0x2e,
+	0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x1a, 0x0c, 0x2e, 0x66, 0x61,
+	0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x22, 0x12, 0x20, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f,
+	0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x65, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x30, 0x01, 0x12, 0x6c, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x1a, 0x12, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x12, 0x23,
+	0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x74, 0x78, 0x73, 0x2f, 0x7b, 0x74, 0x78,
+	0x69, 0x64, 0x7d, 0x12, 0x61, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x13, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x22, 0x32, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x2c, 0x12, 0x2a, 0x2f, 0x76, 0x31, 0x2f,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x6e, 0x75, 0x6d, 0x7d, 0x12, 0x5b, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x17, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x1a,
+	0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x6e, 0x66,
+	0x6f, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x12, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x7d, 0x12, 0x4d, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x73, 0x12, 0x16, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x1a, 0x0f, 0x2e, 0x66, 0x61,
+	0x62, 0x65, 0x78, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x14, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x0e, 0x12, 0x0c, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x73, 0x12, 0x58, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x13,
+	0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x1a, 0x0c, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x22, 0x27, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x21, 0x12, 0x1f, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x7d, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x30, 0x01, 0x12, 0x4d, 0x0a, 0x04,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x12, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x50, 0x61, 0x67, 0x65, 0x1a, 0x0b, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78,
+	0x2e, 0x50, 0x61, 0x67, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x12, 0x1c, 0x2f,
+	0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x74, 0x78, 0x73, 0x12, 0x51, 0x0a, 0x05, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x12, 0x13, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x0b, 0x2e, 0x66, 0x61, 0x62, 0x65,
+	0x78, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x12, 0x1e,
+	0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x78,
+	0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12,
+	0x18, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4b,
+	0x65, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x1a, 0x11, 0x2e, 0x66, 0x61, 0x62, 0x65,
+	0x78, 0x2e, 0x4b, 0x65, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x22, 0x3a, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x34, 0x12, 0x32, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x68,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x7d, 0x2f, 0x7b, 0x6b, 0x65, 0x79, 0x7d, 0x12, 0x9b, 0x01, 0x0a, 0x16, 0x47, 0x65, 0x74,
+	0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x12, 0x21, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x1a, 0x11, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x4b,
+	0x65, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x22, 0x4b, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x45, 0x12, 0x43, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f,
+	0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x68, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x7d, 0x2f,
+	0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x65, 0x2f, 0x7b, 0x6f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x74, 0x79, 0x70, 0x65, 0x7d, 0x12, 0x71, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x13, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x1a, 0x16, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e,
+	0x4b, 0x65, 0x79, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x38, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x32, 0x12, 0x30, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64,
+	0x7d, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x7d, 0x2f, 0x7b, 0x6b, 0x65, 0x79, 0x7d, 0x12, 0x6a, 0x0a, 0x09, 0x53, 0x63, 0x61,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x63, 0x61, 0x6e, 0x1a,
+	0x10, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x67,
+	0x65, 0x22, 0x32, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x2c, 0x12, 0x2a, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x69, 0x64, 0x7d, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2f, 0x7b, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x7d, 0x12, 0x6e, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x61, 0x77, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x52, 0x61, 0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x0f, 0x2e, 0x66,
+	0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x61, 0x77, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x36, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x30, 0x12, 0x2e, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x73, 0x2f, 0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6e, 0x75, 0x6d,
+	0x7d, 0x2f, 0x72, 0x61, 0x77, 0x12, 0x60, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x12, 0x17, 0x2e, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x1a, 0x0c, 0x2e, 0x66, 0x61,
+	0x62, 0x65, 0x78, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x2a, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x24, 0x12, 0x22, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f,
+	0x7b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x69, 0x64, 0x7d, 0x2f, 0x73, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x30, 0x01, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65,
+	0x72, 0x2d, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x66, 0x61, 0x62, 0x65, 0x78, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_fabex_proto_rawDescOnce sync.Once
+	file_fabex_proto_rawDescData = file_fabex_proto_rawDesc
+)
+
+func file_fabex_proto_rawDescGZIP() []byte {
+	file_fabex_proto_rawDescOnce.Do(func() {
+		file_fabex_proto_rawDescData = protoimpl.X.CompressGZIP(file_fabex_proto_rawDescData)
+	})
+	return file_fabex_proto_rawDescData
+}
+
+var file_fabex_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_fabex_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_fabex_proto_goTypes = []interface{}{
+	(SortOrder)(0),                     // 0: fabex.SortOrder
+	(*RequestTransaction)(nil),         // 1: fabex.RequestTransaction
+	(*Transaction)(nil),                // 2: fabex.Transaction
+	(*RequestBlock)(nil),               // 3: fabex.RequestBlock
+	(*Block)(nil),                      // 4: fabex.Block
+	(*RequestChainInfo)(nil),           // 5: fabex.RequestChainInfo
+	(*ChainInfo)(nil),                  // 6: fabex.ChainInfo
+	(*RequestChannels)(nil),            // 7: fabex.RequestChannels
+	(*Channels)(nil),                   // 8: fabex.Channels
+	(*RequestRange)(nil),               // 9: fabex.RequestRange
+	(*Entry)(nil),                      // 10: fabex.Entry
+	(*Document)(nil),                   // 11: fabex.Document
+	(*RequestPage)(nil),                // 12: fabex.RequestPage
+	(*Page)(nil),                       // 13: fabex.Page
+	(*RequestQuery)(nil),               // 14: fabex.RequestQuery
+	(*RequestKeyHistory)(nil),          // 15: fabex.RequestKeyHistory
+	(*RequestCompositeKeyHistory)(nil), // 16: fabex.RequestCompositeKeyHistory
+	(*KeyModification)(nil),            // 17: fabex.KeyModification
+	(*KeyHistory)(nil),                 // 18: fabex.KeyHistory
+	(*RequestState)(nil),               // 19: fabex.RequestState
+	(*RequestStateScan)(nil),           // 20: fabex.RequestStateScan
+	(*StatePage)(nil),                  // 21: fabex.StatePage
+	(*RequestRawBlock)(nil),            // 22: fabex.RequestRawBlock
+	(*RawBlock)(nil),                   // 23: fabex.RawBlock
+	(*RequestSubscribe)(nil),           // 24: fabex.RequestSubscribe
+	nil,                                // 25: fabex.RequestQuery.FieldsEntry
+	(*wrapperspb.Int32Value)(nil),      // 26: google.protobuf.Int32Value
+	(*wrapperspb.UInt64Value)(nil),     // 27: google.protobuf.UInt64Value
+}
+var file_fabex_proto_depIdxs = []int32{
+	10, // 0: fabex.Transaction.entries:type_name -> fabex.Entry
+	10, // 1: fabex.Block.entries:type_name -> fabex.Entry
+	6,  // 2: fabex.Channels.channels:type_name -> fabex.ChainInfo
+	11, // 3: fabex.Entry.documents:type_name -> fabex.Document
+	0,  // 4: fabex.RequestPage.order:type_name -> fabex.SortOrder
+	10, // 5: fabex.Page.entries:type_name -> fabex.Entry
+	26, // 6: fabex.RequestQuery.validationcode:type_name -> google.protobuf.Int32Value
+	0,  // 7: fabex.RequestQuery.order:type_name -> fabex.SortOrder
+	25, // 8: fabex.RequestQuery.fields:type_name -> fabex.RequestQuery.FieldsEntry
+	0,  // 9: fabex.RequestKeyHistory.order:type_name -> fabex.SortOrder
+	0,  // 10: fabex.RequestCompositeKeyHistory.order:type_name -> fabex.SortOrder
+	17, // 11: fabex.KeyHistory.modifications:type_name -> fabex.KeyModification
+	27, // 12: fabex.RequestState.block:type_name -> google.protobuf.UInt64Value
+	27, // 13: fabex.RequestStateScan.block:type_name -> google.protobuf.UInt64Value
+	0,  // 14: fabex.RequestStateScan.order:type_name -> fabex.SortOrder
+	17, // 15: fabex.StatePage.entries:type_name -> fabex.KeyModification
+	27, // 16: fabex.RequestSubscribe.checkpoint:type_name -> google.protobuf.UInt64Value
+	26, // 17: fabex.RequestSubscribe.validationcode:type_name -> google.protobuf.Int32Value
+	10, // 18: fabex.Fabex.Get:input_type -> fabex.Entry
+	1,  // 19: fabex.Fabex.GetTransaction:input_type -> fabex.RequestTransaction
+	3,  // 20: fabex.Fabex.GetBlock:input_type -> fabex.RequestBlock
+	5,  // 21: fabex.Fabex.GetChainInfo:input_type -> fabex.RequestChainInfo
+	7,  // 22: fabex.Fabex.ListChannels:input_type -> fabex.RequestChannels
+	9,  // 23: fabex.Fabex.GetRange:input_type -> fabex.RequestRange
+	12, // 24: fabex.Fabex.List:input_type -> fabex.RequestPage
+	14, // 25: fabex.Fabex.Query:input_type -> fabex.RequestQuery
+	15, // 26: fabex.Fabex.GetKeyHistory:input_type -> fabex.RequestKeyHistory
+	16, // 27: fabex.Fabex.GetCompositeKeyHistory:input_type -> fabex.RequestCompositeKeyHistory
+	19, // 28: fabex.Fabex.GetState:input_type -> fabex.RequestState
+	20, // 29: fabex.Fabex.ScanState:input_type -> fabex.RequestStateScan
+	22, // 30: fabex.Fabex.GetRawBlock:input_type -> fabex.RequestRawBlock
+	24, // 31: fabex.Fabex.Subscribe:input_type -> fabex.RequestSubscribe
+	10, // 32: fabex.Fabex.Get:output_type -> fabex.Entry
+	2,  // 33: fabex.Fabex.GetTransaction:output_type -> fabex.Transaction
+	4,  // 34: fabex.Fabex.GetBlock:output_type -> fabex.Block
+	6,  // 35: fabex.Fabex.GetChainInfo:output_type -> fabex.ChainInfo
+	8,  // 36: fabex.Fabex.ListChannels:output_type -> fabex.Channels
+	10, // 37: fabex.Fabex.GetRange:output_type -> fabex.Entry
+	13, // 38: fabex.Fabex.List:output_type -> fabex.Page
+	13, // 39: fabex.Fabex.Query:output_type -> fabex.Page
+	18, // 40: fabex.Fabex.GetKeyHistory:output_type -> fabex.KeyHistory
+	18, // 41: fabex.Fabex.GetCompositeKeyHistory:output_type -> fabex.KeyHistory
+	17, // 42: fabex.Fabex.GetState:output_type -> fabex.KeyModification
+	21, // 43: fabex.Fabex.ScanState:output_type -> fabex.StatePage
+	23, // 44: fabex.Fabex.GetRawBlock:output_type -> fabex.RawBlock
+	10, // 45: fabex.Fabex.Subscribe:output_type -> fabex.Entry
+	32, // [32:46] is the sub-list for method output_type
+	18, // [18:32] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_fabex_proto_init() }
+func file_fabex_proto_init() {
+	if File_fabex_proto != nil {
+		return
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_fabex_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RequestRange); i {
+			switch v := v.(*RequestTransaction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -272,6 +2392,102 @@ func file_fabex_proto_init() {
 			}
 		}
 		file_fabex_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Block); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestChainInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChainInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestChannels); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Channels); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Entry); i {
 			case 0:
 				return &v.state
@@ -283,19 +2499,188 @@ func file_fabex_proto_init() {
 				return nil
 			}
 		}
+		file_fabex_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Document); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestPage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Page); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestKeyHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestCompositeKeyHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyModification); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestStateScan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatePage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestRawBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RawBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fabex_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestSubscribe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_fabex_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      1,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_fabex_proto_goTypes,
 		DependencyIndexes: file_fabex_proto_depIdxs,
+		EnumInfos:         file_fabex_proto_enumTypes,
 		MessageInfos:      file_fabex_proto_msgTypes,
 	}.Build()
 	File_fabex_proto = out.File
@@ -23,8 +23,28 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type FabexClient interface {
+	// Get streams channel entries selected by txid, blocknum or payload, all entries if none is specified,
+	// they include block 0 and are read in chunks of the max range
 	Get(ctx context.Context, in *Entry, opts ...grpc.CallOption) (Fabex_GetClient, error)
+	// GetTransaction returns entries of the tx, NOT_FOUND if the tx isn't stored
+	GetTransaction(ctx context.Context, in *RequestTransaction, opts ...grpc.CallOption) (*Transaction, error)
+	// GetBlock returns entries of the block, NOT_FOUND if the block isn't stored
+	GetBlock(ctx context.Context, in *RequestBlock, opts ...grpc.CallOption) (*Block, error)
+	// GetChainInfo describes the stored part of the channel ledger, NOT_FOUND if the channel isn't explored
+	GetChainInfo(ctx context.Context, in *RequestChainInfo, opts ...grpc.CallOption) (*ChainInfo, error)
+	// ListChannels describes channels explored by fabex
+	ListChannels(ctx context.Context, in *RequestChannels, opts ...grpc.CallOption) (*Channels, error)
+	// GetRange streams entries of blocks [startblock, endblock], the range is limited by the server
 	GetRange(ctx context.Context, in *RequestRange, opts ...grpc.CallOption) (Fabex_GetRangeClient, error)
+	List(ctx context.Context, in *RequestPage, opts ...grpc.CallOption) (*Page, error)
+	Query(ctx context.Context, in *RequestQuery, opts ...grpc.CallOption) (*Page, error)
+	GetKeyHistory(ctx context.Context, in *RequestKeyHistory, opts ...grpc.CallOption) (*KeyHistory, error)
+	GetCompositeKeyHistory(ctx context.Context, in *RequestCompositeKeyHistory, opts ...grpc.CallOption) (*KeyHistory, error)
+	GetState(ctx context.Context, in *RequestState, opts ...grpc.CallOption) (*KeyModification, error)
+	ScanState(ctx context.Context, in *RequestStateScan, opts ...grpc.CallOption) (*StatePage, error)
+	GetRawBlock(ctx context.Context, in *RequestRawBlock, opts ...grpc.CallOption) (*RawBlock, error)
+	// Subscribe streams stored txs starting from the block, then txs of new blocks as they are indexed
+	Subscribe(ctx context.Context, in *RequestSubscribe, opts ...grpc.CallOption) (Fabex_SubscribeClient, error)
 }
 
 type fabexClient struct {
@@ -67,6 +87,42 @@ func (x *fabexGetClient) Recv() (*Entry, error) {
 	return m, nil
 }
 
+func (c *fabexClient) GetTransaction(ctx context.Context, in *RequestTransaction, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetBlock(ctx context.Context, in *RequestBlock, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetChainInfo(ctx context.Context, in *RequestChainInfo, opts ...grpc.CallOption) (*ChainInfo, error) {
+	out := new(ChainInfo)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetChainInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) ListChannels(ctx context.Context, in *RequestChannels, opts ...grpc.CallOption) (*Channels, error) {
+	out := new(Channels)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/ListChannels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *fabexClient) GetRange(ctx context.Context, in *RequestRange, opts ...grpc.CallOption) (Fabex_GetRangeClient, error) {
 	stream, err := c.cc.NewStream(ctx, &Fabex_ServiceDesc.Streams[1], "/fabex.Fabex/GetRange", opts...)
 	if err != nil {
@@ -99,12 +155,127 @@ func (x *fabexGetRangeClient) Recv() (*Entry, error) {
 	return m, nil
 }
 
+func (c *fabexClient) List(ctx context.Context, in *RequestPage, opts ...grpc.CallOption) (*Page, error) {
+	out := new(Page)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) Query(ctx context.Context, in *RequestQuery, opts ...grpc.CallOption) (*Page, error) {
+	out := new(Page)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetKeyHistory(ctx context.Context, in *RequestKeyHistory, opts ...grpc.CallOption) (*KeyHistory, error) {
+	out := new(KeyHistory)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetKeyHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetCompositeKeyHistory(ctx context.Context, in *RequestCompositeKeyHistory, opts ...grpc.CallOption) (*KeyHistory, error) {
+	out := new(KeyHistory)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetCompositeKeyHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetState(ctx context.Context, in *RequestState, opts ...grpc.CallOption) (*KeyModification, error) {
+	out := new(KeyModification)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) ScanState(ctx context.Context, in *RequestStateScan, opts ...grpc.CallOption) (*StatePage, error) {
+	out := new(StatePage)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/ScanState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetRawBlock(ctx context.Context, in *RequestRawBlock, opts ...grpc.CallOption) (*RawBlock, error) {
+	out := new(RawBlock)
+	err := c.cc.Invoke(ctx, "/fabex.Fabex/GetRawBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) Subscribe(ctx context.Context, in *RequestSubscribe, opts ...grpc.CallOption) (Fabex_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fabex_ServiceDesc.Streams[2], "/fabex.Fabex/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fabexSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fabex_SubscribeClient interface {
+	Recv() (*Entry, error)
+	grpc.ClientStream
+}
+
+type fabexSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *fabexSubscribeClient) Recv() (*Entry, error) {
+	m := new(Entry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // FabexServer is the server API for Fabex service.
 // All implementations must embed UnimplementedFabexServer
 // for forward compatibility
 type FabexServer interface {
+	// Get streams channel entries selected by txid, blocknum or payload, all entries if none is specified,
+	// they include block 0 and are read in chunks of the max range
 	Get(*Entry, Fabex_GetServer) error
+	// GetTransaction returns entries of the tx, NOT_FOUND if the tx isn't stored
+	GetTransaction(context.Context, *RequestTransaction) (*Transaction, error)
+	// GetBlock returns entries of the block, NOT_FOUND if the block isn't stored
+	GetBlock(context.Context, *RequestBlock) (*Block, error)
+	// GetChainInfo describes the stored part of the channel ledger, NOT_FOUND if the channel isn't explored
+	GetChainInfo(context.Context, *RequestChainInfo) (*ChainInfo, error)
+	// ListChannels describes channels explored by fabex
+	ListChannels(context.Context, *RequestChannels) (*Channels, error)
+	// GetRange streams entries of blocks [startblock, endblock], the range is limited by the server
 	GetRange(*RequestRange, Fabex_GetRangeServer) error
+	List(context.Context, *RequestPage) (*Page, error)
+	Query(context.Context, *RequestQuery) (*Page, error)
+	GetKeyHistory(context.Context, *RequestKeyHistory) (*KeyHistory, error)
+	GetCompositeKeyHistory(context.Context, *RequestCompositeKeyHistory) (*KeyHistory, error)
+	GetState(context.Context, *RequestState) (*KeyModification, error)
+	ScanState(context.Context, *RequestStateScan) (*StatePage, error)
+	GetRawBlock(context.Context, *RequestRawBlock) (*RawBlock, error)
+	// Subscribe streams stored txs starting from the block, then txs of new blocks as they are indexed
+	Subscribe(*RequestSubscribe, Fabex_SubscribeServer) error
 	mustEmbedUnimplementedFabexServer()
 }
 
@@ -115,9 +286,45 @@ type UnimplementedFabexServer struct {
 func (UnimplementedFabexServer) Get(*Entry, Fabex_GetServer) error {
 	return status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedFabexServer) GetTransaction(context.Context, *RequestTransaction) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedFabexServer) GetBlock(context.Context, *RequestBlock) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedFabexServer) GetChainInfo(context.Context, *RequestChainInfo) (*ChainInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChainInfo not implemented")
+}
+func (UnimplementedFabexServer) ListChannels(context.Context, *RequestChannels) (*Channels, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChannels not implemented")
+}
 func (UnimplementedFabexServer) GetRange(*RequestRange, Fabex_GetRangeServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetRange not implemented")
 }
+func (UnimplementedFabexServer) List(context.Context, *RequestPage) (*Page, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedFabexServer) Query(context.Context, *RequestQuery) (*Page, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedFabexServer) GetKeyHistory(context.Context, *RequestKeyHistory) (*KeyHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyHistory not implemented")
+}
+func (UnimplementedFabexServer) GetCompositeKeyHistory(context.Context, *RequestCompositeKeyHistory) (*KeyHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCompositeKeyHistory not implemented")
+}
+func (UnimplementedFabexServer) GetState(context.Context, *RequestState) (*KeyModification, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedFabexServer) ScanState(context.Context, *RequestStateScan) (*StatePage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanState not implemented")
+}
+func (UnimplementedFabexServer) GetRawBlock(context.Context, *RequestRawBlock) (*RawBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRawBlock not implemented")
+}
+func (UnimplementedFabexServer) Subscribe(*RequestSubscribe, Fabex_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
 func (UnimplementedFabexServer) mustEmbedUnimplementedFabexServer() {}
 
 // UnsafeFabexServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +359,78 @@ func (x *fabexGetServer) Send(m *Entry) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Fabex_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTransaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetTransaction(ctx, req.(*RequestTransaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestBlock)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetBlock(ctx, req.(*RequestBlock))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetChainInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestChainInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetChainInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetChainInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetChainInfo(ctx, req.(*RequestChainInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_ListChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestChannels)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).ListChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/ListChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).ListChannels(ctx, req.(*RequestChannels))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Fabex_GetRange_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(RequestRange)
 	if err := stream.RecvMsg(m); err != nil {
@@ -173,13 +452,205 @@ func (x *fabexGetRangeServer) Send(m *Entry) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Fabex_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).List(ctx, req.(*RequestPage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).Query(ctx, req.(*RequestQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetKeyHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestKeyHistory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetKeyHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetKeyHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetKeyHistory(ctx, req.(*RequestKeyHistory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetCompositeKeyHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestCompositeKeyHistory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetCompositeKeyHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetCompositeKeyHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetCompositeKeyHistory(ctx, req.(*RequestCompositeKeyHistory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestState)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetState(ctx, req.(*RequestState))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_ScanState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestStateScan)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).ScanState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/ScanState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).ScanState(ctx, req.(*RequestStateScan))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetRawBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestRawBlock)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).GetRawBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.Fabex/GetRawBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).GetRawBlock(ctx, req.(*RequestRawBlock))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestSubscribe)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FabexServer).Subscribe(m, &fabexSubscribeServer{stream})
+}
+
+type Fabex_SubscribeServer interface {
+	Send(*Entry) error
+	grpc.ServerStream
+}
+
+type fabexSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *fabexSubscribeServer) Send(m *Entry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Fabex_ServiceDesc is the grpc.ServiceDesc for Fabex service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var Fabex_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "fabex.Fabex",
 	HandlerType: (*FabexServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Fabex_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Fabex_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetChainInfo",
+			Handler:    _Fabex_GetChainInfo_Handler,
+		},
+		{
+			MethodName: "ListChannels",
+			Handler:    _Fabex_ListChannels_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Fabex_List_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Fabex_Query_Handler,
+		},
+		{
+			MethodName: "GetKeyHistory",
+			Handler:    _Fabex_GetKeyHistory_Handler,
+		},
+		{
+			MethodName: "GetCompositeKeyHistory",
+			Handler:    _Fabex_GetCompositeKeyHistory_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _Fabex_GetState_Handler,
+		},
+		{
+			MethodName: "ScanState",
+			Handler:    _Fabex_ScanState_Handler,
+		},
+		{
+			MethodName: "GetRawBlock",
+			Handler:    _Fabex_GetRawBlock_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Get",
@@ -191,6 +662,11 @@ var Fabex_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Fabex_GetRange_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Fabex_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "fabex.proto",
 }
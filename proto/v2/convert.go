@@ -0,0 +1,134 @@
+package fabexv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// BlockFromTxs converts stored txs of a single block, details is the decoded block or nil if it isn't archived
+func BlockFromTxs(txs []db.Tx, details *blockhandler.CustomBlock) (*Block, error) {
+	if len(txs) == 0 {
+		return nil, errors.New("block has no txs")
+	}
+	transactions, err := TransactionsFromTxs(txs, func(uint64) (*blockhandler.CustomBlock, error) {
+		return details, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Block{
+		ChannelId:    txs[0].ChannelId,
+		Number:       txs[0].Blocknum,
+		Hash:         txs[0].Hash,
+		PreviousHash: txs[0].PreviousHash,
+		Transactions: transactions,
+	}, nil
+}
+
+// TransactionsFromTxs converts stored txs, adjacent txs of a Fabric tx writing to several chaincodes are merged.
+// Reads and events are taken from decoded blocks returned by details, it returns nil if the block isn't archived.
+func TransactionsFromTxs(txs []db.Tx, details func(blocknum uint64) (*blockhandler.CustomBlock, error)) ([]*Transaction, error) {
+	var out []*Transaction
+	for i, tx := range txs {
+		writes, err := writesFromTx(tx)
+		if err != nil {
+			return nil, err
+		}
+		// TxNum of converted txs may be resolved from the block, so stored txs are compared
+		if prev := i - 1; prev >= 0 && txs[prev].Txid == tx.Txid && txs[prev].Blocknum == tx.Blocknum && txs[prev].TxNum == tx.TxNum {
+			out[len(out)-1].Writes = append(out[len(out)-1].Writes, writes...)
+			continue
+		}
+
+		t := &Transaction{
+			Id:             tx.Txid,
+			ChannelId:      tx.ChannelId,
+			BlockNumber:    tx.Blocknum,
+			TxNum:          tx.TxNum,
+			Time:           timestamppb.New(time.Unix(tx.Time, 0)),
+			ValidationCode: tx.ValidationCode,
+			Valid:          tx.ValidationCode == int32(peer.TxValidationCode_VALID),
+			Type:           tx.TxType,
+			CreatorMspId:   tx.CreatorMSP,
+			Writes:         writes,
+		}
+		block, err := details(tx.Blocknum)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			addDetails(t, block)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// addDetails adds reads and events of the tx, its position in the block is resolved by txid as storages may not keep it
+func addDetails(t *Transaction, block *blockhandler.CustomBlock) {
+	txNum := block.TxNum(t.Id, t.TxNum)
+	t.TxNum = txNum
+	for _, read := range block.Reads {
+		if read.TxNum != txNum {
+			continue
+		}
+		r := &Read{Namespace: read.Namespace, Key: read.Key}
+		if read.Version != nil {
+			r.Version = &Version{BlockNumber: read.Version.BlockNum, TxNum: read.Version.TxNum}
+		}
+		t.Reads = append(t.Reads, r)
+	}
+	for _, ev := range block.Events {
+		if ev.Txid == t.Id && ev.TxNum == txNum {
+			t.Events = append(t.Events, &Event{Chaincode: ev.Chaincode, Name: ev.Name, Payload: ev.Payload})
+		}
+	}
+}
+
+// writesFromTx decodes the payload of the stored tx, writes of config txs are never deletes
+func writesFromTx(tx db.Tx) ([]*Write, error) {
+	var kvs []models.WriteKV
+	if err := json.Unmarshal(tx.Payload, &kvs); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal payload of tx %s", tx.Txid)
+	}
+	documents := make(map[string]interface{}, len(tx.Documents))
+	for _, doc := range tx.Documents {
+		documents[doc.Key] = doc.Value
+	}
+
+	writes := make([]*Write, 0, len(kvs))
+	for _, kv := range kvs {
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode value of key %s in tx %s", kv.Key, tx.Txid)
+		}
+		w := &Write{Namespace: tx.Chaincode, Key: kv.Key, Value: value, IsDelete: tx.Chaincode != "" && kv.Deleted()}
+		if doc, ok := documents[kv.Key]; ok {
+			if w.Document, err = documentToStruct(doc); err != nil {
+				return nil, errors.Wrapf(err, "failed to convert document of key %s in tx %s", kv.Key, tx.Txid)
+			}
+		}
+		writes = append(writes, w)
+	}
+	return writes, nil
+}
+
+// documentToStruct converts the document through JSON, so values of database specific types are supported
+func documentToStruct(doc interface{}) (*structpb.Struct, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	return s, protojson.Unmarshal(raw, s)
+}
@@ -0,0 +1,240 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.12.4
+// source: v2/fabex.proto
+
+package fabexv2
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// FabexClient is the client API for Fabex service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FabexClient interface {
+	// Query returns a page of transactions matching the filter
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// GetBlocks streams stored blocks of the range in ascending order
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (Fabex_GetBlocksClient, error)
+	// Subscribe streams stored blocks starting from the block, then new blocks as they are indexed. Blocks contain
+	// only transactions matching the filter, blocks without matching transactions are skipped.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Fabex_SubscribeClient, error)
+}
+
+type fabexClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFabexClient(cc grpc.ClientConnInterface) FabexClient {
+	return &fabexClient{cc}
+}
+
+func (c *fabexClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/fabex.v2.Fabex/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabexClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (Fabex_GetBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fabex_ServiceDesc.Streams[0], "/fabex.v2.Fabex/GetBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fabexGetBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fabex_GetBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type fabexGetBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *fabexGetBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fabexClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Fabex_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fabex_ServiceDesc.Streams[1], "/fabex.v2.Fabex/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fabexSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fabex_SubscribeClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type fabexSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *fabexSubscribeClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FabexServer is the server API for Fabex service.
+// All implementations must embed UnimplementedFabexServer
+// for forward compatibility
+type FabexServer interface {
+	// Query returns a page of transactions matching the filter
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	// GetBlocks streams stored blocks of the range in ascending order
+	GetBlocks(*GetBlocksRequest, Fabex_GetBlocksServer) error
+	// Subscribe streams stored blocks starting from the block, then new blocks as they are indexed. Blocks contain
+	// only transactions matching the filter, blocks without matching transactions are skipped.
+	Subscribe(*SubscribeRequest, Fabex_SubscribeServer) error
+	mustEmbedUnimplementedFabexServer()
+}
+
+// UnimplementedFabexServer must be embedded to have forward compatible implementations.
+type UnimplementedFabexServer struct {
+}
+
+func (UnimplementedFabexServer) Query(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedFabexServer) GetBlocks(*GetBlocksRequest, Fabex_GetBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (UnimplementedFabexServer) Subscribe(*SubscribeRequest, Fabex_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedFabexServer) mustEmbedUnimplementedFabexServer() {}
+
+// UnsafeFabexServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FabexServer will
+// result in compilation errors.
+type UnsafeFabexServer interface {
+	mustEmbedUnimplementedFabexServer()
+}
+
+func RegisterFabexServer(s grpc.ServiceRegistrar, srv FabexServer) {
+	s.RegisterService(&Fabex_ServiceDesc, srv)
+}
+
+func _Fabex_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabexServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabex.v2.Fabex/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabexServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabex_GetBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FabexServer).GetBlocks(m, &fabexGetBlocksServer{stream})
+}
+
+type Fabex_GetBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type fabexGetBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *fabexGetBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Fabex_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FabexServer).Subscribe(m, &fabexSubscribeServer{stream})
+}
+
+type Fabex_SubscribeServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type fabexSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *fabexSubscribeServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Fabex_ServiceDesc is the grpc.ServiceDesc for Fabex service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fabex_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fabex.v2.Fabex",
+	HandlerType: (*FabexServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Query",
+			Handler:    _Fabex_Query_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetBlocks",
+			Handler:       _Fabex_GetBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Fabex_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v2/fabex.proto",
+}
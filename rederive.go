@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// rederive parses archived blocks of the channel again and replaces stored txs and key history of every storage,
+// e.g. to backfill fields added by a newer version. It's run instead of the service:
+//
+//	CONFIG=configs/config.yaml DB=mongo ./fabex rederive -channel mychannel -from 0 -to 1000
+func rederive(ctx context.Context, args []string, storages []db.Storage, blocks db.RawBlockStore, decoder blockhandler.ValueDecoder) error {
+	flags := flag.NewFlagSet("rederive", flag.ContinueOnError)
+	ch := flags.String("channel", "", "channel to re-derive")
+	from := flags.Uint64("from", 0, "first block")
+	to := flags.Uint64("to", 0, "last block, the last stored block if 0")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *ch == "" {
+		return errors.New("channel is not specified")
+	}
+	if blocks == nil {
+		return errors.New("blocks aren't archived")
+	}
+
+	var decoders []blockhandler.ValueDecoder
+	if decoder != nil {
+		decoders = append(decoders, decoder)
+	}
+	derive := blockhandler.Derive(decoders...)
+
+	l, ok := ctx.Value("log").(*zap.Logger)
+	if !ok {
+		return errors.New("failed to get logger from context")
+	}
+	for _, storage := range storages {
+		replacer, ok := storage.(db.BlockReplacer)
+		if !ok {
+			return errors.New("re-deriving is not supported by the database")
+		}
+		// pending migrations run first
+		if err := storage.Init(ctx, *ch); err != nil {
+			return err
+		}
+
+		last := *to
+		if last == 0 {
+			tx, err := storage.GetLastEntry(ctx, *ch)
+			if err != nil {
+				return errors.Wrap(err, "failed to get the last stored block")
+			}
+			last = tx.Blocknum
+		}
+		done, err := db.Rederive(ctx, replacer, blocks, derive, *ch, *from, last)
+		if err != nil {
+			return err
+		}
+		l.Info("blocks are re-derived", zap.String("channel", *ch), zap.Uint64("from", *from), zap.Uint64("to", last), zap.Uint64("blocks", done))
+	}
+
+	return nil
+}
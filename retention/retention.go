@@ -0,0 +1,169 @@
+// Package retention prunes indexed data of channels according to retention policies
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// CheckpointName is the name of the checkpoint of chaincode pruning in storage
+const CheckpointName = "retention"
+
+// Policy selects data of the channel to keep, zero fields are not used. Blocks are kept if any rule keeps them.
+type Policy struct {
+	Channel string
+	// KeepBlocks keeps the last N blocks
+	KeepBlocks uint64
+	// KeepFor keeps blocks newer than the duration
+	KeepFor time.Duration
+	// Chaincodes keeps txs of the chaincodes only, config txs are always kept
+	Chaincodes []string
+}
+
+// Pruner enforces retention policies. The last stored block is never pruned, because ingestion resumes from it.
+// Blocks are pruned only after they are processed by all consumers, so sinks can still export them.
+// Archived blocks are pruned by block rules only, so retained raw blocks form a chain verifiable by header hashes.
+type Pruner struct {
+	storage db.Storage
+	pruner  db.Pruner
+	// blocks is nil if the block archive is disabled or doesn't support pruning
+	blocks    db.RawBlockPruner
+	policies  []Policy
+	consumers []string
+	now       func() time.Time
+}
+
+// New creates pruner, storage must implement db.Pruner. Consumers are checkpoint names of block processors.
+func New(storage db.Storage, blocks db.RawBlockStore, policies []Policy, consumers []string) (*Pruner, error) {
+	pruner, ok := storage.(db.Pruner)
+	if !ok {
+		return nil, errors.New("retention is not supported by the database")
+	}
+
+	p := &Pruner{storage: storage, pruner: pruner, policies: policies, consumers: consumers, now: time.Now}
+	if blocks != nil {
+		p.blocks, _ = blocks.(db.RawBlockPruner)
+	}
+	return p, nil
+}
+
+// Run prunes channels every interval until ctx is done
+func (p *Pruner) Run(ctx context.Context, interval time.Duration) {
+	l, ok := ctx.Value("log").(*zap.Logger)
+	if !ok {
+		l = zap.NewNop()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, policy := range p.policies {
+			deleted, err := p.Prune(ctx, policy)
+			if err != nil {
+				l.Error("pruning failed", zap.Error(err), zap.String("channel", policy.Channel))
+				continue
+			}
+			if deleted != 0 {
+				l.Info("pruned txs", zap.String("channel", policy.Channel), zap.Int64("txs", deleted))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes data of the channel which isn't kept by the policy, it returns the number of deleted txs
+func (p *Pruner) Prune(ctx context.Context, policy Policy) (int64, error) {
+	ch := policy.Channel
+	last, err := p.storage.GetLastEntry(ctx, ch)
+	if err != nil {
+		if err.Error() == db.NOT_FOUND_ERR {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "failed to get last block")
+	}
+
+	// limit is the first block which can't be pruned
+	limit := last.Blocknum
+	for _, consumer := range p.consumers {
+		processed, ok, err := p.storage.GetCheckpoint(ctx, ch, consumer)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get checkpoint of %s", consumer)
+		}
+		if !ok {
+			return 0, nil
+		}
+		if processed+1 < limit {
+			limit = processed + 1
+		}
+	}
+
+	var before uint64
+	if policy.KeepBlocks != 0 && last.Blocknum+1 > policy.KeepBlocks {
+		before = last.Blocknum + 1 - policy.KeepBlocks
+	}
+	if policy.KeepFor != 0 {
+		blocknum, ok, err := p.pruner.LastBlockBefore(ctx, ch, p.now().Add(-policy.KeepFor))
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to find expired blocks")
+		}
+		// the block is kept by the time rule only if the block rule keeps less
+		if !ok {
+			before = 0
+		} else if policy.KeepBlocks == 0 || blocknum+1 < before {
+			before = blocknum + 1
+		}
+	}
+	if before > limit {
+		before = limit
+	}
+
+	var deleted int64
+	if before != 0 {
+		if deleted, err = p.pruner.PruneBlocks(ctx, ch, before); err != nil {
+			return deleted, errors.Wrapf(err, "failed to prune blocks before %d", before)
+		}
+		if p.blocks != nil {
+			if err = p.blocks.PruneRawBlocks(ctx, ch, before); err != nil {
+				return deleted, errors.Wrapf(err, "failed to prune archived blocks before %d", before)
+			}
+		}
+	}
+
+	if len(policy.Chaincodes) == 0 || limit == 0 {
+		return deleted, nil
+	}
+
+	// blocks are pruned by chaincodes once, the checkpoint keeps the last pruned block
+	var from uint64
+	pruned, ok, err := p.storage.GetCheckpoint(ctx, ch, CheckpointName)
+	if err != nil {
+		return deleted, errors.Wrap(err, "failed to get retention checkpoint")
+	}
+	if ok {
+		from = pruned + 1
+	}
+	if from < before {
+		from = before
+	}
+	to := limit - 1
+	if from > to {
+		return deleted, nil
+	}
+
+	n, err := p.pruner.PruneChaincodes(ctx, ch, policy.Chaincodes, from, to)
+	deleted += n
+	if err != nil {
+		return deleted, errors.Wrapf(err, "failed to prune chaincodes in blocks %d-%d", from, to)
+	}
+
+	return deleted, errors.Wrap(p.storage.SetCheckpoint(ctx, ch, CheckpointName, to), "failed to set retention checkpoint")
+}
@@ -0,0 +1,111 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorage returns storage with blocks 0-99 of mychannel, every block has txs of fabcar and marbles,
+// the time of a block is its number in seconds
+func newStorage(checkpoints map[string]uint64) *dbtest.Storage {
+	storage := dbtest.New()
+	for blocknum := uint64(0); blocknum < 100; blocknum++ {
+		storage.Add("mychannel",
+			db.Tx{ChannelId: "mychannel", Txid: "fabcar", Blocknum: blocknum, Chaincode: "fabcar", Time: int64(blocknum)},
+			db.Tx{ChannelId: "mychannel", Txid: "marbles", Blocknum: blocknum, TxNum: 1, Chaincode: "marbles", Time: int64(blocknum)})
+	}
+	for consumer, blocknum := range checkpoints {
+		_ = storage.SetCheckpoint(context.Background(), "mychannel", consumer, blocknum)
+	}
+	return storage
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		checkpoints map[string]uint64
+		// expired is the time of the first block which isn't expired
+		expired int64
+		// first is the first kept block, kept is the number of kept txs
+		first uint64
+		kept  int
+	}{
+		{
+			name:        "keep blocks",
+			policy:      Policy{KeepBlocks: 10},
+			checkpoints: map[string]uint64{"s3": 99},
+			first:       90,
+			kept:        20,
+		},
+		{
+			name:        "consumer is behind",
+			policy:      Policy{KeepBlocks: 10},
+			checkpoints: map[string]uint64{"s3": 50},
+			first:       51,
+			kept:        98,
+		},
+		{
+			name:        "consumer has not started",
+			policy:      Policy{KeepBlocks: 10},
+			checkpoints: map[string]uint64{},
+			kept:        200,
+		},
+		{
+			name:        "time rule keeps more blocks",
+			policy:      Policy{KeepBlocks: 10, KeepFor: time.Hour},
+			checkpoints: map[string]uint64{"s3": 99},
+			expired:     41,
+			first:       41,
+			kept:        118,
+		},
+		{
+			name:        "nothing expired",
+			policy:      Policy{KeepBlocks: 10, KeepFor: time.Hour},
+			checkpoints: map[string]uint64{"s3": 99},
+			kept:        200,
+		},
+		{
+			name:        "last block is kept",
+			policy:      Policy{KeepFor: time.Hour},
+			checkpoints: map[string]uint64{"s3": 99},
+			expired:     100,
+			first:       99,
+			kept:        2,
+		},
+		{
+			name:        "chaincodes",
+			policy:      Policy{KeepBlocks: 10, Chaincodes: []string{"fabcar"}},
+			checkpoints: map[string]uint64{"s3": 99, CheckpointName: 80},
+			first:       90,
+			// marbles txs of blocks 90-98 are pruned, the last block is kept
+			kept: 11,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := newStorage(tt.checkpoints)
+			pruner, err := New(storage, nil, nil, []string{"s3"})
+			require.NoError(t, err)
+			pruner.now = func() time.Time { return time.Unix(tt.expired, 0).Add(tt.policy.KeepFor) }
+
+			tt.policy.Channel = "mychannel"
+			deleted, err := pruner.Prune(ctx, tt.policy)
+			require.NoError(t, err)
+
+			txs, err := storage.QueryAll(ctx, "mychannel")
+			require.NoError(t, err)
+			require.Len(t, txs, tt.kept)
+			assert.Equal(t, int64(200-tt.kept), deleted)
+			assert.Equal(t, tt.first, txs[0].Blocknum)
+		})
+	}
+}
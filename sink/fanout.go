@@ -0,0 +1,360 @@
+package sink
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/state"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Defaults of FanoutOptions
+const (
+	DefaultQueueSize = 100
+	DefaultRetryMin  = time.Second
+	DefaultRetryMax  = time.Minute
+)
+
+// replayBatch is the number of blocks read from the read backend by a single query during replay
+const replayBatch = 100
+
+// FanoutSink is a named sink, the name identifies its checkpoints
+type FanoutSink struct {
+	Name string
+	Sink helpers.BlockProcessor
+}
+
+// FanoutOptions configures queues of sinks, zero values are replaced by defaults
+type FanoutOptions struct {
+	// QueueSize is the number of blocks buffered for every sink
+	QueueSize int
+	// RetryMin and RetryMax limit the delay between attempts to process a failed block
+	RetryMin time.Duration
+	RetryMax time.Duration
+}
+
+// Health is the state of the sink
+type Health struct {
+	Name string `json:"name"`
+	// Healthy is false if the last attempt to process a block failed
+	Healthy bool `json:"healthy"`
+	// Blocks are the last processed blocks of channels
+	Blocks map[string]uint64 `json:"blocks"`
+	// Queued is the number of blocks waiting in the queue
+	Queued int `json:"queued"`
+	// Dropped is the number of blocks dropped because the queue was full, they are replayed later
+	Dropped   uint64     `json:"dropped"`
+	Retries   uint64     `json:"retries"`
+	LastError string     `json:"lasterror,omitempty"`
+	LastErrAt *time.Time `json:"lasterrat,omitempty"`
+}
+
+// Fanout delivers blocks stored into the read backend to other sinks. Every sink has its own queue and checkpoints,
+// so a slow or failing sink doesn't block ingestion and other sinks. Blocks which were dropped from a full queue or
+// were queued before restart are replayed from the read backend.
+type Fanout struct {
+	read db.Storage
+	// blocks are used to restore original blocks during replay, can be nil
+	blocks  db.RawBlockStore
+	workers []*worker
+	opts    FanoutOptions
+}
+
+type queuedBlock struct {
+	ch    string
+	block *blockhandler.CustomBlock
+}
+
+type worker struct {
+	FanoutSink
+	queue chan queuedBlock
+
+	mu     sync.Mutex
+	health Health
+}
+
+// CheckpointName returns the name of the sink checkpoint in storage
+func CheckpointName(sink string) string {
+	return "fanout/" + sink
+}
+
+// NewFanout creates fan-out, read is the storage serving APIs, its checkpoints are used by sinks
+func NewFanout(read db.Storage, blocks db.RawBlockStore, sinks []FanoutSink, opts FanoutOptions) *Fanout {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.RetryMin <= 0 {
+		opts.RetryMin = DefaultRetryMin
+	}
+	if opts.RetryMax < opts.RetryMin {
+		opts.RetryMax = DefaultRetryMax
+	}
+
+	f := &Fanout{read: read, blocks: blocks, opts: opts}
+	for _, s := range sinks {
+		f.workers = append(f.workers, &worker{
+			FanoutSink: s,
+			queue:      make(chan queuedBlock, opts.QueueSize),
+			health:     Health{Name: s.Name, Healthy: true, Blocks: make(map[string]uint64)},
+		})
+	}
+	return f
+}
+
+// ProcessBlock queues the block for every sink, it never blocks ingestion
+func (f *Fanout) ProcessBlock(_ context.Context, ch string, block *blockhandler.CustomBlock) error {
+	for _, w := range f.workers {
+		select {
+		case w.queue <- queuedBlock{ch: ch, block: block}:
+		default:
+			w.mu.Lock()
+			w.health.Dropped++
+			w.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Run processes queues until ctx is done
+func (f *Fanout) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, w := range f.workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			f.run(ctx, w)
+		}(w)
+	}
+	wg.Wait()
+}
+
+// Health returns states of sinks
+func (f *Fanout) Health() []Health {
+	health := make([]Health, 0, len(f.workers))
+	for _, w := range f.workers {
+		w.mu.Lock()
+		h := w.health
+		h.Blocks = make(map[string]uint64, len(w.health.Blocks))
+		for ch, blocknum := range w.health.Blocks {
+			h.Blocks[ch] = blocknum
+		}
+		w.mu.Unlock()
+		h.Queued = len(w.queue)
+		health = append(health, h)
+	}
+	return health
+}
+
+func (f *Fanout) run(ctx context.Context, w *worker) {
+	l, ok := ctx.Value("log").(*zap.Logger)
+	if !ok {
+		l = zap.NewNop()
+	}
+
+	for {
+		var queued queuedBlock
+		select {
+		case <-ctx.Done():
+			return
+		case queued = <-w.queue:
+		}
+
+		delay := f.opts.RetryMin
+		for {
+			err := f.deliver(ctx, w, queued.ch, queued.block)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			l.Error("sink failed", zap.Error(err), zap.String("sink", w.Name), zap.String("channel", queued.ch))
+			w.mu.Lock()
+			w.health.Healthy = false
+			w.health.Retries++
+			w.health.LastError = err.Error()
+			now := time.Now().UTC()
+			w.health.LastErrAt = &now
+			w.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > f.opts.RetryMax {
+				delay = f.opts.RetryMax
+			}
+		}
+	}
+}
+
+// deliver processes the block and blocks missed by the sink before it
+func (f *Fanout) deliver(ctx context.Context, w *worker, ch string, block *blockhandler.CustomBlock) error {
+	last, ok, err := f.read.GetCheckpoint(ctx, ch, CheckpointName(w.Name))
+	if err != nil {
+		return errors.Wrap(err, "failed to get sink checkpoint")
+	}
+
+	var next uint64
+	if ok {
+		next = last + 1
+	}
+	if block.Number < next {
+		return nil
+	}
+
+	for from := next; from < block.Number; from += replayBatch {
+		to := from + replayBatch - 1
+		if to >= block.Number {
+			to = block.Number - 1
+		}
+		blocks, err := f.stored(ctx, ch, from, to)
+		if err != nil {
+			return errors.Wrapf(err, "failed to replay blocks %d-%d", from, to)
+		}
+		for _, b := range blocks {
+			if err = f.process(ctx, w, ch, b); err != nil {
+				return err
+			}
+		}
+	}
+
+	return f.process(ctx, w, ch, block)
+}
+
+func (f *Fanout) process(ctx context.Context, w *worker, ch string, block *blockhandler.CustomBlock) error {
+	if err := w.Sink.ProcessBlock(ctx, ch, block); err != nil {
+		return errors.Wrapf(err, "failed to process block %d", block.Number)
+	}
+	if err := f.read.SetCheckpoint(ctx, ch, CheckpointName(w.Name), block.Number); err != nil {
+		return errors.Wrap(err, "failed to set sink checkpoint")
+	}
+
+	w.mu.Lock()
+	w.health.Healthy = true
+	w.health.Blocks[ch] = block.Number
+	w.mu.Unlock()
+	return nil
+}
+
+// stored restores blocks [from, to] from the read backend, key history is restored from payloads of valid txs,
+// original blocks and chaincode events are restored from the archive
+func (f *Fanout) stored(ctx context.Context, ch string, from, to uint64) ([]*blockhandler.CustomBlock, error) {
+	byNumber := make(map[uint64]*blockhandler.CustomBlock)
+	add := func(txs []db.Tx) {
+		for _, tx := range txs {
+			block, ok := byNumber[tx.Blocknum]
+			if !ok {
+				block = &blockhandler.CustomBlock{Number: tx.Blocknum}
+				byNumber[tx.Blocknum] = block
+			}
+			block.Txs = append(block.Txs, tx)
+		}
+	}
+
+	// zero ToBlock of the filter means no upper bound
+	if to == 0 {
+		txs, err := f.read.GetByBlocknum(ctx, ch, 0)
+		if err != nil {
+			return nil, err
+		}
+		add(txs)
+	} else {
+		filter := db.Filter{FromBlock: from, ToBlock: to, Page: db.Page{Limit: db.MaxPageLimit}}
+		for {
+			txs, next, err := f.read.Query(ctx, ch, filter)
+			if err != nil {
+				return nil, err
+			}
+			add(txs)
+			if next == "" || len(txs) == 0 {
+				break
+			}
+			filter.Cursor = next
+		}
+	}
+
+	blocks := make([]*blockhandler.CustomBlock, 0, len(byNumber))
+	for _, block := range byNumber {
+		// txs of the block can be returned in any order by cassandra
+		sort.Slice(block.Txs, func(i, j int) bool { return block.Txs[i].TxNum < block.Txs[j].TxNum })
+
+		var valid []db.Tx
+		for _, tx := range block.Txs {
+			if tx.ValidationCode == int32(peer.TxValidationCode_VALID) {
+				valid = append(valid, tx)
+			}
+		}
+		history, err := state.WritesFromTxs(valid)
+		if err != nil {
+			return nil, err
+		}
+		block.KeyHistory = history
+
+		if f.blocks != nil {
+			raw, err := f.blocks.GetRawBlock(ctx, ch, block.Number)
+			if err != nil && err.Error() != db.NOT_FOUND_ERR {
+				return nil, err
+			}
+			if err == nil {
+				if block.Block, err = protoutil.UnmarshalBlock(raw); err != nil {
+					return nil, err
+				}
+				// chaincode events aren't stored, they are restored from the original block
+				handled, err := blockhandler.HandleBlock(block.Block)
+				if err != nil {
+					return nil, err
+				}
+				block.Events = handled.Events
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Number < blocks[j].Number })
+
+	return blocks, nil
+}
+
+// StorageSink writes blocks into the storage. Channels are initialized on the first block.
+type StorageSink struct {
+	storage db.Storage
+
+	mu          sync.Mutex
+	initialized map[string]bool
+}
+
+// NewStorageSink creates sink of connected storage
+func NewStorageSink(storage db.Storage) *StorageSink {
+	return &StorageSink{storage: storage, initialized: make(map[string]bool)}
+}
+
+func (s *StorageSink) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	s.mu.Lock()
+	initialized := s.initialized[ch]
+	s.mu.Unlock()
+	if !initialized {
+		if err := s.storage.Init(ctx, ch); err != nil {
+			return errors.Wrap(err, "failed to init channel")
+		}
+		s.mu.Lock()
+		s.initialized[ch] = true
+		s.mu.Unlock()
+	}
+
+	for _, tx := range block.Txs {
+		if err := s.storage.Insert(ctx, ch, tx); err != nil {
+			return errors.Wrapf(err, "failed to store tx %s", tx.Txid)
+		}
+	}
+	return errors.Wrap(s.storage.InsertKeyHistory(ctx, ch, block.KeyHistory), "failed to store key history")
+}
@@ -0,0 +1,84 @@
+package sink
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySink fails the first attempts and records processed blocks
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	blocks   []uint64
+	history  int
+}
+
+func (s *flakySink) ProcessBlock(_ context.Context, _ string, block *blockhandler.CustomBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink is unavailable")
+	}
+	s.blocks = append(s.blocks, block.Number)
+	s.history += len(block.KeyHistory)
+	return nil
+}
+
+func (s *flakySink) processed() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint64(nil), s.blocks...)
+}
+
+func TestFanout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// blocks 0-2 were stored before the sink was added
+	storage := newStorage(0, 1, 2, 3)
+	flaky := &flakySink{failures: 2}
+	healthy := &flakySink{}
+	fanout := NewFanout(storage, nil, []FanoutSink{{Name: "flaky", Sink: flaky}, {Name: "healthy", Sink: healthy}},
+		FanoutOptions{QueueSize: 1, RetryMin: time.Millisecond, RetryMax: time.Millisecond})
+
+	// the queue is full, so block 4 is dropped and replayed later
+	require.NoError(t, fanout.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 3, Txs: testTxs(3)}))
+	storage.Add("mychannel", testTxs(4)...)
+	require.NoError(t, fanout.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 4, Txs: testTxs(4)}))
+	assert.Equal(t, uint64(1), fanout.Health()[0].Dropped)
+
+	done := make(chan struct{})
+	go func() {
+		fanout.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(flaky.processed()) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, []uint64{0, 1, 2, 3}, flaky.processed())
+	assert.Equal(t, 3, flaky.history)
+
+	storage.Add("mychannel", testTxs(5)...)
+	require.NoError(t, fanout.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 5, Txs: testTxs(5)}))
+	require.Eventually(t, func() bool { return len(healthy.processed()) == 6 }, time.Second, time.Millisecond)
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5}, healthy.processed())
+
+	cancel()
+	<-done
+
+	health := fanout.Health()
+	assert.True(t, health[0].Healthy)
+	assert.Equal(t, uint64(2), health[0].Retries)
+	assert.Contains(t, health[0].LastError, "sink is unavailable")
+	assert.Equal(t, uint64(5), health[1].Blocks["mychannel"])
+	checkpoint, _, err := storage.GetCheckpoint(ctx, "mychannel", CheckpointName("healthy"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), checkpoint)
+}
@@ -0,0 +1,217 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Encodings of Kafka messages
+const (
+	// EncodingJSON encodes blocks as models.Block, other messages as db structs
+	EncodingJSON = "json"
+	// EncodingProto encodes blocks as pb.RawBlock, txs as pb.Entry, writes as pb.KeyModification and
+	// events as peer.ChaincodeEvent
+	EncodingProto = "protobuf"
+)
+
+// Headers of Kafka messages
+const (
+	HeaderChannel  = "fabex-channel"
+	HeaderBlocknum = "fabex-blocknum"
+	HeaderTxnum    = "fabex-txnum"
+)
+
+// KafkaTopics are topics of published messages, messages of a kind aren't published if its topic is empty
+type KafkaTopics struct {
+	// Blocks are keyed by <channel>/<blocknum>
+	Blocks string
+	// Transactions are keyed by txid
+	Transactions string
+	// Writes are keyed by the written key
+	Writes string
+	// Events are keyed by txid
+	Events string
+}
+
+// KafkaOptions configures KafkaSink
+type KafkaOptions struct {
+	Topics KafkaTopics
+	// Encoding is EncodingJSON or EncodingProto
+	Encoding string
+	// Archived is true if original blocks are archived, protobuf encoded blocks are published only with the archive
+	Archived bool
+}
+
+// NewKafkaProducer connects to brokers. If transactionalID is set the producer is idempotent and messages of
+// every block are published in a single Kafka transaction.
+func NewKafkaProducer(brokers []string, transactionalID string) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.ClientID = "fabex"
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	if transactionalID != "" {
+		config.Version = sarama.V2_0_0_0
+		config.Producer.Idempotent = true
+		config.Producer.Transaction.ID = transactionalID
+		config.Net.MaxOpenRequests = 1
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	return producer, errors.Wrap(err, "failed to create kafka producer")
+}
+
+// KafkaSink publishes decoded blocks, txs, writes of valid txs and chaincode events.
+// Run it through Fanout, so the block is published before its checkpoint is set and blocks missed
+// by the sink are published after restart. Messages of the block are published atomically if the producer
+// is transactional, so consumers reading committed messages see every block once unless fabex fails
+// between the commit and the checkpoint. Original blocks and events of replayed blocks are published only if
+// the archive is enabled, protobuf encoded blocks which weren't archived are skipped.
+type KafkaSink struct {
+	producer sarama.SyncProducer
+	opts     KafkaOptions
+}
+
+// NewKafkaSink creates sink publishing messages with the producer
+func NewKafkaSink(producer sarama.SyncProducer, opts KafkaOptions) (*KafkaSink, error) {
+	switch opts.Encoding {
+	case "":
+		opts.Encoding = EncodingJSON
+	case EncodingJSON, EncodingProto:
+	default:
+		return nil, errors.Errorf("unknown kafka encoding %s", opts.Encoding)
+	}
+	if opts.Encoding == EncodingProto && opts.Topics.Blocks != "" && !opts.Archived {
+		return nil, errors.New("protobuf encoded blocks are published from the archive, enable it or unset the blocks topic")
+	}
+	return &KafkaSink{producer: producer, opts: opts}, nil
+}
+
+func (s *KafkaSink) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	msgs, err := s.messages(ctx, ch, block)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	if !s.producer.IsTransactional() {
+		return errors.Wrap(s.producer.SendMessages(msgs), "failed to publish messages")
+	}
+
+	if err = s.producer.BeginTxn(); err != nil {
+		return errors.Wrap(err, "failed to begin kafka transaction")
+	}
+	if err = s.producer.SendMessages(msgs); err != nil {
+		if abortErr := s.producer.AbortTxn(); abortErr != nil {
+			return errors.Wrapf(err, "failed to publish messages, abort failed: %s", abortErr)
+		}
+		return errors.Wrap(err, "failed to publish messages")
+	}
+	return errors.Wrap(s.producer.CommitTxn(), "failed to commit kafka transaction")
+}
+
+// Close closes the producer
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
+
+func (s *KafkaSink) messages(ctx context.Context, ch string, block *blockhandler.CustomBlock) ([]*sarama.ProducerMessage, error) {
+	var msgs []*sarama.ProducerMessage
+	add := func(topic, key string, txnum *uint64, value []byte) {
+		headers := []sarama.RecordHeader{
+			{Key: []byte(HeaderChannel), Value: []byte(ch)},
+			{Key: []byte(HeaderBlocknum), Value: []byte(strconv.FormatUint(block.Number, 10))},
+		}
+		if txnum != nil {
+			headers = append(headers, sarama.RecordHeader{Key: []byte(HeaderTxnum), Value: []byte(strconv.FormatUint(*txnum, 10))})
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:   topic,
+			Key:     sarama.StringEncoder(key),
+			Value:   sarama.ByteEncoder(value),
+			Headers: headers,
+		})
+	}
+	protoEncoding := s.opts.Encoding == EncodingProto
+
+	if topic := s.opts.Topics.Blocks; topic != "" {
+		var (
+			value []byte
+			err   error
+		)
+		if protoEncoding {
+			// blocks indexed before the archive was enabled are replayed from storage without the original block
+			if block.Block != nil {
+				var raw []byte
+				if raw, err = proto.Marshal(block.Block); err == nil {
+					value, err = proto.Marshal(&pb.RawBlock{Block: raw, Headerhash: protoutil.BlockHeaderHash(block.Block.Header)})
+				}
+			} else if l, ok := ctx.Value("log").(*zap.Logger); ok {
+				l.Warn("block isn't archived, it's not published", zap.String("channel", ch), zap.Uint64("block", block.Number))
+			}
+		} else {
+			value, err = encodeJSON(ch, block.Number, block.Txs)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode block %d", block.Number)
+		}
+		if value != nil {
+			add(topic, ch+"/"+strconv.FormatUint(block.Number, 10), nil, value)
+		}
+	}
+
+	if topic := s.opts.Topics.Transactions; topic != "" {
+		for i := range block.Txs {
+			tx := block.Txs[i]
+			value, err := encode(protoEncoding, pb.EntryFromTx(tx), tx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to encode tx %s", tx.Txid)
+			}
+			add(topic, tx.Txid, &tx.TxNum, value)
+		}
+	}
+
+	if topic := s.opts.Topics.Writes; topic != "" {
+		for i := range block.KeyHistory {
+			mod := block.KeyHistory[i]
+			value, err := encode(protoEncoding, pb.KeyModificationFromDB(mod), mod)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to encode write of key %s", mod.Key)
+			}
+			add(topic, mod.Key, &mod.TxNum, value)
+		}
+	}
+
+	if topic := s.opts.Topics.Events; topic != "" {
+		for i := range block.Events {
+			event := block.Events[i]
+			msg := &peer.ChaincodeEvent{ChaincodeId: event.Chaincode, TxId: event.Txid, EventName: event.Name, Payload: event.Payload}
+			value, err := encode(protoEncoding, msg, event)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to encode event %s of tx %s", event.Name, event.Txid)
+			}
+			add(topic, event.Txid, &event.TxNum, value)
+		}
+	}
+
+	return msgs, nil
+}
+
+func encode(protoEncoding bool, msg proto.Message, v interface{}) ([]byte, error) {
+	if protoEncoding {
+		return proto.Marshal(msg)
+	}
+	return json.Marshal(v)
+}
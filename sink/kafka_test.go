@@ -0,0 +1,179 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	pb "github.com/hyperledger-labs/fabex/proto"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTopics = KafkaTopics{Blocks: "blocks", Transactions: "txs", Writes: "writes", Events: "events"}
+
+func testKafkaBlock(blocknum uint64) *blockhandler.CustomBlock {
+	return &blockhandler.CustomBlock{
+		Number:     blocknum,
+		Txs:        testTxs(blocknum),
+		KeyHistory: []db.KeyModification{{ChannelId: "mychannel", Namespace: "fabcar", Key: "a", Txid: "tx", Blocknum: blocknum, Value: []byte("1")}},
+		Events:     []db.ChaincodeEvent{{ChannelId: "mychannel", Txid: "tx", Blocknum: blocknum, Chaincode: "fabcar", Name: "created"}},
+		Block:      protoutil.NewBlock(blocknum, nil),
+	}
+}
+
+// recordingProducer expects n messages and records them
+func recordingProducer(t *testing.T, n int) (*mocks.SyncProducer, func() []*sarama.ProducerMessage) {
+	config := mocks.NewTestConfig()
+	config.Version = sarama.V2_0_0_0
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Transaction.ID = "fabex"
+	config.Net.MaxOpenRequests = 1
+	producer := mocks.NewSyncProducer(t, config)
+
+	var (
+		mu   sync.Mutex
+		msgs []*sarama.ProducerMessage
+	)
+	for i := 0; i < n; i++ {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			msgs = append(msgs, msg)
+			return nil
+		})
+	}
+	return producer, func() []*sarama.ProducerMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return msgs
+	}
+}
+
+func encoded(t *testing.T, enc sarama.Encoder) []byte {
+	data, err := enc.Encode()
+	require.NoError(t, err)
+	return data
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer, published := recordingProducer(t, 4)
+	sink, err := NewKafkaSink(producer, KafkaOptions{Topics: testTopics, Encoding: EncodingJSON})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.ProcessBlock(context.Background(), "mychannel", testKafkaBlock(7)))
+	assert.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+
+	msgs := published()
+	require.Len(t, msgs, 4)
+	var topics, keys []string
+	for _, msg := range msgs {
+		topics = append(topics, msg.Topic)
+		keys = append(keys, string(encoded(t, msg.Key)))
+	}
+	assert.Equal(t, []string{"blocks", "txs", "writes", "events"}, topics)
+	assert.Equal(t, []string{"mychannel/7", "tx", "a", "tx"}, keys)
+	assert.Equal(t, []sarama.RecordHeader{
+		{Key: []byte(HeaderChannel), Value: []byte("mychannel")},
+		{Key: []byte(HeaderBlocknum), Value: []byte("7")},
+		{Key: []byte(HeaderTxnum), Value: []byte("0")},
+	}, msgs[1].Headers)
+
+	var tx db.Tx
+	require.NoError(t, json.Unmarshal(encoded(t, msgs[1].Value), &tx))
+	assert.Equal(t, uint64(7), tx.Blocknum)
+	var event db.ChaincodeEvent
+	require.NoError(t, json.Unmarshal(encoded(t, msgs[3].Value), &event))
+	assert.Equal(t, "created", event.Name)
+
+	require.NoError(t, producer.Close())
+}
+
+func TestKafkaSinkProto(t *testing.T) {
+	producer, published := recordingProducer(t, 3)
+	opts := KafkaOptions{Topics: KafkaTopics{Blocks: "blocks", Transactions: "txs"}, Encoding: EncodingProto}
+	// protobuf encoded blocks are published from the archive only
+	_, err := NewKafkaSink(producer, opts)
+	assert.Error(t, err)
+	opts.Archived = true
+	sink, err := NewKafkaSink(producer, opts)
+	require.NoError(t, err)
+
+	block := testKafkaBlock(7)
+	require.NoError(t, sink.ProcessBlock(context.Background(), "mychannel", block))
+	msgs := published()
+	require.Len(t, msgs, 2)
+
+	var raw pb.RawBlock
+	require.NoError(t, proto.Unmarshal(encoded(t, msgs[0].Value), &raw))
+	assert.Equal(t, protoutil.BlockHeaderHash(block.Block.Header), raw.Headerhash)
+
+	var entry pb.Entry
+	require.NoError(t, proto.Unmarshal(encoded(t, msgs[1].Value), &entry))
+	assert.Equal(t, "tx", entry.Txid)
+	assert.Equal(t, "fabcar", entry.Chaincode)
+
+	// blocks indexed before the archive was enabled are skipped, their txs are published
+	block.Block = nil
+	require.NoError(t, sink.ProcessBlock(context.Background(), "mychannel", block))
+	msgs = published()
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "txs", msgs[2].Topic)
+
+	_, err = NewKafkaSink(producer, KafkaOptions{Encoding: "avro"})
+	assert.Error(t, err)
+	require.NoError(t, producer.Close())
+}
+
+// TestKafkaSinkBroker publishes blocks through fan-out to the in-process mock broker
+func TestKafkaSinkBroker(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	metadata := sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+	for _, topic := range []string{"blocks", "txs", "writes", "events"} {
+		metadata.SetLeader(topic, 0, broker.BrokerID())
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadata,
+		// the producer sends v3 requests by default
+		"ProduceRequest": sarama.NewMockProduceResponse(t).SetVersion(3),
+	})
+
+	producer, err := NewKafkaProducer([]string{broker.Addr()}, "")
+	require.NoError(t, err)
+	sink, err := NewKafkaSink(producer, KafkaOptions{Topics: testTopics})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	storage := newStorage(0)
+	fanout := NewFanout(storage, nil, []FanoutSink{{Name: "kafka", Sink: sink}}, FanoutOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fanout.Run(ctx)
+
+	// block 0 is replayed from storage before block 1
+	storage.Add("mychannel", testTxs(1)...)
+	require.NoError(t, fanout.ProcessBlock(ctx, "mychannel", testKafkaBlock(1)))
+	require.Eventually(t, func() bool {
+		blocknum, ok, _ := storage.GetCheckpoint(ctx, "mychannel", CheckpointName("kafka"))
+		return ok && blocknum == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var produced int
+	for _, res := range broker.History() {
+		if _, ok := res.Request.(*sarama.ProduceRequest); ok {
+			produced++
+		}
+	}
+	assert.NotZero(t, produced)
+	assert.True(t, fanout.Health()[0].Healthy)
+}
@@ -0,0 +1,329 @@
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+)
+
+// Formats of exported blocks
+const (
+	// FormatRaw is marshaled common.Block
+	FormatRaw = "raw"
+	// FormatJSON is models.Block, the same as REST API returns
+	FormatJSON = "json"
+)
+
+// DefaultSegmentSize is the number of blocks listed by a single manifest
+const DefaultSegmentSize = 1000
+
+// ObjectOptions configures export of blocks into object storage
+type ObjectOptions struct {
+	// Name is the name of the sink checkpoint in storage
+	Name string
+	// Prefix is prepended to all keys, e.g. fabex/
+	Prefix string
+	// Format is FormatRaw or FormatJSON
+	Format string
+	// SegmentSize is the number of blocks listed by a single manifest, DefaultSegmentSize if it's 0
+	SegmentSize uint64
+}
+
+// Manifest lists exported blocks of the segment [FirstBlock, FirstBlock+SegmentSize)
+type Manifest struct {
+	Channel    string          `json:"channel"`
+	Format     string          `json:"format"`
+	FirstBlock uint64          `json:"firstblock"`
+	Blocks     []ManifestEntry `json:"blocks"`
+	Updated    time.Time       `json:"updated"`
+}
+
+type ManifestEntry struct {
+	Blocknum uint64 `json:"blocknum"`
+	Key      string `json:"key"`
+	Size     int    `json:"size"`
+	// SHA256 is hex encoded hash of the object
+	SHA256 string `json:"sha256"`
+	// HeaderHash is hex encoded hash of the block header, it's set for raw blocks only
+	HeaderHash string `json:"headerhash,omitempty"`
+}
+
+// ChannelManifest lists manifests of the channel
+type ChannelManifest struct {
+	Channel     string   `json:"channel"`
+	Format      string   `json:"format"`
+	SegmentSize uint64   `json:"segmentsize"`
+	Manifests   []string `json:"manifests"`
+}
+
+// ObjectSink exports blocks into object storage. Keys are
+//
+//	<prefix><channel>/<blocknum>.block|.json    exported block, blocknum is zero padded to 20 digits
+//	<prefix><channel>/manifests/<firstblock>.json    Manifest of the segment
+//	<prefix><channel>/manifest.json    ChannelManifest
+//
+// Exported blocks are checkpointed in storage, so export resumes after restart.
+type ObjectSink struct {
+	storage db.Storage
+	// blocks is used for exporting raw blocks missed by the sink, can be nil
+	blocks db.RawBlockStore
+	store  ObjectStore
+	opts   ObjectOptions
+
+	mu sync.Mutex
+	// manifests are current segments of channels
+	manifests map[string]*Manifest
+}
+
+// NewObjectSink creates sink, blocks are used to export raw blocks indexed before the sink was enabled and can be nil
+func NewObjectSink(storage db.Storage, blocks db.RawBlockStore, store ObjectStore, opts ObjectOptions) (*ObjectSink, error) {
+	if opts.Format != FormatRaw && opts.Format != FormatJSON {
+		return nil, errors.Errorf("unknown format %s", opts.Format)
+	}
+	if opts.Name == "" {
+		return nil, errors.New("sink name must be specified")
+	}
+	if opts.SegmentSize == 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+
+	return &ObjectSink{storage: storage, blocks: blocks, store: store, opts: opts, manifests: make(map[string]*Manifest)}, nil
+}
+
+// ProcessBlock exports the block. Already exported blocks are skipped. Blocks missed by the sink (e.g. it was enabled
+// for already indexed channel) are exported first from storage. Raw blocks which aren't archived can't be exported,
+// so an error is returned and the checkpoint stays before the gap.
+func (s *ObjectSink) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	last, ok, err := s.storage.GetCheckpoint(ctx, ch, s.opts.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sink checkpoint")
+	}
+
+	var next uint64
+	if ok {
+		next = last + 1
+	}
+	if block.Number < next {
+		return nil
+	}
+
+	for num := next; num < block.Number; num++ {
+		data, headerHash, found, err := s.stored(ctx, ch, num)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get stored block %d", num)
+		}
+		if !found {
+			if s.opts.Format == FormatRaw {
+				return errors.Errorf("missed block %d isn't archived, it can't be exported in raw format", num)
+			}
+			continue
+		}
+		if err = s.export(ctx, ch, num, data, headerHash); err != nil {
+			return err
+		}
+	}
+
+	data, headerHash, err := s.encode(ch, block)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode block %d", block.Number)
+	}
+	return s.export(ctx, ch, block.Number, data, headerHash)
+}
+
+func (s *ObjectSink) encode(ch string, block *blockhandler.CustomBlock) ([]byte, []byte, error) {
+	if s.opts.Format == FormatRaw {
+		if block.Block == nil {
+			return nil, nil, errors.New("no original block")
+		}
+		raw, err := proto.Marshal(block.Block)
+		if err != nil {
+			return nil, nil, err
+		}
+		return raw, protoutil.BlockHeaderHash(block.Block.Header), nil
+	}
+
+	data, err := encodeJSON(ch, block.Number, block.Txs)
+	return data, nil, err
+}
+
+func encodeJSON(ch string, blocknum uint64, txs []db.Tx) ([]byte, error) {
+	blocks, err := helpers.PackTxsToBlocks(txs)
+	if err != nil {
+		return nil, err
+	}
+	block := models.Block{ChannelId: ch, Blocknum: blocknum}
+	if len(blocks) != 0 {
+		block = blocks[0]
+	}
+	return json.Marshal(block)
+}
+
+// stored encodes the block from storage, found is false if the block isn't stored
+func (s *ObjectSink) stored(ctx context.Context, ch string, blocknum uint64) (data, headerHash []byte, found bool, err error) {
+	if s.opts.Format == FormatRaw {
+		if s.blocks == nil {
+			return nil, nil, false, nil
+		}
+		raw, err := s.blocks.GetRawBlock(ctx, ch, blocknum)
+		if err != nil {
+			if err.Error() == db.NOT_FOUND_ERR {
+				return nil, nil, false, nil
+			}
+			return nil, nil, false, err
+		}
+		block, err := protoutil.UnmarshalBlock(raw)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return raw, protoutil.BlockHeaderHash(block.Header), true, nil
+	}
+
+	txs, err := s.storage.GetByBlocknum(ctx, ch, blocknum)
+	if err != nil || len(txs) == 0 {
+		return nil, nil, false, err
+	}
+	data, err = encodeJSON(ch, blocknum, txs)
+	return data, nil, err == nil, err
+}
+
+func (s *ObjectSink) channelKey(ch string) string {
+	return s.opts.Prefix + ch
+}
+
+// BlockKey returns the key of the exported block
+func (s *ObjectSink) BlockKey(ch string, blocknum uint64) string {
+	ext := "block"
+	if s.opts.Format == FormatJSON {
+		ext = "json"
+	}
+	return path.Join(s.channelKey(ch), fmt.Sprintf("%020d.%s", blocknum, ext))
+}
+
+func (s *ObjectSink) manifestKey(ch string, firstBlock uint64) string {
+	return path.Join(s.channelKey(ch), "manifests", fmt.Sprintf("%020d.json", firstBlock))
+}
+
+// export stores the block, adds it to the segment manifest and moves the checkpoint. If export is interrupted,
+// the block is exported again and replaces the previous objects.
+func (s *ObjectSink) export(ctx context.Context, ch string, blocknum uint64, data, headerHash []byte) error {
+	key := s.BlockKey(ch, blocknum)
+	contentType := "application/octet-stream"
+	if s.opts.Format == FormatJSON {
+		contentType = "application/json"
+	}
+	if err := s.store.PutObject(ctx, key, data, contentType); err != nil {
+		return errors.Wrapf(err, "failed to export block %d", blocknum)
+	}
+
+	hash := sha256.Sum256(data)
+	entry := ManifestEntry{Blocknum: blocknum, Key: key, Size: len(data), SHA256: hex.EncodeToString(hash[:])}
+	if headerHash != nil {
+		entry.HeaderHash = hex.EncodeToString(headerHash)
+	}
+	if err := s.addToManifest(ctx, ch, entry); err != nil {
+		return errors.Wrapf(err, "failed to update manifest of block %d", blocknum)
+	}
+
+	return errors.Wrap(s.storage.SetCheckpoint(ctx, ch, s.opts.Name, blocknum), "failed to set sink checkpoint")
+}
+
+func (s *ObjectSink) addToManifest(ctx context.Context, ch string, entry ManifestEntry) error {
+	s.mu.Lock()
+	manifest := s.manifests[ch]
+	s.mu.Unlock()
+
+	firstBlock := entry.Blocknum - entry.Blocknum%s.opts.SegmentSize
+	if manifest == nil || manifest.FirstBlock != firstBlock {
+		var err error
+		if manifest, err = s.loadManifest(ctx, ch, firstBlock); err != nil {
+			return err
+		}
+	}
+
+	// the block is exported again after interrupted export
+	i := sort.Search(len(manifest.Blocks), func(i int) bool { return manifest.Blocks[i].Blocknum >= entry.Blocknum })
+	if i < len(manifest.Blocks) && manifest.Blocks[i].Blocknum == entry.Blocknum {
+		manifest.Blocks[i] = entry
+	} else {
+		manifest.Blocks = append(manifest.Blocks, ManifestEntry{})
+		copy(manifest.Blocks[i+1:], manifest.Blocks[i:])
+		manifest.Blocks[i] = entry
+	}
+	manifest.Updated = time.Now().UTC()
+
+	if err := s.putJSON(ctx, s.manifestKey(ch, firstBlock), manifest); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.manifests[ch] = manifest
+	s.mu.Unlock()
+	return nil
+}
+
+// loadManifest loads manifest of the segment, the new segment is added to the channel manifest
+func (s *ObjectSink) loadManifest(ctx context.Context, ch string, firstBlock uint64) (*Manifest, error) {
+	channelKey := path.Join(s.channelKey(ch), "manifest.json")
+	channelManifest := &ChannelManifest{}
+	if err := s.getJSON(ctx, channelKey, channelManifest); err != nil {
+		if err.Error() != db.NOT_FOUND_ERR {
+			return nil, err
+		}
+		channelManifest = &ChannelManifest{Channel: ch, Format: s.opts.Format, SegmentSize: s.opts.SegmentSize}
+	}
+	if channelManifest.Format != s.opts.Format || channelManifest.SegmentSize != s.opts.SegmentSize {
+		return nil, errors.Errorf("channel %s is exported with format %s and segment size %d", ch, channelManifest.Format, channelManifest.SegmentSize)
+	}
+
+	key := s.manifestKey(ch, firstBlock)
+	manifest := &Manifest{}
+	err := s.getJSON(ctx, key, manifest)
+	if err == nil {
+		return manifest, nil
+	}
+	if err.Error() != db.NOT_FOUND_ERR {
+		return nil, err
+	}
+
+	i := sort.SearchStrings(channelManifest.Manifests, key)
+	if i == len(channelManifest.Manifests) || channelManifest.Manifests[i] != key {
+		channelManifest.Manifests = append(channelManifest.Manifests, "")
+		copy(channelManifest.Manifests[i+1:], channelManifest.Manifests[i:])
+		channelManifest.Manifests[i] = key
+		if err = s.putJSON(ctx, channelKey, channelManifest); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Manifest{Channel: ch, Format: s.opts.Format, FirstBlock: firstBlock}, nil
+}
+
+func (s *ObjectSink) getJSON(ctx context.Context, key string, v interface{}) error {
+	data, err := s.store.GetObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(data, v), "failed to unmarshal %s", key)
+}
+
+func (s *ObjectSink) putJSON(ctx context.Context, key string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.store.PutObject(ctx, key, data, "application/json")
+}
@@ -0,0 +1,148 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memStore) PutObject(_ context.Context, key string, data []byte, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memStore) GetObject(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New(db.NOT_FOUND_ERR)
+	}
+	return data, nil
+}
+
+func (m *memStore) keys() []string {
+	var keys []string
+	for key := range m.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newStorage returns storage with txs of the blocks
+func newStorage(blocknums ...uint64) *dbtest.Storage {
+	storage := dbtest.New()
+	for _, blocknum := range blocknums {
+		storage.Add("mychannel", testTxs(blocknum)...)
+	}
+	return storage
+}
+
+func testTxs(blocknum uint64) []db.Tx {
+	return []db.Tx{{ChannelId: "mychannel", Txid: "tx", Blocknum: blocknum, Hash: "hash", Chaincode: "fabcar", Payload: []byte(`[{"key":"a","value":"MQ=="}]`)}}
+}
+
+func TestObjectSink(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{objects: make(map[string][]byte)}
+	storage := newStorage(0, 3)
+	opts := ObjectOptions{Name: "s3", Prefix: "fabex/", Format: FormatJSON, SegmentSize: 2}
+
+	sink, err := NewObjectSink(storage, nil, store, opts)
+	require.NoError(t, err)
+	for _, num := range []uint64{1, 2, 2} {
+		require.NoError(t, sink.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: num, Txs: testTxs(num)}))
+	}
+
+	// restarted sink resumes from the checkpoint, block 4 isn't stored
+	sink, err = NewObjectSink(storage, nil, store, opts)
+	require.NoError(t, err)
+	require.NoError(t, sink.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 5, Txs: testTxs(5)}))
+	checkpoint, _, err := storage.GetCheckpoint(ctx, "mychannel", "s3")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), checkpoint)
+
+	assert.Equal(t, []string{
+		"fabex/mychannel/00000000000000000000.json",
+		"fabex/mychannel/00000000000000000001.json",
+		"fabex/mychannel/00000000000000000002.json",
+		"fabex/mychannel/00000000000000000003.json",
+		"fabex/mychannel/00000000000000000005.json",
+		"fabex/mychannel/manifest.json",
+		"fabex/mychannel/manifests/00000000000000000000.json",
+		"fabex/mychannel/manifests/00000000000000000002.json",
+		"fabex/mychannel/manifests/00000000000000000004.json",
+	}, store.keys())
+
+	var block models.Block
+	require.NoError(t, json.Unmarshal(store.objects["fabex/mychannel/00000000000000000003.json"], &block))
+	assert.Equal(t, uint64(3), block.Blocknum)
+	assert.Equal(t, "tx", block.Txs[0].Txid)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(store.objects["fabex/mychannel/manifests/00000000000000000002.json"], &manifest))
+	require.Len(t, manifest.Blocks, 2)
+	assert.Equal(t, uint64(2), manifest.FirstBlock)
+	assert.Equal(t, "fabex/mychannel/00000000000000000003.json", manifest.Blocks[1].Key)
+
+	var channelManifest ChannelManifest
+	require.NoError(t, json.Unmarshal(store.objects["fabex/mychannel/manifest.json"], &channelManifest))
+	assert.Len(t, channelManifest.Manifests, 3)
+
+	// the channel can't be exported with other settings into the same prefix
+	sink, err = NewObjectSink(newStorage(), nil, store, ObjectOptions{Name: "s3", Prefix: "fabex/", Format: FormatRaw})
+	require.NoError(t, err)
+	block0 := protoutil.NewBlock(0, nil)
+	assert.Error(t, sink.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 0, Block: block0}))
+
+	// raw blocks missed by the sink must be archived, the checkpoint doesn't move past the gap
+	storage = newStorage()
+	sink, err = NewObjectSink(storage, nil, store, ObjectOptions{Name: "raw", Prefix: "raw/", Format: FormatRaw})
+	require.NoError(t, err)
+	require.NoError(t, sink.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 0, Block: block0}))
+	assert.Error(t, sink.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 2, Block: protoutil.NewBlock(2, nil)}))
+	checkpoint, _, err = storage.GetCheckpoint(ctx, "mychannel", "raw")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), checkpoint)
+}
+
+// TestS3Store runs against S3-compatible storage, e.g. MinIO started by make minio
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("FABEX_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("FABEX_TEST_S3_ENDPOINT is not set")
+	}
+
+	ctx := context.Background()
+	store, err := NewS3Store(ctx, S3Options{Endpoint: endpoint, AccessKey: "minioadmin", SecretKey: "minioadmin", Bucket: "fabex-test"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.PutObject(ctx, "mychannel/test.json", []byte("{}"), "application/json"))
+	data, err := store.GetObject(ctx, "mychannel/test.json")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), data)
+
+	_, err = store.GetObject(ctx, "mychannel/missing.json")
+	require.Error(t, err)
+	assert.Equal(t, db.NOT_FOUND_ERR, err.Error())
+}
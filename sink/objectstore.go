@@ -0,0 +1,81 @@
+// Package sink exports indexed blocks to external systems
+package sink
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+)
+
+// ObjectStore is a bucket of an object storage
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, data []byte, contentType string) error
+	// GetObject returns NOT_FOUND_ERR if there is no such object
+	GetObject(ctx context.Context, key string) ([]byte, error)
+}
+
+// S3Options configures connection to S3-compatible storage, e.g. AWS S3 or MinIO
+type S3Options struct {
+	// Endpoint is host:port of the storage, e.g. s3.amazonaws.com or localhost:9000
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Region    string
+	UseSSL    bool
+	// Bucket is created if it doesn't exist
+	Bucket string
+}
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store connects to the bucket
+func NewS3Store(ctx context.Context, opts S3Options) (ObjectStore, error) {
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 client")
+	}
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check bucket %s", opts.Bucket)
+	}
+	if !exists {
+		if err = client.MakeBucket(ctx, opts.Bucket, minio.MakeBucketOptions{Region: opts.Region}); err != nil {
+			return nil, errors.Wrapf(err, "failed to create bucket %s", opts.Bucket)
+		}
+	}
+
+	return &s3Store{client: client, bucket: opts.Bucket}, nil
+}
+
+func (s *s3Store) PutObject(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: contentType})
+	return errors.Wrapf(err, "failed to put object %s", key)
+}
+
+func (s *s3Store) GetObject(ctx context.Context, key string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get object %s", key)
+	}
+	defer obj.Close()
+
+	// errors of the request are returned by the first read
+	data, err := ioutil.ReadAll(obj)
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, errors.New(db.NOT_FOUND_ERR)
+	}
+	return data, errors.Wrapf(err, "failed to get object %s", key)
+}
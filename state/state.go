@@ -0,0 +1,130 @@
+// Package state materializes world state of channels from valid writes of indexed blocks
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+)
+
+// CheckpointName is the name of the materializer checkpoint in storage
+const CheckpointName = "state"
+
+// Materializer applies writes of blocks to the state store in block order
+type Materializer struct {
+	storage db.Storage
+	store   db.StateStore
+}
+
+// NewMaterializer creates materializer, storage must implement db.StateStore
+func NewMaterializer(storage db.Storage) (*Materializer, error) {
+	store, ok := storage.(db.StateStore)
+	if !ok {
+		return nil, errors.New("state is not supported by the database")
+	}
+	return &Materializer{storage: storage, store: store}, nil
+}
+
+// ProcessBlock applies writes of the block. Already applied blocks are skipped. If some blocks before this one
+// were not applied (e.g. state was enabled for already indexed channel), they are replayed from stored txs first.
+func (m *Materializer) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	last, ok, err := m.storage.GetCheckpoint(ctx, ch, CheckpointName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get state checkpoint")
+	}
+
+	var next uint64
+	if ok {
+		next = last + 1
+	}
+	if block.Number < next {
+		return nil
+	}
+	if block.Number > next {
+		if err = m.replay(ctx, ch, next, block.Number-1); err != nil {
+			return errors.Wrapf(err, "failed to replay blocks %d-%d", next, block.Number-1)
+		}
+	}
+
+	if err = m.store.ApplyState(ctx, ch, block.KeyHistory); err != nil {
+		return errors.Wrapf(err, "failed to apply block %d", block.Number)
+	}
+
+	return m.storage.SetCheckpoint(ctx, ch, CheckpointName, block.Number)
+}
+
+// replay applies valid writes of stored txs from the block range
+func (m *Materializer) replay(ctx context.Context, ch string, from, to uint64) error {
+	valid := int32(peer.TxValidationCode_VALID)
+	filter := db.Filter{FromBlock: from, ToBlock: to, ValidationCode: &valid, Page: db.Page{Limit: db.MaxPageLimit}}
+
+	for {
+		txs, next, err := m.storage.Query(ctx, ch, filter)
+		if err != nil {
+			return err
+		}
+
+		writes, err := WritesFromTxs(txs)
+		if err != nil {
+			return err
+		}
+		if err = m.store.ApplyState(ctx, ch, writes); err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+		filter.Cursor = next
+	}
+}
+
+// WritesFromTxs restores key modifications from payloads of stored txs. Txs without chaincode (config txs)
+// are skipped.
+// Txs stored before chaincode and tx type were indexed can't be told from config txs, so an error is returned
+// for them: such channels must be re-derived from raw blocks or reindexed.
+func WritesFromTxs(txs []db.Tx) ([]db.KeyModification, error) {
+	var writes []db.KeyModification
+	for _, tx := range txs {
+		if tx.Chaincode == "" {
+			if tx.TxType == "" {
+				return nil, errors.Errorf("tx %s of block %d has no chaincode and tx type, it was stored by an older version", tx.Txid, tx.Blocknum)
+			}
+			continue
+		}
+
+		var kvs []models.WriteKV
+		if err := json.Unmarshal(tx.Payload, &kvs); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal payload of tx %s", tx.Txid)
+		}
+
+		for _, kv := range kvs {
+			value, err := base64.StdEncoding.DecodeString(kv.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode value of key %s in tx %s", kv.Key, tx.Txid)
+			}
+			objectType, attributes, _ := db.SplitCompositeKey(kv.Key)
+			writes = append(writes, db.KeyModification{
+				ChannelId:  tx.ChannelId,
+				Namespace:  tx.Chaincode,
+				Key:        kv.Key,
+				Blocknum:   tx.Blocknum,
+				TxNum:      tx.TxNum,
+				Txid:       tx.Txid,
+				Value:      value,
+				IsDelete:   kv.Deleted(),
+				Time:       tx.Time,
+				ObjectType: objectType,
+				Attributes: attributes,
+			})
+		}
+	}
+
+	return writes, nil
+}
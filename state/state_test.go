@@ -0,0 +1,104 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/hyperledger-labs/fabex/models"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritesFromTxs(t *testing.T) {
+	payload, err := json.Marshal([]models.WriteKV{
+		{Key: "car1", Value: base64.StdEncoding.EncodeToString([]byte("red"))},
+		{Key: "car2", Value: ""},
+		{Key: "car3", Value: base64.StdEncoding.EncodeToString([]byte("red")), IsDelete: true},
+	})
+	assert.NoError(t, err)
+
+	txs := []db.Tx{
+		{ChannelId: "mychannel", Txid: "config", Blocknum: 0, TxType: "CONFIG"},
+		{ChannelId: "mychannel", Txid: "tx1", Blocknum: 5, Chaincode: "fabcar", TxType: "ENDORSER_TRANSACTION", Payload: payload, Time: 10},
+	}
+
+	writes, err := WritesFromTxs(txs)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.KeyModification{
+		{ChannelId: "mychannel", Namespace: "fabcar", Key: "car1", Blocknum: 5, Txid: "tx1", Value: []byte("red"), Time: 10},
+		{ChannelId: "mychannel", Namespace: "fabcar", Key: "car2", Blocknum: 5, Txid: "tx1", Value: []byte{}, IsDelete: true, Time: 10},
+		{ChannelId: "mychannel", Namespace: "fabcar", Key: "car3", Blocknum: 5, Txid: "tx1", Value: []byte("red"), IsDelete: true, Time: 10},
+	}, writes)
+}
+
+func TestWritesFromLegacyTxs(t *testing.T) {
+	payload, err := json.Marshal([]models.WriteKV{{Key: "car1", Value: base64.StdEncoding.EncodeToString([]byte("red"))}})
+	assert.NoError(t, err)
+
+	// txs stored without chaincode and tx type must not build empty state silently
+	_, err = WritesFromTxs([]db.Tx{{ChannelId: "mychannel", Txid: "tx1", Blocknum: 5, Payload: payload}})
+	assert.Error(t, err)
+}
+
+func TestMaterializer(t *testing.T) {
+	ctx := context.Background()
+	storage := dbtest.New()
+	m, err := NewMaterializer(storage)
+	require.NoError(t, err)
+	value := func(key string) string {
+		mod, err := storage.GetState(ctx, "mychannel", "fabcar", key)
+		if err != nil {
+			return err.Error()
+		}
+		return string(mod.Value)
+	}
+
+	// incremental blocks
+	require.NoError(t, m.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 0, KeyHistory: []db.KeyModification{
+		{Namespace: "fabcar", Key: "car1", Value: []byte("red")},
+		{Namespace: "fabcar", Key: "car2", Value: []byte("blue")},
+	}}))
+	assert.Equal(t, "red", value("car1"))
+	require.NoError(t, m.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 1, KeyHistory: []db.KeyModification{
+		{Namespace: "fabcar", Key: "car1", IsDelete: true},
+	}}))
+	assert.Equal(t, db.NOT_FOUND_ERR, value("car1"))
+	assert.Equal(t, "blue", value("car2"))
+
+	// applied blocks are skipped
+	require.NoError(t, m.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 0, KeyHistory: []db.KeyModification{
+		{Namespace: "fabcar", Key: "car1", Value: []byte("red")},
+	}}))
+	assert.Equal(t, db.NOT_FOUND_ERR, value("car1"))
+
+	// stored blocks after the checkpoint are replayed, invalid txs don't change state
+	write := func(kvs ...models.WriteKV) []byte {
+		payload, err := json.Marshal(kvs)
+		require.NoError(t, err)
+		return payload
+	}
+	invalid := int32(peer.TxValidationCode_MVCC_READ_CONFLICT)
+	storage.Add("mychannel",
+		db.Tx{Txid: "tx2", Blocknum: 2, Chaincode: "fabcar", Payload: write(models.WriteKV{Key: "car3", Value: base64.StdEncoding.EncodeToString([]byte("green"))})},
+		db.Tx{Txid: "tx3", Blocknum: 3, Chaincode: "fabcar", Payload: write(models.WriteKV{Key: "car2", IsDelete: true})},
+		db.Tx{Txid: "tx4", Blocknum: 3, Chaincode: "fabcar", ValidationCode: invalid,
+			Payload: write(models.WriteKV{Key: "car4", Value: base64.StdEncoding.EncodeToString([]byte("black"))})},
+	)
+	require.NoError(t, m.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 4, KeyHistory: []db.KeyModification{
+		{Namespace: "fabcar", Key: "car5", Value: []byte("white")},
+	}}))
+	assert.Equal(t, "green", value("car3"))
+	assert.Equal(t, db.NOT_FOUND_ERR, value("car2"))
+	assert.Equal(t, db.NOT_FOUND_ERR, value("car4"))
+	assert.Equal(t, "white", value("car5"))
+	last, ok, err := storage.GetCheckpoint(ctx, "mychannel", CheckpointName)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, 4, last)
+}
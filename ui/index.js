@@ -8,6 +8,9 @@ let treeData = {
 let errorModal,
     input;
 
+// number of blocks kept in the live block list
+const liveBlocksLimit = 20;
+
 // Initialization of Vuew.js components
 window.onload = function () {
 
@@ -110,6 +113,52 @@ window.onload = function () {
         }
     });
 
+    // live block list fed by server-sent events
+    new Vue({
+        el: '#live',
+        data: {
+            channel: 'mychannel',
+            chaincode: '',
+            keyprefix: '',
+            blocks: [],
+            source: null
+        },
+        methods: {
+            toggle: function () {
+                if (this.source) {
+                    this.source.close();
+                    this.source = null;
+                    return
+                }
+
+                let params = new URLSearchParams();
+                if (this.chaincode) params.set('chaincode', this.chaincode);
+                if (this.keyprefix) params.set('keyprefix', this.keyprefix);
+
+                this.blocks = [];
+                this.source = new EventSource(`/api/${encodeURIComponent(this.channel)}/live?${params}`);
+                this.source.addEventListener('block', (event) => {
+                    this.blocks.unshift(JSON.parse(event.data));
+                    this.blocks.splice(liveBlocksLimit);
+                });
+                this.source.addEventListener('error', (event) => {
+                    // the server sends an error event before closing the stream
+                    if (event.data) {
+                        errorModal.showModal = true;
+                        errorModal.httpCode = 'Live feed';
+                        errorModal.error = event.data;
+                        this.source.close();
+                        this.source = null;
+                    }
+                });
+            },
+            show: function (block) {
+                input.message = block.blocknum;
+                MakeTree(block);
+            }
+        }
+    });
+
     // register modal component
     Vue.component("modal", {
         template: "#modal-template",
@@ -170,9 +219,12 @@ async function GetBlockAndMakeTree() {
         return
     }
 
-    block = ans.data.msg;
+    MakeTree(ans.data.msg);
+}
 
-    console.log(block)
+// MakeTree replaces the tree with the block
+function MakeTree(block) {
+    treeData.children = [];
 
     // parsing json into a tree
     treeData.children.push({name: "Block " + block.blocknum, children: []});
@@ -211,6 +263,11 @@ async function GetBlockAndMakeTree() {
 
             var value = window.atob(block.txs[j].KV[x].value)
 
+            // decoded JSON or protobuf value
+            if (block.txs[j].KV[x].document) {
+                value = JSON.stringify(block.txs[j].KV[x].document)
+            }
+
             if (isConfig) {
                 var key = block.txs[j].KV[x].key
                 if (key == "Groups" || key == "Values" || key == "Policies") {
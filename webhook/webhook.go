@@ -0,0 +1,464 @@
+// Package webhook delivers indexed txs matching subscriptions to HTTP endpoints
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/helpers"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Headers of deliveries
+const (
+	// SignatureHeader is "sha256=" followed by hex encoded HMAC-SHA256 of the body keyed by the subscription secret
+	SignatureHeader    = "X-Fabex-Signature"
+	DeliveryHeader     = "X-Fabex-Delivery"
+	SubscriptionHeader = "X-Fabex-Subscription"
+)
+
+// Defaults of Options
+const (
+	DefaultAttempts  = 5
+	DefaultRetryMin  = time.Second
+	DefaultRetryMax  = time.Minute
+	DefaultTimeout   = 10 * time.Second
+	DefaultQueueSize = 100
+)
+
+// Options configures deliveries, zero values are replaced by defaults
+type Options struct {
+	// Attempts is the number of attempts before the delivery is dead-lettered
+	Attempts int
+	// RetryMin and RetryMax limit the delay between attempts
+	RetryMin time.Duration
+	RetryMax time.Duration
+	// Timeout limits a single request
+	Timeout time.Duration
+	// QueueSize is the number of blocks queued for a subscription, blocks dropped by the full queue are replayed
+	// from stored txs with the next block
+	QueueSize int
+	// AllowedNetworks are CIDR networks of callback targets allowed even if they are loopback, link-local or private
+	AllowedNetworks []string
+}
+
+// Payload is the body of the delivery, it lists matching txs of the block
+type Payload struct {
+	// ID is the same for repeated deliveries of the block, so receivers can skip duplicates
+	ID           string  `json:"id"`
+	Subscription string  `json:"subscription"`
+	Channel      string  `json:"channel"`
+	Blocknum     uint64  `json:"blocknum"`
+	Txs          []db.Tx `json:"txs"`
+}
+
+// Dispatcher posts matching txs of every block to subscribers. Run it through sink.Fanout, so blocks are delivered
+// after restart. Every subscription has its own queue and checkpoint, so a failing subscriber doesn't delay others.
+// The checkpoint is set after the block is delivered, blocks dropped by the full queue or queued when the dispatcher
+// stops are replayed from stored txs with the next block. Deliveries failed after all attempts are stored as dead letters.
+type Dispatcher struct {
+	storage db.Storage
+	client  *http.Client
+	opts    Options
+	allowed []*net.IPNet
+
+	mu      sync.Mutex
+	workers map[string]*worker
+	// subs are cached subscriptions, they are listed again after Invalidate
+	subs []db.Subscription
+}
+
+// worker delivers payloads of the subscription in block order
+type worker struct {
+	queue  chan delivery
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type delivery struct {
+	sub     db.Subscription
+	payload Payload
+}
+
+// NewDispatcher creates dispatcher of subscriptions from storage, stored txs are used to replay missed blocks
+func NewDispatcher(storage db.Storage, opts Options) (*Dispatcher, error) {
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultAttempts
+	}
+	if opts.RetryMin <= 0 {
+		opts.RetryMin = DefaultRetryMin
+	}
+	if opts.RetryMax < opts.RetryMin {
+		opts.RetryMax = DefaultRetryMax
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	d := &Dispatcher{storage: storage, opts: opts, workers: make(map[string]*worker)}
+	for _, cidr := range opts.AllowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid allowed network %s", cidr)
+		}
+		d.allowed = append(d.allowed, network)
+	}
+
+	// targets are checked when they are dialed, so names resolved to internal addresses and redirects are rejected,
+	// proxies aren't used as they would be checked instead of targets
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: opts.Timeout, Control: d.checkDial}).DialContext
+	d.client = &http.Client{Timeout: opts.Timeout, Transport: transport}
+	return d, nil
+}
+
+// checkDial rejects connections to internal addresses which aren't allowed
+func (d *Dispatcher) checkDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return d.checkIP(net.ParseIP(host))
+}
+
+func (d *Dispatcher) checkIP(ip net.IP) error {
+	if ip == nil || !(ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()) {
+		return nil
+	}
+	for _, network := range d.allowed {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return errors.Errorf("target %s is an internal address", ip)
+}
+
+// Validate checks the subscription before it's stored, literal internal addresses and localhost are rejected unless
+// they are allowed
+func (d *Dispatcher) Validate(sub db.Subscription) error {
+	if err := Validate(sub); err != nil {
+		return err
+	}
+	u, _ := url.Parse(sub.URL)
+	host := strings.ToLower(u.Hostname())
+	ip := net.ParseIP(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	return d.checkIP(ip)
+}
+
+// Invalidate drops cached subscriptions, call it after subscriptions are changed
+func (d *Dispatcher) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subs = nil
+}
+
+// subscriptions returns cached subscriptions, they are listed if they aren't cached
+func (d *Dispatcher) subscriptions(ctx context.Context) ([]db.Subscription, error) {
+	d.mu.Lock()
+	subs := d.subs
+	d.mu.Unlock()
+	if subs != nil {
+		return subs, nil
+	}
+
+	subs, err := d.storage.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subs == nil {
+		subs = []db.Subscription{}
+	}
+	d.mu.Lock()
+	d.subs = subs
+	d.mu.Unlock()
+	return subs, nil
+}
+
+// CheckpointName returns the name of the subscription checkpoint in storage
+func CheckpointName(subscriptionID string) string {
+	return "webhook/" + subscriptionID
+}
+
+// ProcessBlock queues the block for subscriptions of the channel, it doesn't wait for deliveries. Blocks without
+// matching txs are queued too, so checkpoints follow the channel. Workers run until ctx is done, workers of deleted
+// subscriptions are stopped.
+func (d *Dispatcher) ProcessBlock(ctx context.Context, ch string, block *blockhandler.CustomBlock) error {
+	subs, err := d.subscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list subscriptions")
+	}
+	d.stopDeleted(subs)
+
+	for _, sub := range subs {
+		if sub.Channel != ch {
+			continue
+		}
+		payload := Payload{ID: DeliveryID(sub.ID, block.Number), Subscription: sub.ID, Channel: ch, Blocknum: block.Number}
+		for _, tx := range block.Txs {
+			if sub.Filter.Match(tx) {
+				payload.Txs = append(payload.Txs, tx)
+			}
+		}
+
+		w, started := d.worker(ctx, sub.ID)
+		if started && block.Number > 0 {
+			if err = d.startCheckpoint(ctx, sub, block.Number-1); err != nil {
+				return errors.Wrapf(err, "subscription %s", sub.ID)
+			}
+		}
+		// the dropped block is replayed with the next one
+		select {
+		case w.queue <- delivery{sub: sub, payload: payload}:
+		default:
+		}
+	}
+	return nil
+}
+
+// startCheckpoint sets the checkpoint of the new subscription to the block before the first processed one, so
+// the block is replayed if it isn't delivered before the dispatcher stops
+func (d *Dispatcher) startCheckpoint(ctx context.Context, sub db.Subscription, blocknum uint64) error {
+	_, ok, err := d.storage.GetCheckpoint(ctx, sub.Channel, CheckpointName(sub.ID))
+	if err != nil || ok {
+		return errors.Wrap(err, "failed to get subscription checkpoint")
+	}
+	return errors.Wrap(d.storage.SetCheckpoint(ctx, sub.Channel, CheckpointName(sub.ID), blocknum), "failed to set subscription checkpoint")
+}
+
+// worker returns the worker of the subscription, it's started if it's not running
+func (d *Dispatcher) worker(ctx context.Context, id string) (w *worker, started bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w, ok := d.workers[id]
+	if ok {
+		select {
+		case <-w.done:
+		default:
+			return w, false
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w = &worker{queue: make(chan delivery, d.opts.QueueSize), cancel: cancel, done: make(chan struct{})}
+	d.workers[id] = w
+	go d.run(ctx, w)
+	return w, true
+}
+
+// stopDeleted stops workers of subscriptions which aren't listed, their queued deliveries are dropped
+func (d *Dispatcher) stopDeleted(subs []db.Subscription) {
+	listed := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		listed[sub.ID] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, w := range d.workers {
+		if !listed[id] {
+			w.cancel()
+			delete(d.workers, id)
+		}
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context, w *worker) {
+	defer close(w.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case del := <-w.queue:
+			if err := d.process(ctx, del); err != nil && ctx.Err() == nil {
+				d.logger(ctx).Error("webhook delivery is delayed", zap.Error(err), zap.String("subscription", del.sub.ID),
+					zap.Uint64("block", del.payload.Blocknum))
+			}
+		}
+	}
+}
+
+// process delivers blocks missed by the subscription since its checkpoint, then the queued block. The checkpoint
+// is set after every block is delivered or dead-lettered.
+func (d *Dispatcher) process(ctx context.Context, del delivery) error {
+	sub, blocknum := del.sub, del.payload.Blocknum
+	last, ok, err := d.storage.GetCheckpoint(ctx, sub.Channel, CheckpointName(sub.ID))
+	if err != nil {
+		return errors.Wrap(err, "failed to get subscription checkpoint")
+	}
+	if ok && blocknum <= last {
+		return nil
+	}
+
+	if ok && last+1 < blocknum {
+		err = helpers.QueryBlocks(ctx, d.storage, sub.Channel, sub.Filter.Filter(), last+1, blocknum-1, func(n uint64, txs []db.Tx) error {
+			payload := Payload{ID: DeliveryID(sub.ID, n), Subscription: sub.ID, Channel: sub.Channel, Blocknum: n, Txs: txs}
+			return d.deliverBlock(ctx, sub, payload)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to replay blocks %d-%d", last+1, blocknum-1)
+		}
+	}
+
+	return d.deliverBlock(ctx, sub, del.payload)
+}
+
+// deliverBlock delivers the payload if it has txs and sets the checkpoint of the subscription
+func (d *Dispatcher) deliverBlock(ctx context.Context, sub db.Subscription, payload Payload) error {
+	if len(payload.Txs) != 0 {
+		if err := d.deliver(ctx, sub, payload); err != nil {
+			return err
+		}
+	}
+	err := d.storage.SetCheckpoint(ctx, sub.Channel, CheckpointName(sub.ID), payload.Blocknum)
+	return errors.Wrap(err, "failed to set subscription checkpoint")
+}
+
+func (d *Dispatcher) logger(ctx context.Context) *zap.Logger {
+	l, ok := ctx.Value("log").(*zap.Logger)
+	if !ok {
+		l = zap.NewNop()
+	}
+	return l
+}
+
+// deliver posts the payload until it's accepted or attempts are exhausted, then it's dead-lettered.
+// An error is returned if ctx is done or the dead letter isn't stored.
+func (d *Dispatcher) deliver(ctx context.Context, sub db.Subscription, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+
+	delay := d.opts.RetryMin
+	for attempt := 1; ; attempt++ {
+		if err = d.post(ctx, sub, payload.ID, body); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.logger(ctx).Warn("webhook delivery failed", zap.Error(err), zap.String("subscription", sub.ID), zap.Int("attempt", attempt))
+		if attempt == d.opts.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > d.opts.RetryMax {
+			delay = d.opts.RetryMax
+		}
+	}
+
+	return d.deadLetter(ctx, sub, payload, err, d.opts.Attempts)
+}
+
+func (d *Dispatcher) deadLetter(ctx context.Context, sub db.Subscription, payload Payload, cause error, attempts int) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+	letter := db.DeadLetter{
+		ID:             payload.ID,
+		SubscriptionID: sub.ID,
+		Channel:        payload.Channel,
+		Blocknum:       payload.Blocknum,
+		Payload:        body,
+		Error:          cause.Error(),
+		Attempts:       attempts,
+		Time:           time.Now().Unix(),
+	}
+	return errors.Wrap(d.storage.AddDeadLetter(ctx, letter), "failed to store dead letter")
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub db.Subscription, id string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(SubscriptionHeader, sub.ID)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// DeliveryID identifies delivery of the block to the subscription
+func DeliveryID(subscriptionID string, blocknum uint64) string {
+	return fmt.Sprintf("%s-%d", subscriptionID, blocknum)
+}
+
+// Sign returns the value of SignatureHeader for the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of the received body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Validate checks the subscription before it's stored
+func Validate(sub db.Subscription) error {
+	if sub.Channel == "" {
+		return errors.New("channel must be specified")
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid url: %s", sub.URL)
+	}
+	return nil
+}
+
+// NewID returns a random subscription ID
+func NewID() string {
+	return randomHex(16)
+}
+
+// NewSecret returns a random HMAC key
+func NewSecret() string {
+	return randomHex(32)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabex/blockhandler"
+	"github.com/hyperledger-labs/fabex/db"
+	"github.com/hyperledger-labs/fabex/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		received []Payload
+	)
+	// the receiver fails the first attempt
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.True(t, Verify("secret", body, r.Header.Get(SignatureHeader)))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, payload.ID, r.Header.Get(DeliveryHeader))
+		received = append(received, payload)
+	}))
+	defer flaky.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	// the receiver doesn't respond until it's released
+	var (
+		release     = make(chan struct{})
+		interrupted = make(chan struct{}, 1)
+		resumed     = make(chan string, 10)
+	)
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the closed connection is noticed after the body is read
+		_, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		select {
+		case <-release:
+			resumed <- r.Header.Get(DeliveryHeader)
+		case <-r.Context().Done():
+			interrupted <- struct{}{}
+		}
+	}))
+	defer hanging.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := dbtest.New()
+	for _, sub := range []db.Subscription{
+		{ID: "cars", Channel: "mychannel", URL: flaky.URL, Secret: "secret", Filter: db.SubscriptionFilter{KeyPrefix: "CAR"}},
+		{ID: "broken", Channel: "mychannel", URL: broken.URL},
+		{ID: "hanging", Channel: "mychannel", URL: hanging.URL},
+		{ID: "other", Channel: "otherchannel", URL: broken.URL},
+	} {
+		require.NoError(t, store.PutSubscription(ctx, sub))
+	}
+	// test servers listen on loopback
+	opts := Options{Attempts: 3, RetryMin: time.Millisecond, RetryMax: time.Millisecond, Timeout: time.Minute, AllowedNetworks: []string{"127.0.0.0/8"}}
+	dispatcher, err := NewDispatcher(store, opts)
+	require.NoError(t, err)
+
+	block := &blockhandler.CustomBlock{Number: 5, Txs: []db.Tx{
+		{Txid: "tx1", Blocknum: 5, Keys: []string{"CAR1"}},
+		{Txid: "tx2", Blocknum: 5, Keys: []string{"BIKE1"}},
+	}}
+	store.Add("mychannel", block.Txs...)
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", block))
+
+	// the hanging receiver doesn't delay others
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, "cars-5", received[0].ID)
+	require.Len(t, received[0].Txs, 1)
+	assert.Equal(t, "tx1", received[0].Txs[0].Txid)
+
+	var letters []db.DeadLetter
+	require.Eventually(t, func() bool {
+		letters, _ = store.ListDeadLetters(ctx, "broken")
+		return len(letters) == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, uint64(5), letters[0].Blocknum)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Contains(t, letters[0].Error, "500")
+
+	// checkpoints are set after delivery
+	require.Eventually(t, func() bool {
+		last, ok, _ := store.GetCheckpoint(ctx, "mychannel", CheckpointName("cars"))
+		return ok && last == 5
+	}, 5*time.Second, time.Millisecond)
+	last, _, err := store.GetCheckpoint(ctx, "mychannel", CheckpointName("hanging"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, last)
+
+	// the delivery interrupted by stop is replayed after restart with the next block
+	cancel()
+	<-interrupted
+	close(release)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	dispatcher, err = NewDispatcher(store, opts)
+	require.NoError(t, err)
+	next := &blockhandler.CustomBlock{Number: 6, Txs: []db.Tx{{Txid: "tx3", Blocknum: 6, Keys: []string{"BIKE2"}}}}
+	store.Add("mychannel", next.Txs...)
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", next))
+	for _, id := range []string{"hanging-5", "hanging-6"} {
+		select {
+		case delivered := <-resumed:
+			assert.Equal(t, id, delivered)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s isn't delivered", id)
+		}
+	}
+	letters, err = store.ListDeadLetters(ctx, "hanging")
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+func TestSubscriptionCache(t *testing.T) {
+	received := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(DeliveryHeader)
+	}))
+	defer receiver.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := dbtest.New()
+	dispatcher, err := NewDispatcher(store, Options{AllowedNetworks: []string{"127.0.0.0/8"}})
+	require.NoError(t, err)
+	block := func(n uint64) *blockhandler.CustomBlock {
+		return &blockhandler.CustomBlock{Number: n, Txs: []db.Tx{{Txid: "tx", Blocknum: n}}}
+	}
+
+	// subscriptions are listed once until they are invalidated
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", block(1)))
+	require.NoError(t, store.PutSubscription(ctx, db.Subscription{ID: "sub", Channel: "mychannel", URL: receiver.URL}))
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", block(2)))
+	dispatcher.Invalidate()
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", block(3)))
+
+	select {
+	case id := <-received:
+		assert.Equal(t, "sub-3", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("block isn't delivered")
+	}
+}
+
+func TestInternalTargets(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := dbtest.New()
+	require.NoError(t, store.PutSubscription(ctx, db.Subscription{ID: "internal", Channel: "mychannel", URL: receiver.URL}))
+	dispatcher, err := NewDispatcher(store, Options{Attempts: 1})
+	require.NoError(t, err)
+
+	// loopback targets aren't dialed unless they are allowed
+	require.NoError(t, dispatcher.ProcessBlock(ctx, "mychannel", &blockhandler.CustomBlock{Number: 1, Txs: []db.Tx{{Txid: "tx1", Blocknum: 1}}}))
+	var letters []db.DeadLetter
+	require.Eventually(t, func() bool {
+		letters, _ = store.ListDeadLetters(ctx, "internal")
+		return len(letters) == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Contains(t, letters[0].Error, "internal address")
+
+	_, err = NewDispatcher(store, Options{AllowedNetworks: []string{"10.0.0.1"}})
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(db.Subscription{Channel: "mychannel", URL: "https://example.com/hook"}))
+	assert.Error(t, Validate(db.Subscription{URL: "https://example.com/hook"}))
+	assert.Error(t, Validate(db.Subscription{Channel: "mychannel", URL: "ftp://example.com"}))
+	assert.Error(t, Validate(db.Subscription{Channel: "mychannel", URL: "example.com"}))
+
+	dispatcher, err := NewDispatcher(dbtest.New(), Options{AllowedNetworks: []string{"10.1.0.0/16"}})
+	require.NoError(t, err)
+	assert.NoError(t, dispatcher.Validate(db.Subscription{Channel: "mychannel", URL: "https://example.com/hook"}))
+	assert.NoError(t, dispatcher.Validate(db.Subscription{Channel: "mychannel", URL: "http://10.1.2.3/hook"}))
+	for _, target := range []string{"http://localhost:8080", "http://127.0.0.1", "http://[::1]/hook", "http://169.254.169.254/latest",
+		"http://10.2.0.1", "http://192.168.1.1", "http://0.0.0.0"} {
+		assert.Error(t, dispatcher.Validate(db.Subscription{Channel: "mychannel", URL: target}), target)
+	}
+}